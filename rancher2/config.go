@@ -2,13 +2,20 @@ package rancher2
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/rancher/norman/clientbase"
 	"github.com/rancher/norman/types"
 	clusterClient "github.com/rancher/rancher/pkg/client/generated/cluster/v3"
@@ -24,6 +31,11 @@ const (
 	rancher2ManagementV2TypePrefix    = "management.cattle.io"
 	rancher2ReadyAnswer               = "pong"
 	rancher2RetriesWait               = 5
+	rancher2RetryBackoffBase          = 1 * time.Second
+	rancher2RetryBackoffMax           = 30 * time.Second
+	rancher2StatePollMinInterval      = 3 * time.Second
+	rancher2StatePollMaxInterval      = 30 * time.Second
+	rancher2StatePollBackoffFactor    = 2
 	rancher2WaitFalseCond             = 120
 	rancher2RKEK8sSystemImageVersion  = "2.3.0"
 	rancher2NodeTemplateChangeVersion = "2.3.3" // Change node template id format
@@ -37,27 +49,281 @@ const (
 
 // Client are the client kind for a Rancher v3 API
 type Client struct {
-	Management *managementClient.Client
-	CatalogV2  map[string]*clientbase.APIBaseClient
-	Cluster    map[string]*clusterClient.Client
-	Project    map[string]*projectClient.Client
+	Management     *managementClient.Client
+	ManagementRead *managementClient.Client
+	CatalogV2      map[string]*clientbase.APIBaseClient
+	Cluster        map[string]*clusterClient.Client
+	Project        map[string]*projectClient.Client
 }
 
 // Config is the configuration parameters for a Rancher v3 API
 type Config struct {
-	TokenKey             string `json:"tokenKey"`
-	URL                  string `json:"url"`
-	CACerts              string `json:"cacert"`
-	Insecure             bool   `json:"insecure"`
-	Bootstrap            bool   `json:"bootstrap"`
-	ClusterID            string `json:"clusterId"`
-	ProjectID            string `json:"projectId"`
-	Timeout              time.Duration
-	RancherVersion       string
-	K8SDefaultVersion    string
-	K8SSupportedVersions []string
-	Sync                 sync.Mutex
-	Client               Client
+	TokenKey                   string `json:"tokenKey"`
+	URL                        string `json:"url"`
+	ReadURL                    string `json:"readUrl"`
+	CACerts                    string `json:"cacert"`
+	Insecure                   bool   `json:"insecure"`
+	Bootstrap                  bool   `json:"bootstrap"`
+	ClusterID                  string `json:"clusterId"`
+	ProjectID                  string `json:"projectId"`
+	Timeout                    time.Duration
+	RancherVersion             string
+	K8SDefaultVersion          string
+	K8SSupportedVersions       []string
+	RetryBudget                time.Duration
+	RetryMaxAttempts           int
+	MultiClusterAppConcurrency int
+	StatePollMinInterval       time.Duration
+	StatePollMaxInterval       time.Duration
+	StatePollBackoffFactor     float64
+	MultiClusterAppWaitBudget  time.Duration
+	StopContext                context.Context
+	DefaultTimeouts            map[string]time.Duration
+	CatalogResolutionOrder     []string
+	AnswersEncrypter           answersEncrypter
+	Tracer                     *tracer
+	Sync                       sync.Mutex
+	Client                     Client
+	retrySpent                 time.Duration
+	retrySync                  sync.Mutex
+	multiClusterAppWaitSpent   time.Duration
+	multiClusterAppWaitSync    sync.Mutex
+	multiClusterAppSlots       chan struct{}
+	multiClusterAppSlotsOnce   sync.Once
+	managementTimeoutClients   map[string]*managementClient.Client
+	serverURLReresolved        bool
+}
+
+// stopContext returns the context cancelled when Terraform stops the provider, or a never cancelled one if not set
+func (c *Config) stopContext() context.Context {
+	if c == nil || c.StopContext == nil {
+		return context.Background()
+	}
+
+	return c.StopContext
+}
+
+// consumeRetryBudget charges wait to the retry budget shared across the apply. Returns error if budget is exhausted
+func (c *Config) consumeRetryBudget(wait time.Duration) error {
+	if c.RetryBudget <= 0 {
+		return nil
+	}
+
+	c.retrySync.Lock()
+	defer c.retrySync.Unlock()
+
+	if c.retrySpent+wait > c.RetryBudget {
+		return fmt.Errorf("[ERROR] Retry budget %s exhausted, %s already spent retrying", c.RetryBudget, c.retrySpent)
+	}
+	c.retrySpent += wait
+
+	return nil
+}
+
+// doWithRetry calls fn until it succeeds, fails with an error other than throttling or a server error, or RetryMaxAttempts
+// are made. Waits between attempts grow exponentially with jitter and are charged to the retry budget. Returns the
+// last fn error if ctx is done while waiting. Just one attempt is made if RetryMaxAttempts is 0
+func (c *Config) doWithRetry(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !isRetryableAPIError(err) || attempt >= c.RetryMaxAttempts {
+			return err
+		}
+
+		wait := retryBackoff(attempt)
+		if budgetErr := c.consumeRetryBudget(wait); budgetErr != nil {
+			return budgetErr
+		}
+		log.Printf("[DEBUG] Rancher API call failed, retrying in %s (%d/%d): %v", wait, attempt, c.RetryMaxAttempts, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("[ERROR] Retrying Rancher API call: %v: %v", ctx.Err(), err)
+		}
+	}
+}
+
+// retryBackoff returns the wait before the next attempt, doubling rancher2RetryBackoffBase on every attempt up to
+// rancher2RetryBackoffMax, with a random jitter of up to half the wait
+func retryBackoff(attempt int) time.Duration {
+	wait := rancher2RetryBackoffMax
+	if attempt < 16 {
+		if backoff := rancher2RetryBackoffBase << uint(attempt-1); backoff < wait {
+			wait = backoff
+		}
+	}
+
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// statePollBackoff returns the state poll min interval, max interval and backoff factor set on meta, using the
+// provider defaults for the unset ones
+func statePollBackoff(meta interface{}) (time.Duration, time.Duration, float64) {
+	minInterval, maxInterval, factor := rancher2StatePollMinInterval, rancher2StatePollMaxInterval, float64(rancher2StatePollBackoffFactor)
+	if c, ok := meta.(*Config); ok && c != nil {
+		if c.StatePollMinInterval > 0 {
+			minInterval = c.StatePollMinInterval
+		}
+		if c.StatePollMaxInterval > 0 {
+			maxInterval = c.StatePollMaxInterval
+		}
+		if c.StatePollBackoffFactor >= 1 {
+			factor = c.StatePollBackoffFactor
+		}
+	}
+	if maxInterval < minInterval {
+		maxInterval = minInterval
+	}
+
+	return minInterval, maxInterval, factor
+}
+
+// newStateChangeConf returns a resource.StateChangeConf waiting up to timeout for refresh to reach target. Refresh is
+// polled every state poll min interval, growing by the backoff factor after every poll up to the max interval.
+// Waiting is aborted once ctx is done
+func newStateChangeConf(ctx context.Context, meta interface{}, pending, target []string, refresh resource.StateRefreshFunc, timeout time.Duration) *resource.StateChangeConf {
+	minInterval, maxInterval, factor := statePollBackoff(meta)
+
+	return &resource.StateChangeConf{
+		Pending:      pending,
+		Target:       target,
+		Refresh:      stateBackoffRefreshFunc(ctx, refresh, minInterval, maxInterval, factor),
+		Timeout:      timeout,
+		Delay:        1 * time.Second,
+		PollInterval: minInterval,
+	}
+}
+
+// waitForStateWithBudget waits for conf, bounding its timeout to the multi cluster app wait budget left. Time spent
+// waiting is charged to the budget shared across the apply. Returns error without waiting if the budget is exhausted
+func waitForStateWithBudget(meta interface{}, conf *resource.StateChangeConf) (interface{}, error) {
+	c, ok := meta.(*Config)
+	if !ok || c == nil || c.MultiClusterAppWaitBudget <= 0 {
+		return conf.WaitForState()
+	}
+
+	c.multiClusterAppWaitSync.Lock()
+	left := c.MultiClusterAppWaitBudget - c.multiClusterAppWaitSpent
+	c.multiClusterAppWaitSync.Unlock()
+	if left <= 0 {
+		return nil, fmt.Errorf("[ERROR] Global wait budget exhausted, %s multi_cluster_app_wait_budget already spent waiting", c.MultiClusterAppWaitBudget)
+	}
+	bounded := conf.Timeout > left
+	if bounded {
+		conf.Timeout = left
+	}
+
+	start := time.Now()
+	out, err := conf.WaitForState()
+	c.multiClusterAppWaitSync.Lock()
+	c.multiClusterAppWaitSpent += time.Since(start)
+	c.multiClusterAppWaitSync.Unlock()
+	if err != nil && bounded {
+		return out, fmt.Errorf("[ERROR] Global wait budget exhausted, %s multi_cluster_app_wait_budget spent waiting: %v", c.MultiClusterAppWaitBudget, err)
+	}
+
+	return out, err
+}
+
+// stateBackoffRefreshFunc returns a resource.StateRefreshFunc calling refresh, sleeping before every poll but the first
+// the time needed to grow the minInterval poll interval by factor on every poll up to maxInterval. Returns error if ctx
+// is done
+func stateBackoffRefreshFunc(ctx context.Context, refresh resource.StateRefreshFunc, minInterval, maxInterval time.Duration, factor float64) resource.StateRefreshFunc {
+	interval := time.Duration(0)
+	return func() (interface{}, string, error) {
+		if interval > minInterval {
+			select {
+			case <-time.After(interval - minInterval):
+			case <-ctx.Done():
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, "", fmt.Errorf("[ERROR] Aborting state wait: %v", err)
+		}
+
+		switch {
+		case interval == 0:
+			interval = minInterval
+		case interval < maxInterval:
+			interval = time.Duration(float64(interval) * factor)
+			if interval > maxInterval {
+				interval = maxInterval
+			}
+		}
+
+		return refresh()
+	}
+}
+
+// withSpan calls fn on a tracing span named name, child of the ctx span, setting the fn error as the span status.
+// fn is just called if Tracer isn't set
+func (c *Config) withSpan(ctx context.Context, name string, kind int, fn func(context.Context) error) error {
+	if c.Tracer == nil {
+		return fn(ctx)
+	}
+
+	ctx, end := c.Tracer.start(ctx, name, kind)
+	err := fn(ctx)
+	end(err)
+
+	return err
+}
+
+// acquireMultiClusterAppSlot blocks until less than MultiClusterAppConcurrency multi cluster app operations are
+// running. Returns the function releasing the slot. Unlimited if MultiClusterAppConcurrency is 0
+func (c *Config) acquireMultiClusterAppSlot() func() {
+	if c.MultiClusterAppConcurrency <= 0 {
+		return func() {}
+	}
+
+	c.multiClusterAppSlotsOnce.Do(func() {
+		c.multiClusterAppSlots = make(chan struct{}, c.MultiClusterAppConcurrency)
+	})
+	c.multiClusterAppSlots <- struct{}{}
+
+	return func() {
+		<-c.multiClusterAppSlots
+	}
+}
+
+// isServerURLError checks if err is a failure reaching a host other than the api_url one, like the clients
+// following links of a previous Rancher server-url
+func (c *Config) isServerURLError(err error) bool {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+	failed, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		return false
+	}
+	api, parseErr := url.Parse(c.URL)
+	if parseErr != nil {
+		return false
+	}
+
+	return failed.Host != api.Host
+}
+
+// retryOnServerURLChange calls f, calling it once more if it fails reaching a stale Rancher server-url, e.g. on
+// Rancher HA failovers. Clients are rebuilt from api_url before retrying, just once per provider
+func (c *Config) retryOnServerURLChange(f func() error) error {
+	err := f()
+	if err == nil || !c.isServerURLError(err) {
+		return err
+	}
+
+	c.Sync.Lock()
+	if c.serverURLReresolved {
+		c.Sync.Unlock()
+		return err
+	}
+	c.serverURLReresolved = true
+	log.Printf("[WARN] Rancher server url seems changed, re-resolving endpoint from %s: %v", c.URL, err)
+	c.resetClients()
+	c.Sync.Unlock()
+
+	return f()
 }
 
 // GetRancherVersion get Rancher server version
@@ -93,6 +359,9 @@ func (c *Config) isRancherReady() error {
 		if err == nil && rancher2ReadyAnswer == string(resp) {
 			return nil
 		}
+		if budgetErr := c.consumeRetryBudget(rancher2RetriesWait * time.Second); budgetErr != nil {
+			return budgetErr
+		}
 		select {
 		case <-time.After(rancher2RetriesWait * time.Second):
 		case <-ctx.Done():
@@ -140,6 +409,9 @@ func (c *Config) getK8SDefaultVersion() (string, error) {
 		if !IsServerError(err) && !IsForbidden(err) {
 			return "", err
 		}
+		if budgetErr := c.consumeRetryBudget(rancher2RetriesWait * time.Second); budgetErr != nil {
+			return "", budgetErr
+		}
 		select {
 		case <-time.After(rancher2RetriesWait * time.Second):
 		case <-ctx.Done():
@@ -235,7 +507,9 @@ func (c *Config) UpdateToken(token string) error {
 	if len(token) == 0 {
 		return fmt.Errorf("token is nil")
 	}
+	c.Sync.Lock()
 	c.TokenKey = token
+	c.Sync.Unlock()
 
 	return c.RestartClients()
 }
@@ -243,23 +517,26 @@ func (c *Config) UpdateToken(token string) error {
 // RestartClients connections
 func (c *Config) RestartClients() error {
 	c.Sync.Lock()
-	if c.Client.Management != nil {
-		c.Client.Management = nil
-	}
+	c.resetClients()
 	c.Sync.Unlock()
 
 	_, err := c.ManagementClient()
-	if err != nil {
-		return err
-	}
+
+	return err
+}
+
+// resetClients drops the cached clients, created again on next use. c.Sync must be held
+func (c *Config) resetClients() {
+	c.Client.Management = nil
+	c.Client.ManagementRead = nil
+	c.managementTimeoutClients = nil
 	c.Client.Cluster = map[string]*clusterClient.Client{}
 	c.Client.Project = map[string]*projectClient.Client{}
 	c.Client.CatalogV2 = map[string]*clientbase.APIBaseClient{}
-
-	return nil
 }
 
-// ManagementClient creates a Rancher client scoped to the management API
+// ManagementClient creates a Rancher client scoped to the management API. The client is created once and cached, safe
+// for concurrent use, until the token or the server url changes
 func (c *Config) ManagementClient() (*managementClient.Client, error) {
 	c.Sync.Lock()
 	defer c.Sync.Unlock()
@@ -294,6 +571,152 @@ func (c *Config) ManagementClient() (*managementClient.Client, error) {
 	return c.Client.Management, nil
 }
 
+// ManagementClientWithTimeouts creates a Rancher client scoped to the management API, whose calls abort after connect
+// timeout establishing the connection or read timeout overall. It is the ManagementClient if both are 0. Clients are
+// cached by timeouts
+func (c *Config) ManagementClientWithTimeouts(connect, read time.Duration) (*managementClient.Client, error) {
+	client, err := c.ManagementClient()
+	if err != nil || (connect <= 0 && read <= 0) {
+		return client, err
+	}
+
+	return c.managementClientWithTimeouts(c.URL, connect, read)
+}
+
+// ManagementReadClientWithTimeouts creates a Rancher client scoped to the management API on the read endpoint, whose
+// calls are bounded like ManagementClientWithTimeouts. It is the ManagementClientWithTimeouts if the read endpoint
+// isn't set, and the ManagementReadClient if both timeouts are 0
+func (c *Config) ManagementReadClientWithTimeouts(connect, read time.Duration) (*managementClient.Client, error) {
+	if len(c.ReadURL) == 0 {
+		return c.ManagementClientWithTimeouts(connect, read)
+	}
+	client, err := c.ManagementReadClient()
+	if err != nil || (connect <= 0 && read <= 0) {
+		return client, err
+	}
+	readURL, err := NormalizeURL(c.ReadURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.managementClientWithTimeouts(readURL, connect, read)
+}
+
+// managementClientWithTimeouts returns the cached management client on url bounded by connect and read timeouts,
+// creating it if needed
+func (c *Config) managementClientWithTimeouts(url string, connect, read time.Duration) (*managementClient.Client, error) {
+	c.Sync.Lock()
+	defer c.Sync.Unlock()
+
+	key := url + "/" + connect.String() + "/" + read.String()
+	if cached, ok := c.managementTimeoutClients[key]; ok {
+		return cached, nil
+	}
+
+	options := c.CreateClientOpts()
+	options.URL = url + rancher2ClientAPIVersion
+	options.Timeout = read
+	mClient, err := managementClient.NewClient(options)
+	if err != nil {
+		return nil, err
+	}
+	setHTTPClientTimeouts(mClient.Ops.Client, connect, read)
+	if c.managementTimeoutClients == nil {
+		c.managementTimeoutClients = map[string]*managementClient.Client{}
+	}
+	c.managementTimeoutClients[key] = mClient
+
+	return mClient, nil
+}
+
+// setHTTPClientTimeouts bounds client requests to read, and dialing and TLS handshakes to connect. 0 keeps the client
+// default. Connect isn't set if the client transport isn't a http.Transport
+func setHTTPClientTimeouts(client *http.Client, connect, read time.Duration) {
+	if read > 0 {
+		client.Timeout = read
+	}
+	if connect <= 0 {
+		return
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if client.Transport != nil {
+		transport, ok = client.Transport.(*http.Transport)
+	}
+	if !ok {
+		log.Printf("[WARN] Skipping client connect timeout, unknown transport %T", client.Transport)
+		return
+	}
+	transport = transport.Clone()
+	dialer := &net.Dialer{
+		Timeout:   connect,
+		KeepAlive: 30 * time.Second,
+	}
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = connect
+	client.Transport = transport
+}
+
+// ManagementReadClient creates a Rancher client scoped to the management API on the read endpoint. It is the
+// ManagementClient if the read endpoint isn't set
+func (c *Config) ManagementReadClient() (*managementClient.Client, error) {
+	if len(c.ReadURL) == 0 {
+		return c.ManagementClient()
+	}
+
+	c.Sync.Lock()
+	defer c.Sync.Unlock()
+
+	if c.Client.ManagementRead != nil {
+		return c.Client.ManagementRead, nil
+	}
+
+	readURL, err := NormalizeURL(c.ReadURL)
+	if err != nil {
+		return nil, err
+	}
+	options := c.CreateClientOpts()
+	options.URL = readURL + rancher2ClientAPIVersion
+	mClient, err := managementClient.NewClient(options)
+	if err != nil {
+		return nil, err
+	}
+	c.Client.ManagementRead = mClient
+
+	return c.Client.ManagementRead, nil
+}
+
+// withManagementReadClient calls fn with the read endpoint management client. fn is called again with the
+// ManagementClient if the read endpoint client fails, e.g. lagging behind the primary endpoint
+func (c *Config) withManagementReadClient(fn func(*managementClient.Client) error) error {
+	return c.withManagementReadClientTimeouts(0, 0, fn)
+}
+
+// withManagementReadClientTimeouts is withManagementReadClient with both clients bounded by connect and read
+// timeouts, like ManagementClientWithTimeouts
+func (c *Config) withManagementReadClientTimeouts(connect, read time.Duration, fn func(*managementClient.Client) error) error {
+	client, err := c.ManagementClientWithTimeouts(connect, read)
+	if err != nil {
+		return err
+	}
+	readClient, err := c.ManagementReadClientWithTimeouts(connect, read)
+	if err != nil {
+		log.Printf("[WARN] Using primary endpoint, getting read endpoint client: %v", err)
+		return fn(client)
+	}
+	if readClient == client {
+		return fn(client)
+	}
+
+	err = fn(readClient)
+	if err == nil {
+		return nil
+	}
+	log.Printf("[WARN] Read endpoint call failed, retrying on primary endpoint: %v", err)
+
+	return fn(client)
+}
+
 // CatalogV2Client creates a Rancher client scoped to a Cluster API
 func (c *Config) CatalogV2Client(id string) (*clientbase.APIBaseClient, error) {
 	if id == "" {
@@ -330,6 +753,9 @@ func (c *Config) CatalogV2Client(id string) (*clientbase.APIBaseClient, error) {
 		if !IsServerError(err) && !IsUnknownSchemaType(err) && !IsNotFound(err) && !IsForbidden(err) {
 			return nil, err
 		}
+		if budgetErr := c.consumeRetryBudget(rancher2RetriesWait * time.Second); budgetErr != nil {
+			return nil, budgetErr
+		}
 		select {
 		case <-time.After(rancher2RetriesWait * time.Second):
 		case <-ctx.Done():
@@ -438,6 +864,11 @@ func (c *Config) GetProjectRoleTemplateBindingsByProjectID(projectID string) ([]
 		return nil, err
 	}
 
+	return listProjectRoleTemplateBindingsByProjectID(client, projectID)
+}
+
+// listProjectRoleTemplateBindingsByProjectID lists the project role template bindings of projectID using client
+func listProjectRoleTemplateBindingsByProjectID(client *managementClient.Client, projectID string) ([]managementClient.ProjectRoleTemplateBinding, error) {
 	filters := map[string]interface{}{"ProjectID": projectID}
 	listOpts := NewListOpts(filters)
 
@@ -758,6 +1189,9 @@ func (c *Config) WaitForClusterState(clusterID, state string, interval time.Dura
 				}
 			}
 		}
+		if budgetErr := c.consumeRetryBudget(rancher2RetriesWait * time.Second); budgetErr != nil {
+			return nil, budgetErr
+		}
 		select {
 		case <-time.After(rancher2RetriesWait * time.Second):
 		case <-ctx.Done():
@@ -792,6 +1226,9 @@ func (c *Config) getObjectV2ByID(clusterID, id, APIType string, resp interface{}
 		if !IsServerError(err) && !IsUnknownSchemaType(err) {
 			return err
 		}
+		if budgetErr := c.consumeRetryBudget(rancher2RetriesWait * time.Second); budgetErr != nil {
+			return budgetErr
+		}
 		select {
 		case <-time.After(rancher2RetriesWait * time.Second):
 		case <-ctx.Done():
@@ -1076,6 +1513,9 @@ func (c *Config) activateKontainerDriver(id string, interval time.Duration) erro
 				}
 			}
 		}
+		if budgetErr := c.consumeRetryBudget(rancher2RetriesWait * time.Second); budgetErr != nil {
+			return budgetErr
+		}
 		select {
 		case <-time.After(rancher2RetriesWait * time.Second):
 		case <-ctx.Done():
@@ -1117,6 +1557,9 @@ func (c *Config) activateNodeDriver(id string, interval time.Duration) error {
 				}
 			}
 		}
+		if budgetErr := c.consumeRetryBudget(rancher2RetriesWait * time.Second); budgetErr != nil {
+			return budgetErr
+		}
 		select {
 		case <-time.After(rancher2RetriesWait * time.Second):
 		case <-ctx.Done():
@@ -1614,6 +2057,30 @@ func (c *Config) GetSecret(id, projectID, namespaceID string) (interface{}, erro
 	return client.Secret.ByID(id)
 }
 
+// GetConfigMapKey returns the value of key at config map ID (<namespace>:<name>) on project ID
+func (c *Config) GetConfigMapKey(projectID, id, key string) (string, error) {
+	if len(id) == 0 || len(projectID) == 0 {
+		return "", fmt.Errorf("[ERROR] Config map id nor project id can't be nil")
+	}
+
+	client, err := c.ProjectClient(projectID)
+	if err != nil {
+		return "", err
+	}
+
+	configMap, err := client.ConfigMap.ByID(id)
+	if err != nil {
+		return "", fmt.Errorf("[ERROR] Getting config map %s at project %s: %v", id, projectID, err)
+	}
+
+	value, ok := configMap.Data[key]
+	if !ok {
+		return "", fmt.Errorf("[ERROR] Key %s not found at config map %s on project %s", key, id, projectID)
+	}
+
+	return value, nil
+}
+
 func (c *Config) createSecret(secret *projectClient.Secret) (*projectClient.Secret, error) {
 	client, err := c.ProjectClient(secret.ProjectID)
 	if err != nil {
@@ -0,0 +1,322 @@
+package rancher2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/rancher/norman/clientbase"
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigConsumeRetryBudget(t *testing.T) {
+	wait := rancher2RetriesWait * time.Second
+
+	config := &Config{RetryBudget: 2 * wait}
+	assert.NoError(t, config.consumeRetryBudget(wait))
+	assert.NoError(t, config.consumeRetryBudget(wait))
+	assert.Error(t, config.consumeRetryBudget(wait), "retries should stop once the budget is spent")
+	assert.Equal(t, 2*wait, config.retrySpent)
+
+	unlimited := &Config{}
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, unlimited.consumeRetryBudget(wait))
+	}
+}
+
+func TestConfigDoWithRetry(t *testing.T) {
+	calls := 0
+	throttled := func() error {
+		calls++
+		if calls < 2 {
+			return &clientbase.APIError{StatusCode: http.StatusTooManyRequests}
+		}
+		return nil
+	}
+	config := &Config{RetryMaxAttempts: 3}
+	assert.NoError(t, config.doWithRetry(context.Background(), throttled))
+	assert.Equal(t, 2, calls)
+
+	// Client errors aren't retried
+	calls = 0
+	notFound := func() error {
+		calls++
+		return &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+	assert.True(t, IsNotFound(config.doWithRetry(context.Background(), notFound)))
+	assert.Equal(t, 1, calls)
+
+	// Retries are aborted once the context is done
+	calls = 0
+	badGateway := func() error {
+		calls++
+		return &clientbase.APIError{StatusCode: http.StatusBadGateway}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, config.doWithRetry(ctx, badGateway))
+	assert.Equal(t, 1, calls)
+
+	// Just one attempt if retries aren't configured
+	calls = 0
+	assert.True(t, IsBadGatewayError((&Config{}).doWithRetry(context.Background(), badGateway)))
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 100; attempt++ {
+		wait := retryBackoff(attempt)
+		assert.True(t, wait >= rancher2RetryBackoffBase/2)
+		assert.True(t, wait <= rancher2RetryBackoffMax)
+	}
+	assert.True(t, retryBackoff(1) <= rancher2RetryBackoffBase)
+}
+
+func TestWaitForStateWithBudget(t *testing.T) {
+	polled := false
+	newConf := func(refresh resource.StateRefreshFunc) *resource.StateChangeConf {
+		return &resource.StateChangeConf{
+			Pending:      []string{"pending"},
+			Target:       []string{"done"},
+			Refresh:      refresh,
+			Timeout:      time.Minute,
+			PollInterval: 10 * time.Millisecond,
+		}
+	}
+	pending := func() (interface{}, string, error) {
+		return "", "pending", nil
+	}
+	config := &Config{MultiClusterAppWaitBudget: 50 * time.Millisecond}
+
+	start := time.Now()
+	_, err := waitForStateWithBudget(config, newConf(pending))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Global wait budget exhausted")
+	}
+	assert.Less(t, time.Since(start), 10*time.Second, "wait should be bounded by the budget")
+
+	_, err = waitForStateWithBudget(config, newConf(func() (interface{}, string, error) {
+		polled = true
+		return "", "done", nil
+	}))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Global wait budget exhausted")
+	}
+	assert.False(t, polled, "wait should short-circuit once the budget is spent")
+}
+
+func TestConfigStopContextAbortsStateWait(t *testing.T) {
+	assert.NoError(t, (&Config{}).stopContext().Err(), "Unset stop context should never be cancelled")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	config := &Config{StopContext: ctx, StatePollMinInterval: 10 * time.Millisecond}
+	assert.Equal(t, ctx, config.stopContext())
+
+	cancel()
+	pending := func() (interface{}, string, error) {
+		return "", "pending", nil
+	}
+	start := time.Now()
+	_, err := newStateChangeConf(config.stopContext(), config, []string{"pending"}, []string{"done"}, pending, time.Minute).WaitForState()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Aborting state wait")
+	}
+	assert.Less(t, time.Since(start), 30*time.Second, "wait should abort once the provider is stopped")
+}
+
+func TestStatePollBackoff(t *testing.T) {
+	minInterval, maxInterval, factor := statePollBackoff(nil)
+	assert.Equal(t, rancher2StatePollMinInterval, minInterval)
+	assert.Equal(t, rancher2StatePollMaxInterval, maxInterval)
+	assert.Equal(t, float64(rancher2StatePollBackoffFactor), factor)
+
+	minInterval, maxInterval, factor = statePollBackoff(&Config{
+		StatePollMinInterval:   time.Minute,
+		StatePollMaxInterval:   time.Second,
+		StatePollBackoffFactor: 1.5,
+	})
+	assert.Equal(t, time.Minute, minInterval)
+	assert.Equal(t, time.Minute, maxInterval, "max interval should not be lower than min interval")
+	assert.Equal(t, 1.5, factor)
+}
+
+func TestStateBackoffRefreshFunc(t *testing.T) {
+	calls := []time.Time{}
+	refresh := func() (interface{}, string, error) {
+		calls = append(calls, time.Now())
+		return "app", "pending", nil
+	}
+
+	wrapped := stateBackoffRefreshFunc(context.Background(), refresh, 10*time.Millisecond, 40*time.Millisecond, 2)
+	for i := 0; i < 5; i++ {
+		_, state, err := wrapped()
+		assert.NoError(t, err)
+		assert.Equal(t, "pending", state)
+	}
+	// Extra sleeps on top of the min interval poll: 0, 0, 10ms, 30ms, 30ms
+	assert.True(t, calls[1].Sub(calls[0]) < 10*time.Millisecond)
+	assert.True(t, calls[3].Sub(calls[2]) >= 30*time.Millisecond)
+	assert.True(t, calls[4].Sub(calls[3]) >= 30*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wrapped = stateBackoffRefreshFunc(ctx, refresh, 10*time.Millisecond, time.Hour, 100)
+	_, _, err := wrapped()
+	assert.NoError(t, err)
+	_, _, err = wrapped()
+	assert.NoError(t, err)
+	cancel()
+	_, _, err = wrapped()
+	assert.Error(t, err, "waiting should be aborted once context is done")
+	assert.Len(t, calls, 7)
+}
+
+func TestSetHTTPClientTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &http.Client{}
+	setHTTPClientTimeouts(client, time.Second, 100*time.Millisecond)
+	transport, ok := client.Transport.(*http.Transport)
+	if assert.True(t, ok) {
+		assert.Equal(t, time.Second, transport.TLSHandshakeTimeout)
+		assert.NotNil(t, transport.DialContext)
+	}
+	assert.NotSame(t, http.DefaultTransport, client.Transport, "default transport shouldn't be modified")
+
+	resp, err := client.Get(server.URL + "/fast")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+
+	start := time.Now()
+	_, err = client.Get(server.URL + "/slow")
+	assert.Error(t, err, "slow call should abort at the read timeout")
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	unbounded := &http.Client{}
+	setHTTPClientTimeouts(unbounded, 0, 0)
+	assert.Nil(t, unbounded.Transport)
+	assert.Equal(t, time.Duration(0), unbounded.Timeout)
+}
+
+func TestConfigAcquireMultiClusterAppSlot(t *testing.T) {
+	config := &Config{MultiClusterAppConcurrency: 2}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := config.acquireMultiClusterAppSlot()
+			defer release()
+
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 2, maxRunning, "multi cluster app operations should be limited by concurrency")
+
+	unlimited := &Config{}
+	for i := 0; i < 100; i++ {
+		unlimited.acquireMultiClusterAppSlot()
+	}
+}
+
+func TestConfigRetryOnServerURLChange(t *testing.T) {
+	config := &Config{
+		URL: "https://rancher.example.com",
+		Client: Client{
+			Management: &managementClient.Client{},
+		},
+	}
+	staleErr := &url.Error{Op: "Get", URL: "https://rancher-old.example.com/v3/multiclusterapps/foo", Err: errors.New("connection refused")}
+
+	calls := 0
+	err := config.retryOnServerURLChange(func() error {
+		calls++
+		if calls == 1 {
+			return staleErr
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls, "Call should be retried once the endpoint is re-resolved")
+	assert.Nil(t, config.Client.Management, "Clients should be rebuilt from api_url")
+
+	calls = 0
+	err = config.retryOnServerURLChange(func() error {
+		calls++
+		return staleErr
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls, "Endpoint should be re-resolved just once")
+
+	config = &Config{URL: "https://rancher.example.com"}
+	calls = 0
+	err = config.retryOnServerURLChange(func() error {
+		calls++
+		return &url.Error{Op: "Get", URL: "https://rancher.example.com/v3/multiclusterapps/foo", Err: errors.New("connection refused")}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls, "Failures reaching api_url should not be retried")
+}
+
+func TestConfigManagementClientCached(t *testing.T) {
+	cached := &managementClient.Client{}
+	config := &Config{
+		URL: "https://rancher.example.com",
+		Client: Client{
+			Management:     cached,
+			ManagementRead: &managementClient.Client{},
+		},
+	}
+
+	var wg sync.WaitGroup
+	clients := make([]*managementClient.Client, 20)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = config.ManagementClient()
+		}(i)
+	}
+	wg.Wait()
+	for _, client := range clients {
+		assert.True(t, client == cached, "Management client should be cached")
+	}
+
+	config.Sync.Lock()
+	config.resetClients()
+	config.Sync.Unlock()
+	assert.Nil(t, config.Client.Management)
+	assert.Nil(t, config.Client.ManagementRead, "Read client should be rebuilt with the new token")
+	assert.Empty(t, config.Client.Project)
+}
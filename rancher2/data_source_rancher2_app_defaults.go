@@ -0,0 +1,105 @@
+package rancher2
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+)
+
+func dataSourceRancher2AppDefaults() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRancher2AppDefaultsRead,
+
+		Schema: map[string]*schema.Schema{
+			"catalog_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Global catalog name",
+			},
+			"template_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Template name",
+			},
+			"template_version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "Template version. Latest version if not set",
+			},
+			"template_version_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Template version ID",
+			},
+			"answers": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Default answers of the template version questions",
+			},
+		},
+	}
+}
+
+func dataSourceRancher2AppDefaultsRead(d *schema.ResourceData, meta interface{}) error {
+	catalogName := d.Get("catalog_name").(string)
+	templateName := d.Get("template_name").(string)
+	templateVersion := d.Get("template_version").(string)
+
+	client, err := meta.(*Config).ManagementClient()
+	if err != nil {
+		return err
+	}
+
+	templateID := MultiClusterAppTemplatePrefix + catalogName + "-" + templateName
+	if len(templateVersion) == 0 {
+		template, err := client.Template.ByID(templateID)
+		if err != nil {
+			if IsNotFound(err) {
+				return fmt.Errorf("[ERROR] template %s not found on catalog %s", templateName, catalogName)
+			}
+			return err
+		}
+		templateVersion, err = getLatestVersion(template.VersionLinks)
+		if err != nil {
+			return err
+		}
+	}
+
+	templateVersionID := templateID + "-" + templateVersion
+	version, err := client.TemplateVersion.ByID(templateVersionID)
+	if err != nil {
+		if IsNotFound(err) {
+			return fmt.Errorf("[ERROR] template version %s not found on catalog %s template %s", templateVersion, catalogName, templateName)
+		}
+		return err
+	}
+
+	d.SetId(templateVersionID)
+	d.Set("template_version", templateVersion)
+	d.Set("template_version_id", templateVersionID)
+
+	return d.Set("answers", flattenAppDefaultAnswers(version.Questions))
+}
+
+// flattenAppDefaultAnswers returns the questions default answers. Subquestion defaults are just included if their
+// question default shows them
+func flattenAppDefaultAnswers(questions []managementClient.Question) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, q := range questions {
+		if len(q.Default) > 0 {
+			out[q.Variable] = q.Default
+		}
+		if len(q.ShowSubquestionIf) > 0 && q.ShowSubquestionIf != q.Default {
+			continue
+		}
+		for _, sq := range q.Subquestions {
+			if len(sq.Default) > 0 {
+				out[sq.Variable] = sq.Default
+			}
+		}
+	}
+
+	return out
+}
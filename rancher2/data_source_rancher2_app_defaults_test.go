@@ -0,0 +1,96 @@
+package rancher2
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/rancher/norman/clientbase"
+	"github.com/rancher/norman/types"
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAppDefaultsTemplateOperations struct {
+	managementClient.TemplateOperations
+	templates map[string]*managementClient.Template
+}
+
+func (o *testAppDefaultsTemplateOperations) ByID(id string) (*managementClient.Template, error) {
+	template, ok := o.templates[id]
+	if !ok {
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+	return template, nil
+}
+
+func TestDataSourceRancher2AppDefaultsRead(t *testing.T) {
+	config := &Config{
+		Client: Client{
+			Management: &managementClient.Client{
+				Template: &testAppDefaultsTemplateOperations{
+					templates: map[string]*managementClient.Template{
+						"cattle-global-data:test-demo": {
+							Resource: types.Resource{ID: "cattle-global-data:test-demo"},
+							VersionLinks: map[string]string{
+								"1.9.0":  "link",
+								"1.10.0": "link",
+							},
+						},
+					},
+				},
+				TemplateVersion: &testTemplateVersionOperations{
+					templateVersions: map[string]*managementClient.TemplateVersion{
+						"cattle-global-data:test-demo-1.10.0": {
+							Questions: []managementClient.Question{
+								{Variable: "replicaCount", Default: "1"},
+								{Variable: "image.tag"},
+								{
+									Variable:          "ingress.enabled",
+									Default:           "true",
+									ShowSubquestionIf: "true",
+									Subquestions: []managementClient.SubQuestion{
+										{Variable: "ingress.host", Default: "example.com"},
+									},
+								},
+								{
+									Variable:          "persistence.enabled",
+									Default:           "false",
+									ShowSubquestionIf: "true",
+									Subquestions: []managementClient.SubQuestion{
+										{Variable: "persistence.size", Default: "10Gi"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceRancher2AppDefaults().Schema, map[string]interface{}{
+		"catalog_name":  "test",
+		"template_name": "demo",
+	})
+	err := dataSourceRancher2AppDefaultsRead(d, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "cattle-global-data:test-demo-1.10.0", d.Id())
+	assert.Equal(t, "1.10.0", d.Get("template_version"))
+	assert.Equal(t, map[string]interface{}{
+		"replicaCount":        "1",
+		"ingress.enabled":     "true",
+		"ingress.host":        "example.com",
+		"persistence.enabled": "false",
+	}, d.Get("answers"))
+
+	d = schema.TestResourceDataRaw(t, dataSourceRancher2AppDefaults().Schema, map[string]interface{}{
+		"catalog_name":     "test",
+		"template_name":    "demo",
+		"template_version": "1.9.0",
+	})
+	err = dataSourceRancher2AppDefaultsRead(d, config)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "template version 1.9.0 not found on catalog test template demo")
+	}
+}
@@ -0,0 +1,85 @@
+package rancher2
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceRancher2ClusterV2Kubeconfig() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRancher2ClusterV2KubeconfigRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Cluster V2 name",
+			},
+			"fleet_namespace": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "fleet-default",
+			},
+			"cluster_v1_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Cluster V2 management cluster ID",
+			},
+			"kube_config": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Cluster V2 generated kubeconfig",
+			},
+			"server": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Kubernetes API server URL of the kubeconfig current context",
+			},
+			"ca_cert": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Kubernetes API server CA certificate of the kubeconfig current context, PEM encoded",
+			},
+		},
+	}
+}
+
+func dataSourceRancher2ClusterV2KubeconfigRead(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+	namespace := d.Get("fleet_namespace").(string)
+	id := namespace + clusterV2ClusterIDsep + name
+
+	cluster, err := getClusterV2ByID(meta.(*Config), id)
+	if err != nil {
+		if IsNotFound(err) || IsForbidden(err) {
+			return fmt.Errorf("[ERROR] Cluster V2 %s not found", id)
+		}
+		return err
+	}
+	if len(cluster.Status.ClusterName) == 0 {
+		return fmt.Errorf("[ERROR] Cluster V2 %s has no management cluster yet", id)
+	}
+
+	// A new kubeconfig is generated on every read, as its token may be rotated or expired
+	kubeConfig, err := getClusterKubeconfig(meta.(*Config), cluster.Status.ClusterName, "")
+	if err != nil {
+		return err
+	}
+	if kubeConfig == nil || len(kubeConfig.Config) == 0 {
+		return fmt.Errorf("[ERROR] Cluster V2 %s kubeconfig is not available yet", id)
+	}
+
+	server, caCert, err := getServerFromKubeConfig(kubeConfig.Config)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(id)
+	d.Set("cluster_v1_id", cluster.Status.ClusterName)
+	d.Set("kube_config", kubeConfig.Config)
+	d.Set("server", server)
+
+	return d.Set("ca_cert", caCert)
+}
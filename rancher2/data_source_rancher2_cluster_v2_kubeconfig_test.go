@@ -0,0 +1,49 @@
+package rancher2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testClusterV2Kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: foo
+  cluster:
+    server: https://rancher.example.com/k8s/clusters/c-m-abcde
+    certificate-authority-data: "LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0tCk1JSUJmYWtlCi0tLS0tRU5EIENFUlRJRklDQVRFLS0tLS0K"
+- name: foo-ace
+  cluster:
+    server: https://10.0.0.10:6443
+contexts:
+- name: foo
+  context:
+    cluster: foo
+    user: foo
+- name: foo-ace
+  context:
+    cluster: foo-ace
+    user: foo
+current-context: foo
+users:
+- name: foo
+  user:
+    token: kubeconfig-user-abcde:secret
+`
+
+func TestGetServerFromKubeConfig(t *testing.T) {
+	server, caCert, err := getServerFromKubeConfig(testClusterV2Kubeconfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://rancher.example.com/k8s/clusters/c-m-abcde", server)
+	assert.Equal(t, "-----BEGIN CERTIFICATE-----\nMIIBfake\n-----END CERTIFICATE-----\n", caCert)
+
+	server, caCert, err = getServerFromKubeConfig(strings.Replace(testClusterV2Kubeconfig, "current-context: foo", "current-context: foo-ace", 1))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.10:6443", server)
+	assert.Equal(t, "", caCert)
+
+	_, _, err = getServerFromKubeConfig("apiVersion: v1\nkind: Config\n")
+	assert.Error(t, err)
+}
@@ -24,6 +24,29 @@ func dataSourceRancher2MultiClusterApp() *schema.Resource {
 					Schema: targetFields(),
 				},
 			},
+			"targets_in_sync": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether every multi cluster app target state equals the multi cluster app state",
+			},
+			"target_app_names": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Multi cluster app target app names by project ID",
+			},
+			"target_apps": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Multi cluster app target apps, as project ID, app ID and state of every target",
+				Elem: &schema.Resource{
+					Schema: multiClusterAppTargetAppFields(),
+				},
+			},
+			"target_health_states": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Multi cluster app target health states by project ID",
+			},
 			"catalog_name": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -37,6 +60,14 @@ func dataSourceRancher2MultiClusterApp() *schema.Resource {
 					Schema: answerFields(),
 				},
 			},
+			"effective_answers": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Multi cluster app answers applied on every target, deep merging global, cluster and project answers",
+				Elem: &schema.Resource{
+					Schema: answerFields(),
+				},
+			},
 			"members": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -45,6 +76,14 @@ func dataSourceRancher2MultiClusterApp() *schema.Resource {
 					Schema: memberFields(),
 				},
 			},
+			"member_effective_permissions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Multi cluster app members effective permissions",
+				Elem: &schema.Resource{
+					Schema: memberEffectivePermissionFields(),
+				},
+			},
 			"revision_history_limit": {
 				Type:        schema.TypeInt,
 				Computed:    true,
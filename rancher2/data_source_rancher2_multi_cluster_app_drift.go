@@ -0,0 +1,179 @@
+package rancher2
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	projectClient "github.com/rancher/rancher/pkg/client/generated/project/v3"
+)
+
+func dataSourceRancher2MultiClusterAppDrift() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRancher2MultiClusterAppDriftRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Multi cluster app name",
+			},
+			"drifted": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Any multi cluster app target is out of sync",
+			},
+			"targets": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Multi cluster app targets drift report",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"project_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Project ID for target",
+						},
+						"app_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "App ID for target",
+						},
+						"drifted": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Target app is out of sync",
+						},
+						"missing": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Target app is not found",
+						},
+						"desired_external_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Multi cluster app template external ID",
+						},
+						"installed_external_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Target app installed template external ID",
+						},
+						"drifted_answers": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Answer keys whose installed value differs from the desired one",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRancher2MultiClusterAppDriftRead(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	filters := map[string]interface{}{
+		"name": name,
+	}
+
+	listOpts := NewListOpts(filters)
+
+	client, err := meta.(*Config).ManagementClient()
+	if err != nil {
+		return err
+	}
+
+	multiClusterApps, err := client.MultiClusterApp.List(listOpts)
+	if err != nil {
+		return err
+	}
+
+	count := len(multiClusterApps.Data)
+	if count <= 0 {
+		return fmt.Errorf("[ERROR] multi cluster app with name \"%s\" not found", name)
+	}
+	if count > 1 {
+		return fmt.Errorf("[ERROR] found %d multi cluster app with name \"%s\"", count, name)
+	}
+
+	multiClusterApp := &multiClusterApps.Data[0]
+
+	templateVersion, err := client.TemplateVersion.ByID(multiClusterApp.TemplateVersionID)
+	if err != nil {
+		return err
+	}
+
+	getTargetApp := func(target managementClient.Target) (*projectClient.App, error) {
+		return getMultiClusterAppTargetApp(meta, target)
+	}
+	targets, drifted, err := multiClusterAppDriftReport(multiClusterApp, templateVersion.ExternalID, getTargetApp)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(multiClusterApp.ID)
+	d.Set("drifted", drifted)
+
+	return d.Set("targets", targets)
+}
+
+// multiClusterAppDriftReport compares every target app against the multi cluster app desired template and answers
+func multiClusterAppDriftReport(in *managementClient.MultiClusterApp, externalID string, getTargetApp func(managementClient.Target) (*projectClient.App, error)) ([]interface{}, bool, error) {
+	out := make([]interface{}, 0, len(in.Targets))
+	drifted := false
+	for _, t := range in.Targets {
+		obj := map[string]interface{}{
+			"project_id":          t.ProjectID,
+			"app_id":              t.AppID,
+			"desired_external_id": externalID,
+			"missing":             false,
+			"drifted_answers":     []interface{}{},
+		}
+
+		app, err := getTargetApp(t)
+		if err != nil {
+			if !IsNotFound(err) && !IsForbidden(err) {
+				return nil, false, fmt.Errorf("[ERROR] Getting multi cluster app %s target app %s: %v", in.ID, t.AppID, err)
+			}
+			obj["missing"] = true
+			obj["drifted"] = true
+			drifted = true
+			out = append(out, obj)
+			continue
+		}
+
+		obj["installed_external_id"] = app.ExternalID
+		driftedAnswers := multiClusterAppDriftedAnswers(effectiveAnswerValues(in.Answers, t.ProjectID), app.Answers)
+		obj["drifted_answers"] = toArrayInterface(driftedAnswers)
+		obj["drifted"] = app.ExternalID != externalID || len(driftedAnswers) > 0
+		if obj["drifted"].(bool) {
+			drifted = true
+		}
+		out = append(out, obj)
+	}
+
+	return out, drifted, nil
+}
+
+func multiClusterAppDriftedAnswers(desired, installed map[string]string) []string {
+	out := []string{}
+	for k, v := range desired {
+		if installed[k] != v {
+			out = append(out, k)
+		}
+	}
+	for k := range installed {
+		if _, ok := desired[k]; !ok {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+
+	return out
+}
@@ -0,0 +1,65 @@
+package rancher2
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rancher/norman/clientbase"
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	projectClient "github.com/rancher/rancher/pkg/client/generated/project/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiClusterAppDriftReport(t *testing.T) {
+	externalID := "catalog://?catalog=test&template=test-demo&version=1.23.0"
+	mca := &managementClient.MultiClusterApp{
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-synced", AppID: "mcapp-foo"},
+			{ProjectID: "c-abcde:p-drifted", AppID: "mcapp-foo"},
+			{ProjectID: "c-abcde:p-missing", AppID: "mcapp-foo"},
+		},
+		Answers: []managementClient.Answer{
+			{
+				Values: map[string]string{
+					"ingress.host": "test.example.com",
+				},
+			},
+		},
+	}
+	apps := map[string]*projectClient.App{
+		"c-abcde:p-synced": {
+			ExternalID: externalID,
+			Answers:    map[string]string{"ingress.host": "test.example.com"},
+		},
+		"c-abcde:p-drifted": {
+			ExternalID: "catalog://?catalog=test&template=test-demo&version=1.22.0",
+			Answers:    map[string]string{"ingress.host": "old.example.com"},
+		},
+	}
+	getTargetApp := func(target managementClient.Target) (*projectClient.App, error) {
+		app, ok := apps[target.ProjectID]
+		if !ok {
+			return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+		}
+		return app, nil
+	}
+
+	output, drifted, err := multiClusterAppDriftReport(mca, externalID, getTargetApp)
+	assert.NoError(t, err)
+	assert.True(t, drifted)
+	assert.Len(t, output, 3)
+
+	synced := output[0].(map[string]interface{})
+	assert.False(t, synced["drifted"].(bool))
+	assert.Equal(t, []interface{}{}, synced["drifted_answers"])
+
+	drift := output[1].(map[string]interface{})
+	assert.True(t, drift["drifted"].(bool))
+	assert.False(t, drift["missing"].(bool))
+	assert.Equal(t, "catalog://?catalog=test&template=test-demo&version=1.22.0", drift["installed_external_id"])
+	assert.Equal(t, []interface{}{"ingress.host"}, drift["drifted_answers"])
+
+	missing := output[2].(map[string]interface{})
+	assert.True(t, missing["drifted"].(bool))
+	assert.True(t, missing["missing"].(bool))
+}
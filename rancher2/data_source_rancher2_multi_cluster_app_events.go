@@ -0,0 +1,173 @@
+package rancher2
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	norman "github.com/rancher/norman/types"
+)
+
+const (
+	eventV1APIType              = "event"
+	multiClusterAppEventKind    = "MultiClusterApp"
+	multiClusterAppEventsNoteNA = "Events are not available"
+)
+
+// EventV1 is the subset of a Kubernetes event read from the Rancher v1 API
+type EventV1 struct {
+	ID             string `json:"id,omitempty"`
+	InvolvedObject struct {
+		Kind      string `json:"kind,omitempty"`
+		Name      string `json:"name,omitempty"`
+		Namespace string `json:"namespace,omitempty"`
+	} `json:"involvedObject,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	Source  struct {
+		Component string `json:"component,omitempty"`
+	} `json:"source,omitempty"`
+	ReportingController string `json:"reportingComponent,omitempty"`
+	FirstTimestamp      string `json:"firstTimestamp,omitempty"`
+	LastTimestamp       string `json:"lastTimestamp,omitempty"`
+	EventTime           string `json:"eventTime,omitempty"`
+}
+
+type EventV1Collection struct {
+	norman.Collection
+	Data []EventV1 `json:"data,omitempty"`
+}
+
+func dataSourceRancher2MultiClusterAppEvents() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRancher2MultiClusterAppEventsRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Multi cluster app name",
+			},
+			"limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of events, the most recent first. All events if 0",
+			},
+			"events": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Multi cluster app events, the most recent first",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"actor": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Component reporting the event",
+						},
+						"action": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Event reason",
+						},
+						"message": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Event message",
+						},
+						"timestamp": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Event last timestamp",
+						},
+					},
+				},
+			},
+			"note": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Reason why events are not available, if any",
+			},
+		},
+	}
+}
+
+func dataSourceRancher2MultiClusterAppEventsRead(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	listEvents := func() ([]EventV1, error) {
+		client, err := meta.(*Config).CatalogV2Client(rancher2DefaultLocalClusterID)
+		if err != nil {
+			return nil, err
+		}
+		resp := &EventV1Collection{}
+		err = client.List(eventV1APIType, NewListOpts(nil), resp)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Data, nil
+	}
+
+	events, note, err := multiClusterAppEvents(listEvents, name, d.Get("limit").(int))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(MultiClusterAppTemplatePrefix + name)
+	d.Set("note", note)
+
+	return d.Set("events", events)
+}
+
+// multiClusterAppEvents returns the events involving the multi cluster app name, the most recent first. If events
+// can't be read, e.g. the user can't list them, an empty list is returned with a note
+func multiClusterAppEvents(listEvents func() ([]EventV1, error), name string, limit int) ([]interface{}, string, error) {
+	events, err := listEvents()
+	if err != nil {
+		if IsForbidden(err) || IsNotFound(err) || IsUnknownSchemaType(err) {
+			return []interface{}{}, fmt.Sprintf("%s: %v", multiClusterAppEventsNoteNA, err), nil
+		}
+		return nil, "", fmt.Errorf("[ERROR] Listing multi cluster app %s events: %v", name, err)
+	}
+
+	matched := []EventV1{}
+	for _, e := range events {
+		if e.InvolvedObject.Kind == multiClusterAppEventKind && e.InvolvedObject.Name == name {
+			matched = append(matched, e)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return eventV1Timestamp(matched[i]) > eventV1Timestamp(matched[j])
+	})
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+
+	out := make([]interface{}, 0, len(matched))
+	for _, e := range matched {
+		actor := e.ReportingController
+		if len(actor) == 0 {
+			actor = e.Source.Component
+		}
+		out = append(out, map[string]interface{}{
+			"actor":     actor,
+			"action":    e.Reason,
+			"message":   e.Message,
+			"timestamp": eventV1Timestamp(e),
+		})
+	}
+
+	return out, "", nil
+}
+
+// eventV1Timestamp returns the most recent RFC3339 timestamp of the event
+func eventV1Timestamp(e EventV1) string {
+	for _, ts := range []string{e.LastTimestamp, e.EventTime, e.FirstTimestamp} {
+		if len(ts) > 0 {
+			return ts
+		}
+	}
+	return ""
+}
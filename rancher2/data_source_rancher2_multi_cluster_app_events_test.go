@@ -0,0 +1,64 @@
+package rancher2
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rancher/norman/clientbase"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEventV1(kind, name, reason, component, lastTimestamp string) EventV1 {
+	e := EventV1{
+		Reason:        reason,
+		Message:       reason + " " + name,
+		LastTimestamp: lastTimestamp,
+	}
+	e.InvolvedObject.Kind = kind
+	e.InvolvedObject.Name = name
+	e.Source.Component = component
+	return e
+}
+
+func TestMultiClusterAppEvents(t *testing.T) {
+	updated := testEventV1("MultiClusterApp", "foo", "Updated", "mcapp-controller", "2023-05-02T10:00:00Z")
+	updated.ReportingController = "rancher"
+	listEvents := func() ([]EventV1, error) {
+		return []EventV1{
+			testEventV1("MultiClusterApp", "foo", "Created", "mcapp-controller", "2023-05-01T10:00:00Z"),
+			testEventV1("MultiClusterApp", "bar", "Created", "mcapp-controller", "2023-05-01T11:00:00Z"),
+			testEventV1("App", "foo", "Installed", "helm-controller", "2023-05-01T12:00:00Z"),
+			updated,
+		}, nil
+	}
+
+	events, note, err := multiClusterAppEvents(listEvents, "foo", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "", note)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"actor":     "rancher",
+			"action":    "Updated",
+			"message":   "Updated foo",
+			"timestamp": "2023-05-02T10:00:00Z",
+		},
+		map[string]interface{}{
+			"actor":     "mcapp-controller",
+			"action":    "Created",
+			"message":   "Created foo",
+			"timestamp": "2023-05-01T10:00:00Z",
+		},
+	}, events)
+
+	events, _, err = multiClusterAppEvents(listEvents, "foo", 1)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	forbidden := func() ([]EventV1, error) {
+		return nil, &clientbase.APIError{StatusCode: http.StatusForbidden}
+	}
+	events, note, err = multiClusterAppEvents(forbidden, "foo", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{}, events)
+	assert.Contains(t, note, multiClusterAppEventsNoteNA)
+}
@@ -0,0 +1,155 @@
+package rancher2
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+)
+
+const multiClusterAppKubeconfigTokenDesc = "Terraform multi cluster app kubeconfig"
+
+func dataSourceRancher2MultiClusterAppKubeconfigs() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRancher2MultiClusterAppKubeconfigsRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Multi cluster app name",
+			},
+			"ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "TTL in seconds of the kubeconfig tokens. Rancher default kubeconfig token TTL is used if 0",
+			},
+			"kube_configs": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Kubeconfig of every multi cluster app target cluster, by cluster ID",
+			},
+			"failed_clusters": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Target cluster IDs whose kubeconfig couldn't be generated",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRancher2MultiClusterAppKubeconfigsRead(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+	ttl := d.Get("ttl").(int)
+
+	filters := map[string]interface{}{
+		"name": name,
+	}
+
+	listOpts := NewListOpts(filters)
+
+	client, err := meta.(*Config).ManagementClient()
+	if err != nil {
+		return err
+	}
+
+	multiClusterApps, err := client.MultiClusterApp.List(listOpts)
+	if err != nil {
+		return err
+	}
+
+	count := len(multiClusterApps.Data)
+	if count <= 0 {
+		return fmt.Errorf("[ERROR] multi cluster app with name \"%s\" not found", name)
+	}
+	if count > 1 {
+		return fmt.Errorf("[ERROR] found %d multi cluster app with name \"%s\"", count, name)
+	}
+
+	multiClusterApp := &multiClusterApps.Data[0]
+
+	getKubeconfig := func(clusterID string) (string, error) {
+		return getMultiClusterAppKubeconfig(meta.(*Config), clusterID, ttl)
+	}
+	kubeConfigs, failed := multiClusterAppTargetKubeconfigs(multiClusterApp.Targets, getKubeconfig)
+	if len(failed) > 0 {
+		log.Printf("[WARN] Kubeconfig couldn't be generated for multi cluster app %s target clusters: %v", multiClusterApp.ID, failed)
+	}
+
+	d.SetId(multiClusterApp.ID)
+	d.Set("kube_configs", kubeConfigs)
+
+	return d.Set("failed_clusters", toArrayInterface(failed))
+}
+
+// multiClusterAppTargetKubeconfigs generates the kubeconfig of every distinct target cluster. Clusters failing to
+// generate one are skipped and returned sorted
+func multiClusterAppTargetKubeconfigs(targets []managementClient.Target, getKubeconfig func(string) (string, error)) (map[string]interface{}, []string) {
+	kubeConfigs := map[string]interface{}{}
+	failed := []string{}
+	seen := map[string]bool{}
+	for _, t := range targets {
+		clusterID, err := clusterIDFromProjectID(t.ProjectID)
+		if err != nil || seen[clusterID] {
+			continue
+		}
+		seen[clusterID] = true
+		kubeConfig, err := getKubeconfig(clusterID)
+		if err != nil || len(kubeConfig) == 0 {
+			log.Printf("[DEBUG] Generating kubeconfig for cluster %s: %v", clusterID, err)
+			failed = append(failed, clusterID)
+			continue
+		}
+		kubeConfigs[clusterID] = kubeConfig
+	}
+	sort.Strings(failed)
+
+	return kubeConfigs, failed
+}
+
+// getMultiClusterAppKubeconfig generates the cluster kubeconfig. If ttl is set, its token is replaced by a new
+// cluster scoped token expiring after ttl seconds
+func getMultiClusterAppKubeconfig(c *Config, clusterID string, ttl int) (string, error) {
+	kubeConfig, err := getClusterKubeconfig(c, clusterID, "")
+	if err != nil {
+		return "", err
+	}
+	if ttl == 0 || len(kubeConfig.Config) == 0 {
+		return kubeConfig.Config, nil
+	}
+
+	kubeconfig, err := getObjFromKubeConfig(kubeConfig.Config)
+	if err != nil {
+		return "", fmt.Errorf("getting K8s config object: %v", err)
+	}
+	if kubeconfig == nil || len(kubeconfig.AuthInfos) == 0 {
+		return kubeConfig.Config, nil
+	}
+
+	client, err := c.ManagementClient()
+	if err != nil {
+		return "", err
+	}
+	token, err := client.Token.Create(&managementClient.Token{
+		ClusterID:   clusterID,
+		Description: multiClusterAppKubeconfigTokenDesc,
+		TTLMillis:   int64(ttl) * 1000,
+	})
+	if err != nil {
+		return "", fmt.Errorf("[ERROR] Creating kubeconfig token for cluster %s: %v", clusterID, err)
+	}
+	for i := range kubeconfig.AuthInfos {
+		kubeconfig.AuthInfos[i].AuthInfo.Token = token.Token
+	}
+
+	return getKubeConfigFromObj(kubeconfig)
+}
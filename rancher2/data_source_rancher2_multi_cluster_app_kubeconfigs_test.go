@@ -0,0 +1,36 @@
+package rancher2
+
+import (
+	"fmt"
+	"testing"
+
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiClusterAppTargetKubeconfigs(t *testing.T) {
+	targets := []managementClient.Target{
+		{ProjectID: "c-abcde:p-one"},
+		{ProjectID: "c-abcde:p-two"},
+		{ProjectID: "c-fghij:p-three"},
+		{ProjectID: "c-klmno:p-four"},
+	}
+	clusters := map[string]string{
+		"c-abcde": "kubeconfig-abcde",
+		"c-klmno": "",
+	}
+	calls := map[string]int{}
+	getKubeconfig := func(clusterID string) (string, error) {
+		calls[clusterID]++
+		kubeConfig, ok := clusters[clusterID]
+		if !ok {
+			return "", fmt.Errorf("cluster %s is not available", clusterID)
+		}
+		return kubeConfig, nil
+	}
+
+	kubeConfigs, failed := multiClusterAppTargetKubeconfigs(targets, getKubeconfig)
+	assert.Equal(t, map[string]interface{}{"c-abcde": "kubeconfig-abcde"}, kubeConfigs)
+	assert.Equal(t, []string{"c-fghij", "c-klmno"}, failed)
+	assert.Equal(t, 1, calls["c-abcde"], "Kubeconfig should be generated once per cluster")
+}
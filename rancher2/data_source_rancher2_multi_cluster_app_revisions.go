@@ -0,0 +1,109 @@
+package rancher2
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+)
+
+const multiClusterAppRevisionsLink = "revisions"
+
+func dataSourceRancher2MultiClusterAppRevisions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRancher2MultiClusterAppRevisionsRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Multi cluster app name",
+			},
+			"latest_n": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of revisions, the most recent first. All revisions if 0",
+			},
+			"revisions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Multi cluster app revisions, the most recent first",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Revision ID",
+						},
+						"created": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Revision creation timestamp",
+						},
+						"template_version_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Revision template version ID",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRancher2MultiClusterAppRevisionsRead(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	client, err := meta.(*Config).ManagementClient()
+	if err != nil {
+		return err
+	}
+
+	id := MultiClusterAppTemplatePrefix + name
+	multiClusterApp, err := client.MultiClusterApp.ByID(id)
+	if err != nil {
+		if IsNotFound(err) {
+			return fmt.Errorf("[ERROR] multi cluster app with name \"%s\" not found", name)
+		}
+		return err
+	}
+
+	resp := &managementClient.MultiClusterAppRevisionCollection{}
+	err = client.GetLink(multiClusterApp.Resource, multiClusterAppRevisionsLink, resp)
+	if err != nil {
+		return fmt.Errorf("[ERROR] Getting multi cluster app %s revisions: %v", name, err)
+	}
+
+	d.SetId(multiClusterApp.ID)
+
+	return d.Set("revisions", flattenMultiClusterAppRevisions(resp.Data, d.Get("latest_n").(int)))
+}
+
+// flattenMultiClusterAppRevisions returns the revisions sorted by creation timestamp, the most recent first, up to
+// latest revisions if latest is greater than 0
+func flattenMultiClusterAppRevisions(in []managementClient.MultiClusterAppRevision, latest int) []interface{} {
+	revisions := make([]managementClient.MultiClusterAppRevision, len(in))
+	copy(revisions, in)
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].Created > revisions[j].Created
+	})
+	if latest > 0 && len(revisions) > latest {
+		revisions = revisions[:latest]
+	}
+
+	out := make([]interface{}, 0, len(revisions))
+	for _, r := range revisions {
+		out = append(out, map[string]interface{}{
+			"id":                  r.ID,
+			"created":             r.Created,
+			"template_version_id": r.TemplateVersionID,
+		})
+	}
+
+	return out
+}
@@ -0,0 +1,51 @@
+package rancher2
+
+import (
+	"testing"
+
+	"github.com/rancher/norman/types"
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMultiClusterAppRevision(id, created, templateVersionID string) managementClient.MultiClusterAppRevision {
+	return managementClient.MultiClusterAppRevision{
+		Resource: types.Resource{
+			ID: id,
+		},
+		Created:           created,
+		TemplateVersionID: templateVersionID,
+	}
+}
+
+func TestFlattenMultiClusterAppRevisions(t *testing.T) {
+	revisions := []managementClient.MultiClusterAppRevision{
+		testMultiClusterAppRevision("cattle-global-data:mcapprevision-a", "2023-05-01T10:00:00Z", "cattle-global-data:test-test-1.23.0"),
+		testMultiClusterAppRevision("cattle-global-data:mcapprevision-c", "2023-05-03T10:00:00Z", "cattle-global-data:test-test-1.25.0"),
+		testMultiClusterAppRevision("cattle-global-data:mcapprevision-b", "2023-05-02T10:00:00Z", "cattle-global-data:test-test-1.24.0"),
+	}
+
+	output := flattenMultiClusterAppRevisions(revisions, 0)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"id":                  "cattle-global-data:mcapprevision-c",
+			"created":             "2023-05-03T10:00:00Z",
+			"template_version_id": "cattle-global-data:test-test-1.25.0",
+		},
+		map[string]interface{}{
+			"id":                  "cattle-global-data:mcapprevision-b",
+			"created":             "2023-05-02T10:00:00Z",
+			"template_version_id": "cattle-global-data:test-test-1.24.0",
+		},
+		map[string]interface{}{
+			"id":                  "cattle-global-data:mcapprevision-a",
+			"created":             "2023-05-01T10:00:00Z",
+			"template_version_id": "cattle-global-data:test-test-1.23.0",
+		},
+	}, output)
+	assert.Equal(t, "cattle-global-data:mcapprevision-a", revisions[0].ID)
+
+	output = flattenMultiClusterAppRevisions(revisions, 2)
+	assert.Len(t, output, 2)
+	assert.Equal(t, "cattle-global-data:mcapprevision-b", output[1].(map[string]interface{})["id"])
+}
@@ -1,6 +1,7 @@
 package rancher2
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
@@ -19,6 +20,10 @@ func dataSourceRancher2Setting() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"default": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
 	}
 }
@@ -33,12 +38,19 @@ func dataSourceRancher2SettingRead(d *schema.ResourceData, meta interface{}) err
 	}
 
 	setting, err := client.Setting.ByID(name)
-	if err != nil || setting == nil {
+	if err != nil {
+		if IsNotFound(err) {
+			return fmt.Errorf("[ERROR] Setting %s not found on Rancher server", name)
+		}
 		return err
 	}
+	if setting == nil {
+		return fmt.Errorf("[ERROR] Setting %s not found on Rancher server", name)
+	}
 
 	d.SetId(name)
 	d.Set("value", setting.Value)
+	d.Set("default", setting.Default)
 
 	return nil
 }
@@ -1,9 +1,14 @@
 package rancher2
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/rancher/norman/clientbase"
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"github.com/stretchr/testify/assert"
 )
 
 const (
@@ -29,3 +34,44 @@ func TestAccRancher2SettingDataSource_accessLog(t *testing.T) {
 		},
 	})
 }
+
+type testSettingOperations struct {
+	managementClient.SettingOperations
+	settings map[string]*managementClient.Setting
+}
+
+func (o *testSettingOperations) ByID(id string) (*managementClient.Setting, error) {
+	setting, ok := o.settings[id]
+	if !ok {
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+	return setting, nil
+}
+
+func TestDataSourceRancher2SettingRead(t *testing.T) {
+	config := &Config{
+		Client: Client{
+			Management: &managementClient.Client{
+				Setting: &testSettingOperations{
+					settings: map[string]*managementClient.Setting{
+						"server-url": {Value: "https://rancher.example.com", Default: ""},
+						"cacerts":    {Value: "", Default: "cert"},
+					},
+				},
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceRancher2Setting().Schema, map[string]interface{}{"name": "cacerts"})
+	err := dataSourceRancher2SettingRead(d, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "cacerts", d.Id())
+	assert.Equal(t, "", d.Get("value"))
+	assert.Equal(t, "cert", d.Get("default"))
+
+	d = schema.TestResourceDataRaw(t, dataSourceRancher2Setting().Schema, map[string]interface{}{"name": "server-urls"})
+	err = dataSourceRancher2SettingRead(d, config)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Setting server-urls not found")
+	}
+}
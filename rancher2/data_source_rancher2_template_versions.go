@@ -0,0 +1,152 @@
+package rancher2
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	gover "github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+)
+
+var templateVersionKubeOperatorRegexp = regexp.MustCompile(`(>=|<=|!=|>|<|=|~>|~|\^)\s+`)
+
+func dataSourceRancher2TemplateVersions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRancher2TemplateVersionsRead,
+
+		Schema: map[string]*schema.Schema{
+			"catalog_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Catalog name. Global catalogs are used if not given as <cluster_id>:<catalog_name> or <project_id>:<catalog_name>",
+			},
+			"template_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Template name",
+			},
+			"kubernetes_version": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Kubernetes version the template versions must be compatible with. Distribution suffixes like +rke2r1 are ignored",
+			},
+			"versions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Compatible template versions, sorted ascending",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"latest_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Newest compatible template version",
+			},
+		},
+	}
+}
+
+func dataSourceRancher2TemplateVersionsRead(d *schema.ResourceData, meta interface{}) error {
+	catalogName := d.Get("catalog_name").(string)
+	templateName := d.Get("template_name").(string)
+	kubernetesVersion := d.Get("kubernetes_version").(string)
+
+	if !strings.Contains(catalogName, ":") {
+		catalogName = MultiClusterAppTemplatePrefix + catalogName
+	}
+
+	templateID := catalogName + "-" + templateName
+
+	client, err := meta.(*Config).ManagementClient()
+	if err != nil {
+		return err
+	}
+
+	template, err := client.Template.ByID(templateID)
+	if err != nil {
+		return err
+	}
+
+	versions, err := templateVersionsCompatible(template.Versions, kubernetesVersion)
+	if err != nil {
+		return err
+	}
+
+	latest := ""
+	if len(versions) > 0 {
+		latest = versions[len(versions)-1]
+	}
+
+	d.SetId(templateID + ":" + kubernetesVersion)
+	d.Set("latest_version", latest)
+
+	return d.Set("versions", toArrayInterface(versions))
+}
+
+// templateVersionsCompatible returns the template versions, sorted ascending, whose kubeVersion constraint is satisfied by kubernetesVersion
+func templateVersionsCompatible(versions []managementClient.TemplateVersionSpec, kubernetesVersion string) ([]string, error) {
+	kubeVersion, err := semver.NewVersion(kubernetesVersion)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] Parsing kubernetes version %s: %v", kubernetesVersion, err)
+	}
+	// Distribution suffixes like +rke2r1 must not prevent matching the upstream release
+	kubeVersion, err = semver.NewVersion(fmt.Sprintf("%d.%d.%d", kubeVersion.Major(), kubeVersion.Minor(), kubeVersion.Patch()))
+	if err != nil {
+		return nil, err
+	}
+
+	var compatible []*gover.Version
+	for _, v := range versions {
+		ok, err := templateVersionKubeCompatible(v.KubeVersion, kubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] Parsing kubeVersion constraint of template version %s: %v", v.Version, err)
+		}
+		if !ok {
+			continue
+		}
+		version, err := gover.NewVersion(v.Version)
+		if err != nil {
+			return nil, err
+		}
+		compatible = append(compatible, version)
+	}
+
+	sort.Sort(gover.Collection(compatible))
+
+	out := make([]string, len(compatible))
+	for i, v := range compatible {
+		out[i] = v.Original()
+	}
+
+	return out, nil
+}
+
+// templateVersionKubeCompatible evaluates a helm kubeVersion constraint, following the helm semver syntax, like ~1.24,
+// ^1.20 or 1.20.x. Space separated constraints are ANDed and "||" separated groups are ORed. An empty constraint is
+// always satisfied
+func templateVersionKubeCompatible(constraint string, kubeVersion *semver.Version) (bool, error) {
+	constraint = strings.TrimSpace(constraint)
+	if len(constraint) == 0 {
+		return true, nil
+	}
+
+	groups := strings.Split(constraint, "||")
+	for i := range groups {
+		group := templateVersionKubeOperatorRegexp.ReplaceAllString(strings.TrimSpace(groups[i]), "$1")
+		fields := strings.FieldsFunc(group, func(r rune) bool {
+			return r == ' ' || r == ','
+		})
+		groups[i] = strings.Join(fields, ",")
+	}
+	constraints, err := semver.NewConstraint(strings.Join(groups, "||"))
+	if err != nil {
+		return false, err
+	}
+
+	return constraints.Check(kubeVersion), nil
+}
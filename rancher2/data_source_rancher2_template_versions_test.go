@@ -0,0 +1,59 @@
+package rancher2
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver"
+	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateVersionsCompatible(t *testing.T) {
+	versions := []managementClient.TemplateVersionSpec{
+		{Version: "1.10.0", KubeVersion: "<1.20.0-0"},
+		{Version: "2.1.0", KubeVersion: ">=1.16.0-0 <1.25.0-0"},
+		{Version: "2.0.0", KubeVersion: ">= 1.19"},
+		{Version: "2.2.0"},
+		{Version: "3.0.0", KubeVersion: ">=1.25.0-0"},
+		{Version: "2.3.0", KubeVersion: "<1.20 || ~1.24.0"},
+	}
+
+	output, err := templateVersionsCompatible(versions, "v1.24.9+rke2r1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2.0.0", "2.1.0", "2.2.0", "2.3.0"}, output)
+
+	output, err = templateVersionsCompatible(versions, "v1.26.4")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2.0.0", "2.2.0", "3.0.0"}, output)
+
+	_, err = templateVersionsCompatible(versions, "invalid")
+	assert.Error(t, err)
+
+	_, err = templateVersionsCompatible([]managementClient.TemplateVersionSpec{{Version: "1.0.0", KubeVersion: ">=foo"}}, "v1.24.9")
+	assert.Error(t, err)
+}
+
+func TestTemplateVersionKubeCompatibleHelmSyntax(t *testing.T) {
+	cases := []struct {
+		constraint  string
+		kubeVersion string
+		expected    bool
+	}{
+		{"~1.24", "1.24.9", true},
+		{"~1.24", "1.25.0", false},
+		{"^1.20", "1.26.4", true},
+		{"^1.20", "1.19.0", false},
+		{"^ 1.20", "1.20.0", true},
+		{"1.20.x", "1.20.5", true},
+		{"1.20.x", "1.21.0", false},
+		{">= 1.19 < 1.25 || 1.26.x", "1.26.1", true},
+	}
+	for _, c := range cases {
+		kubeVersion, err := semver.NewVersion(c.kubeVersion)
+		assert.NoError(t, err)
+		ok, err := templateVersionKubeCompatible(c.constraint, kubeVersion)
+		if assert.NoError(t, err, c.constraint) {
+			assert.Equal(t, c.expected, ok, "%s on %s", c.constraint, c.kubeVersion)
+		}
+	}
+}
@@ -1,18 +1,45 @@
 package rancher2
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 )
 
 func resourceRancher2AppV2Import(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	clusterID, name := splitID(d.Id())
+	clusterID, namespace, name, err := splitAppV2ImportID(d.Id())
+	if err != nil {
+		return []*schema.ResourceData{}, err
+	}
+	d.SetId(clusterID + appV2ClusterIDsep + namespace + "/" + name)
 	d.Set("cluster_id", clusterID)
+	d.Set("namespace", namespace)
 	d.Set("name", name)
 
-	err := resourceRancher2AppV2Read(d, meta)
+	err = resourceRancher2AppV2Read(d, meta)
 	if err != nil || d.Id() == "" {
 		return []*schema.ResourceData{}, err
 	}
 
 	return []*schema.ResourceData{d}, nil
 }
+
+// splitAppV2ImportID splits app v2 import ID, as <cluster_id>.<namespace>/<name> or <cluster_id>:<namespace>:<name>
+func splitAppV2ImportID(id string) (string, string, string, error) {
+	var clusterID, namespace, name string
+	if fields := strings.Split(id, ":"); len(fields) == 3 {
+		clusterID, namespace, name = fields[0], fields[1], fields[2]
+	} else {
+		var rancherID string
+		clusterID, rancherID = splitID(id)
+		if fields := strings.SplitN(rancherID, "/", 2); len(fields) == 2 {
+			namespace, name = fields[0], fields[1]
+		}
+	}
+	if len(clusterID) == 0 || len(namespace) == 0 || len(name) == 0 {
+		return "", "", "", fmt.Errorf("[ERROR] importing App V2 %q: ID must be <cluster_id>.<namespace>/<name> or <cluster_id>:<namespace>:<name>", id)
+	}
+
+	return clusterID, namespace, name, nil
+}
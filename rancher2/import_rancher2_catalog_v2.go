@@ -1,11 +1,14 @@
 package rancher2
 
 import (
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 )
 
 func resourceRancher2CatalogV2Import(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	clusterID, name := splitID(d.Id())
+	clusterID, name := splitID(catalogV2ImportID(d.Id()))
+	d.SetId(clusterID + catalogV2ClusterIDsep + name)
 	d.Set("cluster_id", clusterID)
 	d.Set("name", name)
 
@@ -16,3 +19,14 @@ func resourceRancher2CatalogV2Import(d *schema.ResourceData, meta interface{}) (
 
 	return []*schema.ResourceData{d}, nil
 }
+
+// catalogV2ImportID returns the import ID in <cluster_id>.<name> format, also accepting <cluster_id>:<name>.
+// Cluster IDs don't contain dots, but catalog V2 names may
+func catalogV2ImportID(id string) string {
+	i := strings.Index(id, ":")
+	if i <= 0 || strings.Contains(id[:i], catalogV2ClusterIDsep) {
+		return id
+	}
+
+	return id[:i] + catalogV2ClusterIDsep + id[i+1:]
+}
@@ -1,6 +1,8 @@
 package rancher2
 
 import (
+	"log"
+
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 )
 
@@ -14,5 +16,24 @@ func resourceRancher2MultiClusterAppImport(d *schema.ResourceData, meta interfac
 		return []*schema.ResourceData{}, err
 	}
 
+	if len(d.Id()) > 0 {
+		err = d.Set("answers", importMultiClusterAppAnswers(d.Get("answers").([]interface{}), d.Get("targets").([]interface{})))
+		if err != nil {
+			return []*schema.ResourceData{}, err
+		}
+		client, err := multiClusterAppClient(d.Get, meta)
+		if err != nil {
+			return []*schema.ResourceData{}, err
+		}
+		err = multiClusterAppSetTargetNamespaces(d, meta, client, expandTargets(d.Get("targets").([]interface{})), true)
+		if err != nil {
+			return []*schema.ResourceData{}, err
+		}
+		if owner := d.Get("gitops_owner").(string); len(owner) > 0 {
+			log.Printf("[WARN] Multi cluster app ID %s is managed by %s, importing it as read_only. Set read_only to true on config, or remove it from %s before managing it with terraform", resourceID, owner, owner)
+			d.Set("read_only", true)
+		}
+	}
+
 	return []*schema.ResourceData{d}, nil
 }
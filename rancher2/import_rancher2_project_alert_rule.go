@@ -1,14 +1,39 @@
 package rancher2
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 )
 
 func resourceRancher2ProjectAlertRuleImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	d.SetId(projectAlertRuleImportID(id))
+
 	err := resourceRancher2ProjectAlertRuleRead(d, meta)
 	if err != nil {
 		return []*schema.ResourceData{}, err
 	}
+	if d.Id() == "" {
+		return []*schema.ResourceData{}, fmt.Errorf("[ERROR] Project Alert Rule %s not found", id)
+	}
 
 	return []*schema.ResourceData{d}, nil
 }
+
+// projectAlertRuleImportID returns the project alert rule ID, <project_name>:<rule_name>, also accepting the
+// <project_id>:<rule_id> format, e.g. c-xxxxx:p-yyyyy:p-yyyyy:zzzzz or c-xxxxx:p-yyyyy:zzzzz
+func projectAlertRuleImportID(id string) string {
+	fields := strings.Split(id, clusterProjectIDSeparator)
+	switch len(fields) {
+	case 3:
+		return fields[1] + clusterProjectIDSeparator + fields[2]
+	case 4:
+		if fields[1] == fields[2] {
+			return fields[2] + clusterProjectIDSeparator + fields[3]
+		}
+	}
+
+	return id
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
@@ -33,7 +34,7 @@ type CLIConfig struct {
 
 // Provider returns a terraform.ResourceProvider.
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"api_url": {
 				Type:        schema.TypeString,
@@ -41,6 +42,12 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("RANCHER_URL", providerDefaultEmptyString),
 				Description: descriptions["api_url"],
 			},
+			"read_endpoint": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("RANCHER_READ_URL", ""),
+				Description: descriptions["read_endpoint"],
+			},
 			"access_key": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -104,6 +111,113 @@ func Provider() terraform.ResourceProvider {
 					return
 				},
 			},
+			"answers_encryption_passphrase": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("RANCHER_ANSWERS_ENCRYPTION_PASSPHRASE", ""),
+				Description: descriptions["answers_encryption_passphrase"],
+			},
+			"otlp_endpoint": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("OTEL_EXPORTER_OTLP_ENDPOINT", nil),
+				Description:  descriptions["otlp_endpoint"],
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			"default_create_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				Description:  descriptions["default_create_timeout"],
+				ValidateFunc: validatePositiveDuration,
+			},
+			"default_update_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				Description:  descriptions["default_update_timeout"],
+				ValidateFunc: validatePositiveDuration,
+			},
+			"default_delete_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				Description:  descriptions["default_delete_timeout"],
+				ValidateFunc: validatePositiveDuration,
+			},
+			"state_poll_min_interval": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				Description:  descriptions["state_poll_min_interval"],
+				ValidateFunc: validatePositiveDuration,
+			},
+			"state_poll_max_interval": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				Description:  descriptions["state_poll_max_interval"],
+				ValidateFunc: validatePositiveDuration,
+			},
+			"state_poll_backoff_factor": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      float64(rancher2StatePollBackoffFactor),
+				Description:  descriptions["state_poll_backoff_factor"],
+				ValidateFunc: validation.FloatAtLeast(1),
+			},
+			"catalog_resolution_order": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: descriptions["catalog_resolution_order"],
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(catalogResolutionScopes, false),
+				},
+			},
+			"multi_cluster_app_concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  descriptions["multi_cluster_app_concurrency"],
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"multi_cluster_app_wait_budget": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				Description:  descriptions["multi_cluster_app_wait_budget"],
+				ValidateFunc: validatePositiveDuration,
+			},
+			"retry_max_attempts": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				Description:  descriptions["retry_max_attempts"],
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"retry_budget": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: descriptions["retry_budget"],
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v, ok := val.(string)
+					if !ok || len(v) == 0 {
+						return
+					}
+					budget, err := time.ParseDuration(v)
+					if err != nil {
+						errs = append(errs, fmt.Errorf("%q must be in golang duration format, error: %v", key, err))
+						return
+					}
+					if budget < 0 {
+						errs = append(errs, fmt.Errorf("%q must be a positive duration", key))
+					}
+					return
+				},
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
@@ -163,12 +277,14 @@ func Provider() terraform.ResourceProvider {
 
 		DataSourcesMap: map[string]*schema.Resource{
 			"rancher2_app":                           dataSourceRancher2App(),
+			"rancher2_app_defaults":                  dataSourceRancher2AppDefaults(),
 			"rancher2_catalog":                       dataSourceRancher2Catalog(),
 			"rancher2_catalog_v2":                    dataSourceRancher2CatalogV2(),
 			"rancher2_certificate":                   dataSourceRancher2Certificate(),
 			"rancher2_cloud_credential":              dataSourceRancher2CloudCredential(),
 			"rancher2_cluster":                       dataSourceRancher2Cluster(),
 			"rancher2_cluster_v2":                    dataSourceRancher2ClusterV2(),
+			"rancher2_cluster_v2_kubeconfig":         dataSourceRancher2ClusterV2Kubeconfig(),
 			"rancher2_cluster_alert_group":           dataSourceRancher2ClusterAlertGroup(),
 			"rancher2_cluster_alert_rule":            dataSourceRancher2ClusterAlertRule(),
 			"rancher2_cluster_driver":                dataSourceRancher2ClusterDriver(),
@@ -180,6 +296,10 @@ func Provider() terraform.ResourceProvider {
 			"rancher2_global_role":                   dataSourceRancher2GlobalRole(),
 			"rancher2_global_role_binding":           dataSourceRancher2GlobalRoleBinding(),
 			"rancher2_multi_cluster_app":             dataSourceRancher2MultiClusterApp(),
+			"rancher2_multi_cluster_app_drift":       dataSourceRancher2MultiClusterAppDrift(),
+			"rancher2_multi_cluster_app_events":      dataSourceRancher2MultiClusterAppEvents(),
+			"rancher2_multi_cluster_app_kubeconfigs": dataSourceRancher2MultiClusterAppKubeconfigs(),
+			"rancher2_multi_cluster_app_revisions":   dataSourceRancher2MultiClusterAppRevisions(),
 			"rancher2_namespace":                     dataSourceRancher2Namespace(),
 			"rancher2_node_driver":                   dataSourceRancher2NodeDriver(),
 			"rancher2_node_pool":                     dataSourceRancher2NodePool(),
@@ -197,29 +317,53 @@ func Provider() terraform.ResourceProvider {
 			"rancher2_secret_v2":                     dataSourceRancher2SecretV2(),
 			"rancher2_setting":                       dataSourceRancher2Setting(),
 			"rancher2_storage_class_v2":              dataSourceRancher2StorageClassV2(),
+			"rancher2_template_versions":             dataSourceRancher2TemplateVersions(),
 			"rancher2_user":                          dataSourceRancher2User(),
 		},
-
-		ConfigureFunc: providerConfigure,
 	}
+	provider.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
+		config, err := providerConfigure(d)
+		if c, ok := config.(*Config); ok && c != nil {
+			// Cancelled once Terraform stops the provider, e.g. on interrupt
+			c.StopContext = provider.StopContext()
+		}
+		return config, err
+	}
+
+	return provider
 }
 
 func init() {
 	descriptions = map[string]string{
-		"access_key": "API Key used to authenticate with the rancher server",
-		"secret_key": "API secret used to authenticate with the rancher server",
-		"token_key":  "API token used to authenticate with the rancher server",
-		"ca_certs":   "CA certificates used to sign rancher server tls certificates. Mandatory if self signed tls and insecure option false",
-		"insecure":   "Allow insecure connections to Rancher. Mandatory if self signed tls and not ca_certs provided",
-		"api_url":    "The URL to the rancher API",
-		"bootstrap":  "Bootstrap rancher server",
-		"retries":    "Rancher connection retries",
-		"timeout":    "Rancher connection timeout (retry every 5s). Golang duration format, ex: \"60s\"",
+		"access_key":                    "API Key used to authenticate with the rancher server",
+		"secret_key":                    "API secret used to authenticate with the rancher server",
+		"token_key":                     "API token used to authenticate with the rancher server",
+		"ca_certs":                      "CA certificates used to sign rancher server tls certificates. Mandatory if self signed tls and insecure option false",
+		"insecure":                      "Allow insecure connections to Rancher. Mandatory if self signed tls and not ca_certs provided",
+		"api_url":                       "The URL to the rancher API",
+		"read_endpoint":                 "The URL to a read optimized rancher API endpoint, used to refresh multi cluster apps",
+		"bootstrap":                     "Bootstrap rancher server",
+		"retries":                       "Rancher connection retries",
+		"timeout":                       "Rancher connection timeout (retry every 5s). Golang duration format, ex: \"60s\"",
+		"retry_max_attempts":            "Maximum number of attempts of Rancher API calls failing with throttling or server errors, retried with exponential backoff",
+		"retry_budget":                  "Maximum cumulative time spent retrying Rancher API calls during an apply. Golang duration format, ex: \"10m\". Unlimited if empty",
+		"answers_encryption_passphrase": "Passphrase used to encrypt sensitive answers stored on state",
+		"otlp_endpoint":                 "OpenTelemetry protocol HTTP endpoint to export multi cluster app operation traces to, ex: \"http://localhost:4318\". Tracing is disabled if empty",
+		"default_create_timeout":        "Default create timeout for resources not setting it on their timeouts block. Golang duration format, ex: \"10m\"",
+		"default_update_timeout":        "Default update timeout for resources not setting it on their timeouts block. Golang duration format, ex: \"10m\"",
+		"default_delete_timeout":        "Default delete timeout for resources not setting it on their timeouts block. Golang duration format, ex: \"10m\"",
+		"state_poll_min_interval":       "Initial interval between state polls while waiting for resources to reach a state. Golang duration format, ex: \"3s\"",
+		"state_poll_max_interval":       "Maximum interval between state polls while waiting for resources to reach a state. Golang duration format, ex: \"30s\"",
+		"state_poll_backoff_factor":     "Factor the interval between state polls grows by after every poll, up to state_poll_max_interval",
+		"catalog_resolution_order":      "Catalog scopes tried, in order, to find a multi cluster app template whose catalog name is not scoped. Allowed values: global, cluster, project. Just global if empty",
+		"multi_cluster_app_concurrency": "Maximum number of multi cluster app create and update operations running simultaneously. Unlimited if 0",
+		"multi_cluster_app_wait_budget": "Maximum cumulative time spent by multi cluster apps waiting for Rancher during an apply. Golang duration format, ex: \"30m\". Unlimited if empty",
 	}
 }
 
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	apiURL := d.Get("api_url").(string)
+	readURL := d.Get("read_endpoint").(string)
 	accessKey := d.Get("access_key").(string)
 	secretKey := d.Get("secret_key").(string)
 	tokenKey := d.Get("token_key").(string)
@@ -232,18 +376,72 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		return nil, fmt.Errorf("[ERROR] timeout must be in golang duration format, error: %v", err)
 	}
 
+	var retryBudget time.Duration
+	if v := d.Get("retry_budget").(string); len(v) > 0 {
+		retryBudget, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] retry_budget must be in golang duration format, error: %v", err)
+		}
+	}
+
+	var multiClusterAppWaitBudget time.Duration
+	if v := d.Get("multi_cluster_app_wait_budget").(string); len(v) > 0 {
+		multiClusterAppWaitBudget, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] multi_cluster_app_wait_budget must be in golang duration format, error: %v", err)
+		}
+	}
+
 	// Set tokenKey based on accessKey and secretKey if needed
 	if tokenKey == providerDefaultEmptyString && accessKey != providerDefaultEmptyString && secretKey != providerDefaultEmptyString {
 		tokenKey = accessKey + ":" + secretKey
 	}
 
+	defaultTimeouts := map[string]time.Duration{}
+	for _, key := range []string{schema.TimeoutCreate, schema.TimeoutUpdate, schema.TimeoutDelete} {
+		if v := d.Get("default_" + key + "_timeout").(string); len(v) > 0 {
+			defaultTimeout, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("[ERROR] default_%s_timeout must be in golang duration format, error: %v", key, err)
+			}
+			defaultTimeouts[key] = defaultTimeout
+		}
+	}
+
+	statePollIntervals := map[string]time.Duration{}
+	for _, key := range []string{"min", "max"} {
+		if v := d.Get("state_poll_" + key + "_interval").(string); len(v) > 0 {
+			interval, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("[ERROR] state_poll_%s_interval must be in golang duration format, error: %v", key, err)
+			}
+			statePollIntervals[key] = interval
+		}
+	}
+
 	config := &Config{
-		URL:       apiURL,
-		TokenKey:  tokenKey,
-		CACerts:   caCerts,
-		Insecure:  insecure,
-		Bootstrap: bootstrap,
-		Timeout:   timeout,
+		URL:                        apiURL,
+		ReadURL:                    readURL,
+		TokenKey:                   tokenKey,
+		CACerts:                    caCerts,
+		Insecure:                   insecure,
+		Bootstrap:                  bootstrap,
+		Timeout:                    timeout,
+		RetryBudget:                retryBudget,
+		RetryMaxAttempts:           d.Get("retry_max_attempts").(int),
+		MultiClusterAppConcurrency: d.Get("multi_cluster_app_concurrency").(int),
+		MultiClusterAppWaitBudget:  multiClusterAppWaitBudget,
+		StatePollMinInterval:       statePollIntervals["min"],
+		StatePollMaxInterval:       statePollIntervals["max"],
+		StatePollBackoffFactor:     d.Get("state_poll_backoff_factor").(float64),
+		DefaultTimeouts:            defaultTimeouts,
+		CatalogResolutionOrder:     toArrayString(d.Get("catalog_resolution_order").([]interface{})),
+	}
+	if passphrase := d.Get("answers_encryption_passphrase").(string); len(passphrase) > 0 {
+		config.AnswersEncrypter = newPassphraseAnswersEncrypter(passphrase)
+	}
+	if endpoint := d.Get("otlp_endpoint").(string); len(endpoint) > 0 {
+		config.Tracer = newTracer(newOTLPSpanExporter(endpoint))
 	}
 
 	return providerValidateConfig(config)
@@ -69,11 +69,16 @@ func resourceRancher2AppV2Create(d *schema.ResourceData, meta interface{}) error
 	if err != nil {
 		return err
 	}
-	err = appV2OperationWait(meta, clusterID, chartOperation.OperationNamespace+"/"+chartOperation.OperationName)
+	err = appV2OperationWait(meta, clusterID, chartOperation.OperationNamespace+"/"+chartOperation.OperationName, d.Timeout(schema.TimeoutCreate))
 	if err != nil {
 		return fmt.Errorf("[ERROR] installing App V2: %s", err)
 	}
+	// Installed app is kept on state if it isn't deployed, so it can be upgraded or deleted
 	d.SetId(clusterID + appV2ClusterIDsep + chartInstallAction.Namespace + "/" + d.Get("name").(string))
+	err = appV2WaitForDeployed(meta, clusterID, chartInstallAction.Namespace+"/"+name, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return fmt.Errorf("[ERROR] installing App V2: %s", err)
+	}
 
 	return resourceRancher2AppV2Read(d, meta)
 }
@@ -138,7 +143,12 @@ func resourceRancher2AppV2Update(d *schema.ResourceData, meta interface{}) error
 	if err != nil {
 		return err
 	}
-	err = appV2OperationWait(meta, clusterID, chartOperation.OperationNamespace+"/"+chartOperation.OperationName)
+	err = appV2OperationWait(meta, clusterID, chartOperation.OperationNamespace+"/"+chartOperation.OperationName, d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		return fmt.Errorf("[ERROR] upgrading App V2: %s", err)
+	}
+	_, rancherID := splitID(d.Id())
+	err = appV2WaitForDeployed(meta, clusterID, rancherID, d.Timeout(schema.TimeoutUpdate))
 	if err != nil {
 		return fmt.Errorf("[ERROR] upgrading App V2: %s", err)
 	}
@@ -231,7 +241,9 @@ func appV2StateRefreshFunc(meta interface{}, clusterID, appID string) resource.S
 	}
 }
 
-func appV2OperationWait(meta interface{}, clusterID, opID string) error {
+// appV2OperationWait waits until the helm operation opID finishes, returning the operation logs if it failed
+func appV2OperationWait(meta interface{}, clusterID, opID string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
 	for obj, err := getAppV2OperationByID(meta.(*Config), clusterID, opID); ; obj, err = getAppV2OperationByID(meta.(*Config), clusterID, opID) {
 		if err != nil {
 			return err
@@ -251,10 +263,39 @@ func appV2OperationWait(meta interface{}, clusterID, opID string) error {
 
 			}
 		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout after %s waiting for operation %s", timeout, opID)
+		}
 		time.Sleep(5 * time.Second)
 	}
 }
 
+// appV2WaitForDeployed waits until the app appID installed or upgraded by a helm operation is deployed. The app being
+// removed is a failure
+func appV2WaitForDeployed(meta interface{}, clusterID, appID string, timeout time.Duration) error {
+	refresh := appV2StateRefreshFunc(meta, clusterID, appID)
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{},
+		Target:  []string{"deployed"},
+		Refresh: func() (interface{}, string, error) {
+			obj, state, err := refresh()
+			if err == nil && state == "removed" {
+				return nil, "", fmt.Errorf("app %s was removed", appID)
+			}
+			return obj, state, err
+		},
+		Timeout:    timeout,
+		Delay:      1 * time.Second,
+		MinTimeout: 3 * time.Second,
+	}
+	_, err := stateConf.WaitForState()
+	if err != nil {
+		return fmt.Errorf("waiting for app (%s) to be deployed: %s", appID, err)
+	}
+
+	return nil
+}
+
 // Rancher2 App V2 API CRUD functions
 func createAppV2(c *Config, clusterID string, repo *ClusterRepo, chartIntall *types2.ChartInstallAction) (*types2.ChartActionOutput, error) {
 	if c == nil {
@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	v3 "github.com/rancher/rancher/pkg/apis/management.cattle.io/v3"
+	"github.com/stretchr/testify/assert"
 )
 
 const testAccRancher2AppV2Type = "rancher2_app_v2"
@@ -73,6 +74,21 @@ EOF
 	testAccRancher2AppV2UpdateConfig = testAccCheckRancher2ClusterSyncTestacc + testAccRancher2AppV2Update
 }
 
+func TestSplitAppV2ImportID(t *testing.T) {
+	for _, id := range []string{"c-abcde.cattle-monitoring-system/rancher-monitoring", "c-abcde:cattle-monitoring-system:rancher-monitoring"} {
+		clusterID, namespace, name, err := splitAppV2ImportID(id)
+		assert.NoError(t, err, "Unexpected error splitting %s", id)
+		assert.Equal(t, "c-abcde", clusterID)
+		assert.Equal(t, "cattle-monitoring-system", namespace)
+		assert.Equal(t, "rancher-monitoring", name)
+	}
+
+	for _, id := range []string{"rancher-monitoring", "c-abcde.rancher-monitoring", "c-abcde::rancher-monitoring", "c-abcde:a:b:c"} {
+		_, _, _, err := splitAppV2ImportID(id)
+		assert.Error(t, err, "Expected error splitting %s", id)
+	}
+}
+
 func TestAccRancher2AppV2_basic(t *testing.T) {
 	var app *AppV2
 
@@ -39,6 +39,23 @@ resource "` + testAccRancher2CatalogV2Type + `" "foo" {
 	testAccRancher2CatalogV2UpdateConfig = testAccCheckRancher2ClusterSyncTestacc + testAccRancher2CatalogV2Update
 }
 
+func TestCatalogV2ImportID(t *testing.T) {
+	cases := map[string]string{
+		"c-abcde.foo":       "c-abcde.foo",
+		"c-abcde:foo":       "c-abcde.foo",
+		"local:foo.bar":     "local.foo.bar",
+		"local.foo:bar":     "local.foo:bar",
+		"foo":               "foo",
+		":foo":              ":foo",
+		"c-abcde:foo:bar.1": "c-abcde.foo:bar.1",
+	}
+	for id, expected := range cases {
+		if output := catalogV2ImportID(id); output != expected {
+			t.Fatalf("[ERROR] catalog V2 import ID %q: expected %q, got %q", id, expected, output)
+		}
+	}
+}
+
 func TestAccRancher2CatalogV2_basic(t *testing.T) {
 	var catalog *ClusterRepo
 
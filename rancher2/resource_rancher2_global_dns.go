@@ -108,6 +108,8 @@ func resourceRancher2GlobalDNSUpdate(d *schema.ResourceData, meta interface{}) e
 	}
 
 	update := map[string]interface{}{
+		"fqdn":        d.Get("fqdn").(string),
+		"ttl":         int64(d.Get("ttl").(int)),
 		"providerId":  d.Get("provider_id").(string),
 		"annotations": toMapString(d.Get("annotations").(map[string]interface{})),
 		"labels":      toMapString(d.Get("labels").(map[string]interface{})),
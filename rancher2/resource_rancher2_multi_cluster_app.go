@@ -1,15 +1,44 @@
 package rancher2
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/rancher/norman/clientbase"
+	clusterClient "github.com/rancher/rancher/pkg/client/generated/cluster/v3"
 	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	projectClient "github.com/rancher/rancher/pkg/client/generated/project/v3"
 )
 
+const (
+	multiClusterAppGetRetries         = 3
+	multiClusterAppDefaultTimeout     = 10 * time.Minute
+	multiClusterAppConsistencyTimeout = 30 * time.Second
+)
+
+// multiClusterAppExclusiveFields are the fields that can't be set together
+var multiClusterAppExclusiveFields = [][]string{
+	// Kept target apps don't remove their namespaces
+	{"keep_target_apps", "wait_for_namespaces_removal"},
+}
+
+// multiClusterAppExclusiveChanges are the fields that can't be changed together with the field of the key. A rollback
+// to revision_id applies the revision as is, ignoring any other change
+var multiClusterAppExclusiveChanges = map[string][]string{
+	"revision_id": {"answers", "answers_object", "group_answers", "members", "roles", "targets", "template_version"},
+}
+
 func resourceRancher2MultiClusterApp() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceRancher2MultiClusterAppCreate,
@@ -20,19 +49,76 @@ func resourceRancher2MultiClusterApp() *schema.Resource {
 			State: resourceRancher2MultiClusterAppImport,
 		},
 
-		Schema: multiClusterAppFields(),
+		CustomizeDiff: customdiff.Sequence(
+			multiClusterAppValidateExclusiveFields,
+			multiClusterAppValidateCatalogScope,
+			multiClusterAppValidateLockVersion,
+			multiClusterAppValidateTargetRemoval,
+			multiClusterAppValidateSkipUpdateTargets,
+			multiClusterAppValidateGitOpsOwner,
+			multiClusterAppValidateMembers,
+			multiClusterAppSuppressSensitiveAnswers,
+			multiClusterAppValidateRequiredAnswers,
+			multiClusterAppWarnRename,
+			multiClusterAppForceNewOnCatalogChange,
+			multiClusterAppWarnDeprecatedTemplate,
+			multiClusterAppWarnMajorVersionResolution,
+			multiClusterAppPlanClusterTemplateTargets,
+			multiClusterAppDebugAnswers,
+			multiClusterAppReportUpgradeStrategy,
+			multiClusterAppSummarizeAnswersDiff,
+		),
+		Schema:        multiClusterAppFields(),
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    resourceRancher2MultiClusterAppResourceV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceRancher2MultiClusterAppStateUpgradeV0,
+				Version: 0,
+			},
+		},
 		Timeouts: &schema.ResourceTimeout{
-			Create: schema.DefaultTimeout(10 * time.Minute),
-			Update: schema.DefaultTimeout(10 * time.Minute),
-			Delete: schema.DefaultTimeout(10 * time.Minute),
+			Create: schema.DefaultTimeout(multiClusterAppDefaultTimeout),
+			Update: schema.DefaultTimeout(multiClusterAppDefaultTimeout),
+			Delete: schema.DefaultTimeout(multiClusterAppDefaultTimeout),
 		},
 	}
 }
 
+// resourceRancher2MultiClusterAppResourceV0 returns the multi cluster app resource before the canonical answers
+// representation, with its schema frozen so later schema changes don't break the state upgrade
+func resourceRancher2MultiClusterAppResourceV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: multiClusterAppFieldsV0(),
+	}
+}
+
+// resourceRancher2MultiClusterAppStateUpgradeV0 normalizes the answers on state to their canonical representation
+func resourceRancher2MultiClusterAppStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	if answers, ok := rawState["answers"].([]interface{}); ok && len(answers) > 0 {
+		rawState["answers"] = canonicalMultiClusterAppAnswers(answers, answers)
+	}
+
+	return rawState, nil
+}
+
 func resourceRancher2MultiClusterAppCreate(d *schema.ResourceData, meta interface{}) error {
+	return meta.(*Config).withSpan(meta.(*Config).stopContext(), "rancher2_multi_cluster_app.create", traceSpanKindInternal, func(ctx context.Context) error {
+		return resourceRancher2MultiClusterAppCreateContext(ctx, d, meta)
+	})
+}
+
+func resourceRancher2MultiClusterAppCreateContext(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
 
-	err := resourceRancher2AppGetVersion(d, meta)
+	if d.Get("read_only").(bool) {
+		return fmt.Errorf("[ERROR] Creating multi cluster app %s: read_only multi cluster apps can't be created, import them", name)
+	}
+
+	release := meta.(*Config).acquireMultiClusterAppSlot()
+	defer release()
+
+	err := resourceRancher2MultiClusterAppGetVersion(d, meta)
 	if err != nil {
 		return err
 	}
@@ -42,14 +128,48 @@ func resourceRancher2MultiClusterAppCreate(d *schema.ResourceData, meta interfac
 		return err
 	}
 
+	templateTargets, err := multiClusterAppResolveTargets(d.Get, meta)
+	if err != nil {
+		return err
+	}
+	multiClusterApp.Targets = appendClusterTemplateTargets(multiClusterApp.Targets, templateTargets)
+	d.Set("cluster_template_targets", templateTargets)
+
+	multiClusterApp.Answers, err = resolveAnswerReferences(multiClusterApp.Answers, meta.(*Config).GetConfigMapKey)
+	if err != nil {
+		return err
+	}
+	multiClusterApp.Answers, err = renderAnswersClusterProvider(multiClusterApp.Answers, multiClusterApp.Targets, multiClusterAppClusterProvider(d.Get, meta))
+	if err != nil {
+		return err
+	}
+
 	log.Printf("[INFO] Creating multi cluster app %s", name)
 
-	client, err := meta.(*Config).ManagementClient()
+	client, err := multiClusterAppClient(d.Get, meta)
+	if err != nil {
+		return err
+	}
+
+	multiClusterApp.Roles, err = multiClusterAppResolveRoles(d, client)
+	if err != nil {
+		return err
+	}
+
+	projectIDs := make([]string, 0, len(multiClusterApp.Targets))
+	for _, t := range multiClusterApp.Targets {
+		projectIDs = append(projectIDs, t.ProjectID)
+	}
+	err = multiClusterAppValidateTargetQuotas(d, client, multiClusterApp.TemplateVersionID, multiClusterApp.Answers, projectIDs)
 	if err != nil {
 		return err
 	}
 
-	newMultiClusterApp, err := client.MultiClusterApp.Create(multiClusterApp)
+	var newMultiClusterApp *managementClient.MultiClusterApp
+	err = multiClusterAppDoWithRetry(ctx, d, meta, "MultiClusterApp.Create", schema.TimeoutCreate, func() (err error) {
+		newMultiClusterApp, err = client.MultiClusterApp.Create(multiClusterApp)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -57,60 +177,223 @@ func resourceRancher2MultiClusterAppCreate(d *schema.ResourceData, meta interfac
 	d.SetId(newMultiClusterApp.ID)
 
 	if d.Get("wait").(bool) {
-		stateConf := &resource.StateChangeConf{
-			Pending:    []string{},
-			Target:     []string{"active"},
-			Refresh:    multiClusterAppStateRefreshFunc(client, newMultiClusterApp.ID),
-			Timeout:    d.Timeout(schema.TimeoutCreate),
-			Delay:      1 * time.Second,
-			MinTimeout: 3 * time.Second,
-		}
-		_, waitErr := stateConf.WaitForState()
+		waitErr := multiClusterAppWaitForActive(ctx, d, meta, client, newMultiClusterApp.ID, multiClusterAppTimeout(d, meta, schema.TimeoutCreate))
 		if waitErr != nil {
 			return fmt.Errorf("[ERROR] waiting for multi cluster app (%s) to be created: %s", newMultiClusterApp.ID, waitErr)
 		}
+		if d.Get("wait_for_targets_settled").(bool) {
+			waitErr = multiClusterAppWaitForTargetsSettled(ctx, meta, client, newMultiClusterApp.ID, multiClusterAppTimeout(d, meta, schema.TimeoutCreate))
+			if waitErr != nil {
+				return waitErr
+			}
+		}
+		if d.Get("wait_for_target_namespaces").(bool) {
+			waitErr = multiClusterAppWaitForTargetNamespaces(ctx, meta, client, newMultiClusterApp.ID, multiClusterAppTimeout(d, meta, schema.TimeoutCreate))
+			if waitErr != nil {
+				return waitErr
+			}
+		}
+		waitErr = multiClusterAppWaitForCondition(ctx, d, meta, client, newMultiClusterApp.ID, multiClusterAppTimeout(d, meta, schema.TimeoutCreate))
+		if waitErr != nil {
+			return waitErr
+		}
+	}
+
+	// Read endpoint may lag behind the submitted spec, tolerated to not report a post create diff
+	err = multiClusterAppWithReadClient(d, meta, func(readClient *managementClient.Client) error {
+		getApp := func() (*managementClient.MultiClusterApp, error) {
+			return getMultiClusterApp(ctx, readClient, newMultiClusterApp.ID)
+		}
+		return multiClusterAppWaitForConsistency(getApp, multiClusterApp, multiClusterAppConsistencyTimeout)
+	})
+	if err != nil {
+		log.Printf("[WARN] Reading multi cluster app ID %s, it may not reflect the submitted spec yet: %v", newMultiClusterApp.ID, err)
 	}
 
-	return resourceRancher2MultiClusterAppRead(d, meta)
+	return resourceRancher2MultiClusterAppReadContext(ctx, d, meta)
 }
 
 func resourceRancher2MultiClusterAppRead(d *schema.ResourceData, meta interface{}) error {
+	return resourceRancher2MultiClusterAppReadContext(meta.(*Config).stopContext(), d, meta)
+}
+
+func resourceRancher2MultiClusterAppReadContext(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+	return config.withSpan(ctx, "rancher2_multi_cluster_app.read", traceSpanKindInternal, func(ctx context.Context) error {
+		return config.retryOnServerURLChange(func() error {
+			return resourceRancher2MultiClusterAppReadOnce(ctx, d, meta)
+		})
+	})
+}
+
+func resourceRancher2MultiClusterAppReadOnce(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
 
 	log.Printf("[INFO] Refreshing multi cluster app ID %s", id)
 
-	client, err := meta.(*Config).ManagementClient()
+	client, err := multiClusterAppClient(d.Get, meta)
 	if err != nil {
 		return err
 	}
 
-	multiClusterApp, err := client.MultiClusterApp.ByID(id)
+	var multiClusterApp *managementClient.MultiClusterApp
+	err = multiClusterAppWithReadClient(d, meta, func(readClient *managementClient.Client) (err error) {
+		multiClusterApp, err = multiClusterAppGet(ctx, meta, readClient, id)
+		return err
+	})
 	if err != nil {
-		if IsNotFound(err) || IsForbidden(err) {
+		if IsNotFound(err) {
 			log.Printf("[INFO] multi cluster app ID %s not found.", id)
 			d.SetId("")
 			return nil
 		}
+		if IsForbidden(err) {
+			return fmt.Errorf("[ERROR] Getting multi cluster app ID %s is forbidden, check user permissions: %v", id, err)
+		}
+		return err
+	}
+
+	externalID, ok := multiClusterAppPinnedExternalID(d, multiClusterApp.TemplateVersionID)
+	if !ok {
+		var templateVersion *managementClient.TemplateVersion
+		err = multiClusterAppWithReadClient(d, meta, func(readClient *managementClient.Client) error {
+			return multiClusterAppDoWithRetry(ctx, d, meta, "TemplateVersion.ByID", schema.TimeoutRead, func() (err error) {
+				templateVersion, err = readClient.TemplateVersion.ByID(multiClusterApp.TemplateVersionID)
+				return err
+			})
+		})
+		if err != nil {
+			return err
+		}
+		externalID = templateVersion.ExternalID
+	}
+
+	err = flattenMultiClusterApp(d, multiClusterApp, externalID)
+	if err != nil {
+		return err
+	}
+	d.Set("gitops_owner", multiClusterAppGitOpsOwner(multiClusterApp.Labels, multiClusterApp.Annotations))
+
+	err = multiClusterAppSetSensitiveAnswers(d, meta.(*Config).AnswersEncrypter, multiClusterApp)
+	if err != nil {
+		return err
+	}
+
+	// Target apps are read just for new targets, refresh keeps the known namespaces. Import reads them on its own
+	lookup := d.IsNewResource() || d.HasChange("targets") || d.HasChange("target_from_cluster_template") || d.HasChange("target_fleet_workspace")
+	err = multiClusterAppSetTargetNamespaces(d, meta, client, multiClusterApp.Targets, lookup)
+	if err != nil {
+		return err
+	}
+
+	getTargetApp := func(target managementClient.Target) (*projectClient.App, error) {
+		return getMultiClusterAppTargetApp(meta, target)
+	}
+
+	getUser := func(userID string) (*managementClient.User, error) {
+		return client.User.ByID(userID)
+	}
+	d.Set("creator_username", multiClusterAppCreatorUsername(multiClusterApp.CreatorID, getUser))
+
+	if d.Get("read_template_metadata").(bool) {
+		templateID := multiClusterAppTemplatePrefix(d.Get) + d.Get("catalog_name").(string) + "-" + d.Get("template_name").(string)
+		template, err := client.Template.ByID(templateID)
+		if err != nil {
+			return fmt.Errorf("[ERROR] Getting multi cluster app %s template %s: %v", id, templateID, err)
+		}
+		err = d.Set("template_categories", flattenMultiClusterAppTemplateCategories(template))
+		if err != nil {
+			return err
+		}
+	}
+
+	targetRevisions := map[string]interface{}{}
+	if d.Get("read_target_revisions").(bool) {
+		targetRevisions, err = multiClusterAppTargetRevisions(multiClusterApp, getTargetApp)
+		if err != nil {
+			return err
+		}
+	}
+	err = d.Set("target_revisions", targetRevisions)
+	if err != nil {
+		return err
+	}
+
+	targetHelmRevisions := map[string]interface{}{}
+	if d.Get("read_target_helm_revisions").(bool) {
+		clusterActive := func(projectID string) bool {
+			_, active := multiClusterAppTargetClusterActive(client, projectID)
+			return active
+		}
+		getReleases := func(target managementClient.Target, namespace string) ([]projectClient.NamespacedSecret, error) {
+			return getMultiClusterAppTargetHelmReleases(meta, target, namespace)
+		}
+		targetHelmRevisions, err = multiClusterAppTargetHelmRevisions(multiClusterApp, clusterActive, getTargetApp, getReleases)
+		if err != nil {
+			return err
+		}
+	}
+	err = d.Set("target_helm_revisions", targetHelmRevisions)
+	if err != nil {
 		return err
 	}
 
-	templateVersion, err := client.TemplateVersion.ByID(multiClusterApp.TemplateVersionID)
+	if !d.Get("read_target_answers").(bool) {
+		return d.Set("target_answers_drift", []interface{}{})
+	}
+
+	targetAnswersDrift, err := multiClusterAppTargetAnswersDrift(multiClusterApp, getTargetApp)
 	if err != nil {
 		return err
 	}
 
-	return flattenMultiClusterApp(d, multiClusterApp, templateVersion.ExternalID)
+	return d.Set("target_answers_drift", targetAnswersDrift)
+}
+
+// multiClusterAppPinnedExternalID returns the template version external ID without any catalog lookup, if the
+// template version ID matches the catalog, template and version set on d
+func multiClusterAppPinnedExternalID(d *schema.ResourceData, templateVersionID string) (string, bool) {
+	catalogName := d.Get("catalog_name").(string)
+	templateName := d.Get("template_name").(string)
+	templateVersion := d.Get("template_version").(string)
+	if len(catalogName) == 0 || len(templateName) == 0 || len(templateVersion) == 0 {
+		return "", false
+	}
+	if !strings.HasPrefix(templateVersionID, MultiClusterAppTemplatePrefix) || expandMultiClusterAppTemplateVersionID(d) != templateVersionID {
+		return "", false
+	}
+
+	return AppTemplateExternalIDPrefix + "catalog=" + catalogName + "&template=" + templateName + "&version=" + templateVersion, true
 }
 
 func resourceRancher2MultiClusterAppUpdate(d *schema.ResourceData, meta interface{}) error {
+	return meta.(*Config).withSpan(meta.(*Config).stopContext(), "rancher2_multi_cluster_app.update", traceSpanKindInternal, func(ctx context.Context) error {
+		return resourceRancher2MultiClusterAppUpdateContext(ctx, d, meta)
+	})
+}
+
+func resourceRancher2MultiClusterAppUpdateContext(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
 
-	client, err := meta.(*Config).ManagementClient()
+	if d.Get("read_only").(bool) {
+		log.Printf("[WARN] Multi cluster app ID %s is read_only, changes aren't applied", id)
+		return resourceRancher2MultiClusterAppReadContext(ctx, d, meta)
+	}
+
+	release := meta.(*Config).acquireMultiClusterAppSlot()
+	defer release()
+
+	client, err := multiClusterAppClient(d.Get, meta)
+	if err != nil {
+		return err
+	}
+
+	multiClusterApp, err := multiClusterAppGet(ctx, meta, client, id)
 	if err != nil {
 		return err
 	}
 
-	multiClusterApp, err := client.MultiClusterApp.ByID(id)
+	err = multiClusterAppHandleInFlight(ctx, d, meta, client, multiClusterApp, multiClusterAppTimeout(d, meta, schema.TimeoutUpdate))
 	if err != nil {
 		return err
 	}
@@ -126,7 +409,13 @@ func resourceRancher2MultiClusterAppUpdate(d *schema.ResourceData, meta interfac
 		rollback := &managementClient.MultiClusterAppRollbackInput{
 			RevisionID: revID,
 		}
-		err = client.MultiClusterApp.ActionRollback(multiClusterApp, rollback)
+		err = multiClusterAppDoWithRetry(ctx, d, meta, "MultiClusterApp.ActionRollback", schema.TimeoutUpdate, func() error {
+			return client.MultiClusterApp.ActionRollback(multiClusterApp, rollback)
+		})
+		if err != nil {
+			return err
+		}
+		err = multiClusterAppWaitForOperation(ctx, d, meta, client, id, "rollback", multiClusterAppOperationTimeout(d, meta, "rollback_timeout"))
 		if err != nil {
 			return err
 		}
@@ -134,11 +423,20 @@ func resourceRancher2MultiClusterAppUpdate(d *schema.ResourceData, meta interfac
 		updateApp = false
 
 		removeTarget := multiClusterAppTargetToRemove(d, multiClusterApp)
-		addTarget := multiClusterAppTargetToAdd(d, multiClusterApp)
+		addTarget, err := multiClusterAppTargetToAdd(d, multiClusterApp, meta)
+		if err != nil {
+			return err
+		}
 
 		if len(removeTarget.Projects) > 0 {
 			log.Printf("[INFO] Removing targets on multi cluster app ID %s", id)
-			err = client.MultiClusterApp.ActionRemoveProjects(multiClusterApp, removeTarget)
+			err = multiClusterAppDoWithRetry(ctx, d, meta, "MultiClusterApp.ActionRemoveProjects", schema.TimeoutUpdate, func() error {
+				return client.MultiClusterApp.ActionRemoveProjects(multiClusterApp, removeTarget)
+			})
+			if err != nil {
+				return err
+			}
+			err = multiClusterAppWaitForOperation(ctx, d, meta, client, id, "targets removal", multiClusterAppOperationTimeout(d, meta, "remove_targets_timeout"))
 			if err != nil {
 				return err
 			}
@@ -150,150 +448,2628 @@ func resourceRancher2MultiClusterAppUpdate(d *schema.ResourceData, meta interfac
 
 		if len(addTarget.Projects) > 0 {
 			log.Printf("[INFO] Adding targets on multi cluster app ID %s", id)
-			err = client.MultiClusterApp.ActionAddProjects(multiClusterApp, addTarget)
+			addTarget.Answers, err = resolveAnswerReferences(addTarget.Answers, meta.(*Config).GetConfigMapKey)
+			if err != nil {
+				return err
+			}
+			addTarget.Answers, err = multiClusterAppRenderAddTargetAnswers(addTarget, multiClusterAppClusterProvider(d.Get, meta))
+			if err != nil {
+				return err
+			}
+			answers, err := expandMultiClusterAppAnswers(multiClusterAppDecryptedGet(d.Get, meta))
+			if err != nil {
+				return err
+			}
+			err = multiClusterAppValidateTargetQuotas(d, client, expandMultiClusterAppTemplateVersionID(d), answers, addTarget.Projects)
 			if err != nil {
 				return err
 			}
+			stages := multiClusterAppTargetStages(addTarget, multiClusterAppTargetPriorities(d.Get("targets").([]interface{})))
+			for i, stage := range stages {
+				if len(stages) > 1 {
+					log.Printf("[INFO] Adding targets %v on multi cluster app ID %s, stage %d/%d", stage.Projects, id, i+1, len(stages))
+				}
+				err = multiClusterAppDoWithRetry(ctx, d, meta, "MultiClusterApp.ActionAddProjects", schema.TimeoutUpdate, func() error {
+					return client.MultiClusterApp.ActionAddProjects(multiClusterApp, stage)
+				})
+				if err != nil {
+					return multiClusterAppSetAddedTargets(ctx, d, client, id, err)
+				}
+				err = multiClusterAppWaitForOperation(ctx, d, meta, client, id, "targets addition", multiClusterAppOperationTimeout(d, meta, "add_targets_timeout"))
+				if err != nil {
+					return err
+				}
+			}
+			if hasAnswerClusterProviderToken(answers) {
+				// cluster scoped answers have to be rendered for new target clusters
+				updateApp = true
+			}
+		}
+
+		if d.HasChange("group_answers") || multiClusterAppTargetGroupsChanged(d) || multiClusterAppTargetScalesChanged(d) {
+			// group answers and scale hints of existing targets are just updated on app update
+			updateApp = true
+		}
+	}
+
+	// Add or remove targets resolved from target_from_cluster_template and target_fleet_workspace
+	templateTargets, err := multiClusterAppResolveTargets(d.Get, meta)
+	if err != nil {
+		return err
+	}
+	oldTemplateTargets, _ := d.GetChange("cluster_template_targets")
+	removeProjects, addProjects := multiClusterAppClusterTemplateTargetsChange(toArrayString(oldTemplateTargets.([]interface{})), templateTargets, d.Get("targets").([]interface{}))
+	if len(removeProjects) > 0 {
+		log.Printf("[INFO] Removing cluster template targets %v on multi cluster app ID %s", removeProjects, id)
+		err = multiClusterAppDoWithRetry(ctx, d, meta, "MultiClusterApp.ActionRemoveProjects", schema.TimeoutUpdate, func() error {
+			return client.MultiClusterApp.ActionRemoveProjects(multiClusterApp, &managementClient.UpdateMultiClusterAppTargetsInput{Projects: removeProjects})
+		})
+		if err != nil {
+			return err
+		}
+		err = multiClusterAppWaitForOperation(ctx, d, meta, client, id, "targets removal", multiClusterAppOperationTimeout(d, meta, "remove_targets_timeout"))
+		if err != nil {
+			return err
+		}
+	}
+	if len(addProjects) > 0 {
+		log.Printf("[INFO] Adding cluster template targets %v on multi cluster app ID %s", addProjects, id)
+		err = multiClusterAppDoWithRetry(ctx, d, meta, "MultiClusterApp.ActionAddProjects", schema.TimeoutUpdate, func() error {
+			return client.MultiClusterApp.ActionAddProjects(multiClusterApp, &managementClient.UpdateMultiClusterAppTargetsInput{Projects: addProjects})
+		})
+		if err != nil {
+			return err
+		}
+		err = multiClusterAppWaitForOperation(ctx, d, meta, client, id, "targets addition", multiClusterAppOperationTimeout(d, meta, "add_targets_timeout"))
+		if err != nil {
+			return err
 		}
 	}
+	d.Set("cluster_template_targets", templateTargets)
 
 	// Update app if needed
 	if updateApp {
 		log.Printf("[INFO] Updating multi cluster app ID %s", id)
 
+		answers, err := expandMultiClusterAppAnswers(multiClusterAppDecryptedGet(d.Get, meta))
+		if err != nil {
+			return err
+		}
+		answers, err = resolveAnswerReferences(answers, meta.(*Config).GetConfigMapKey)
+		if err != nil {
+			return err
+		}
+		answers, err = renderAnswersClusterProvider(answers, expandTargets(d.Get("targets").([]interface{})), multiClusterAppClusterProvider(d.Get, meta))
+		if err != nil {
+			return err
+		}
+
+		roles, err := multiClusterAppResolveRoles(d, client)
+		if err != nil {
+			return err
+		}
+		annotations, err := expandMultiClusterAppAnnotations(d.Get("annotations").(map[string]interface{}), d.Get("targets").([]interface{}))
+		if err != nil {
+			return err
+		}
+
+		// answers are just updated if some scope answer changed, so unchanged target apps aren't rolled out again
+		multiClusterApp, err = multiClusterAppGet(ctx, meta, client, id)
+		if err != nil {
+			return err
+		}
+		if d.Get("answers_merge_strategy").(string) == multiClusterAppAnswersMergeStrategyMerge {
+			answers = mergeMultiClusterAppAnswers(multiClusterApp.Answers, answers)
+		}
+		if skipped := skipUpdateTargetProjectIDs(d.Get("targets").([]interface{})); len(skipped) > 0 {
+			log.Printf("[INFO] Pinning multi cluster app ID %s answers on targets skipping update: %v", id, skipped)
+			answers = pinSkipUpdateTargetAnswers(answers, multiClusterApp, skipped)
+		}
+		patch, removed := multiClusterAppAnswersToPatch(answers, multiClusterApp)
+		members := expandMembers(d.Get("members").([]interface{}))
+		addedMembers, removedMembers := membersChange(multiClusterApp.Members, members)
+		if len(addedMembers) > 0 || len(removedMembers) > 0 {
+			log.Printf("[INFO] Updating multi cluster app ID %s members, added: %v, removed: %v", id, addedMembers, removedMembers)
+		}
+
 		update := map[string]interface{}{
-			"answers":              expandAnswers(d.Get("answers").([]interface{})),
-			"members":              expandMembers(d.Get("members").([]interface{})),
+			"members":              members,
 			"revisionHistoryLimit": d.Get("revision_history_limit").(int),
-			"roles":                toArrayString(d.Get("roles").([]interface{})),
+			"roles":                roles,
 			"templateVersionId":    expandMultiClusterAppTemplateVersionID(d),
 			"upgradeStrategy":      expandUpgradeStrategy(d.Get("upgrade_strategy").([]interface{})),
-			"annotations":          toMapString(d.Get("annotations").(map[string]interface{})),
+			"annotations":          annotations,
 			"labels":               toMapString(d.Get("labels").(map[string]interface{})),
 		}
-		_, err := client.MultiClusterApp.Update(multiClusterApp, update)
+		if len(patch) > 0 || len(removed) > 0 {
+			log.Printf("[INFO] Updating multi cluster app ID %s answers, changed: %v, removed: %v", id, multiClusterAppAnswerScopes(patch), removed)
+			update["answers"] = answers
+		}
+		err = multiClusterAppDoWithRetry(ctx, d, meta, "MultiClusterApp.Update", schema.TimeoutUpdate, func() error {
+			_, err := client.MultiClusterApp.Update(multiClusterApp, update)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		err = multiClusterAppWaitForOperation(ctx, d, meta, client, id, "update", multiClusterAppTimeout(d, meta, schema.TimeoutUpdate))
+		if err != nil {
+			return err
+		}
+		if len(removedMembers) > 0 {
+			updated, err := multiClusterAppGet(ctx, meta, client, id)
+			if err != nil {
+				return err
+			}
+			notRemoved := []string{}
+			for _, m := range updated.Members {
+				if key := memberPrincipalKey(m); containsString(removedMembers, key) {
+					notRemoved = append(notRemoved, key)
+				}
+			}
+			if len(notRemoved) > 0 {
+				log.Printf("[WARN] multi cluster app ID %s members %v were not removed by Rancher", id, notRemoved)
+			}
+		}
+	}
+
+	if d.Get("wait").(bool) && d.Get("wait_for_targets_settled").(bool) {
+		err = multiClusterAppWaitForTargetsSettled(ctx, meta, client, id, multiClusterAppTimeout(d, meta, schema.TimeoutUpdate))
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.Get("wait").(bool) && d.Get("wait_for_target_namespaces").(bool) {
+		err = multiClusterAppWaitForTargetNamespaces(ctx, meta, client, id, multiClusterAppTimeout(d, meta, schema.TimeoutUpdate))
 		if err != nil {
 			return err
 		}
 	}
 
 	if d.Get("wait").(bool) {
-		stateConf := &resource.StateChangeConf{
-			Pending:    []string{},
-			Target:     []string{"active"},
-			Refresh:    multiClusterAppStateRefreshFunc(client, id),
-			Timeout:    d.Timeout(schema.TimeoutCreate),
-			Delay:      1 * time.Second,
-			MinTimeout: 3 * time.Second,
-		}
-		_, waitErr := stateConf.WaitForState()
-		if waitErr != nil {
-			return fmt.Errorf("[ERROR] waiting for multi cluster app (%s) to be created: %s", id, waitErr)
+		err = multiClusterAppWaitForCondition(ctx, d, meta, client, id, multiClusterAppTimeout(d, meta, schema.TimeoutUpdate))
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.Get("wait_for_roles_removal").(bool) && d.HasChange("roles") {
+		removedRoles := multiClusterAppRemovedRoles(d)
+		if len(removedRoles) > 0 && len(multiClusterApp.Targets) > 0 {
+			log.Printf("[INFO] Waiting for roles %v removal on multi cluster app ID %s targets", removedRoles, id)
+			listBindings := func(projectID string) ([]managementClient.ProjectRoleTemplateBinding, error) {
+				return listProjectRoleTemplateBindingsByProjectID(client, projectID)
+			}
+			refresh := multiClusterAppRolesRemovalRefreshFunc(listBindings, multiClusterApp, removedRoles)
+			stateConf := newStateChangeConf(ctx, meta, []string{"removing"}, []string{"removed"}, refresh, multiClusterAppTimeout(d, meta, schema.TimeoutUpdate))
+			_, waitErr := waitForStateWithBudget(meta, stateConf)
+			if waitErr != nil {
+				return fmt.Errorf("[ERROR] waiting for multi cluster app (%s) roles %v to be removed: %s", id, removedRoles, waitErr)
+			}
 		}
 	}
 
-	return resourceRancher2MultiClusterAppRead(d, meta)
+	return resourceRancher2MultiClusterAppReadContext(ctx, d, meta)
 }
 
 func resourceRancher2MultiClusterAppDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+	return config.withSpan(config.stopContext(), "rancher2_multi_cluster_app.delete", traceSpanKindInternal, func(ctx context.Context) error {
+		return config.retryOnServerURLChange(func() error {
+			return resourceRancher2MultiClusterAppDeleteOnce(ctx, d, meta)
+		})
+	})
+}
+
+func resourceRancher2MultiClusterAppDeleteOnce(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
 
+	if d.Get("read_only").(bool) {
+		log.Printf("[WARN] Multi cluster app ID %s is read_only, just removing it from state", id)
+		d.SetId("")
+		return nil
+	}
+
 	log.Printf("[INFO] Deleting multi cluster app ID %s", id)
 
-	client, err := meta.(*Config).ManagementClient()
+	client, err := multiClusterAppClient(d.Get, meta)
 	if err != nil {
 		return err
 	}
 
-	multiClusterApp, err := client.MultiClusterApp.ByID(id)
+	multiClusterApp, err := multiClusterAppGet(ctx, meta, client, id)
 	if err != nil {
-		if IsNotFound(err) || IsForbidden(err) {
+		if IsNotFound(err) {
 			log.Printf("[INFO] multi cluster app ID %s not found.", d.Id())
 			d.SetId("")
 			return nil
 		}
+		if IsForbidden(err) {
+			return fmt.Errorf("[ERROR] Getting multi cluster app ID %s is forbidden, check user permissions: %v", id, err)
+		}
 		return err
 	}
 
-	err = client.MultiClusterApp.Delete(multiClusterApp)
+	if path := d.Get("delete_snapshot_path").(string); len(path) > 0 {
+		log.Printf("[INFO] Writing multi cluster app ID %s spec snapshot to %s", id, path)
+		err = multiClusterAppWriteSnapshot(path, multiClusterApp)
+		if err != nil {
+			return err
+		}
+	}
+
+	keepTargetApps := d.Get("keep_target_apps").(bool)
+	if keepTargetApps {
+		log.Printf("[INFO] Detaching target apps from multi cluster app ID %s", id)
+		err = multiClusterAppDetachTargetApps(meta, multiClusterApp)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = multiClusterAppDoWithRetry(ctx, d, meta, "MultiClusterApp.Delete", schema.TimeoutDelete, func() error {
+		return client.MultiClusterApp.Delete(multiClusterApp)
+	})
 	if err != nil {
 		return fmt.Errorf("[ERROR] removing multi cluster app: %s", err)
 	}
 
-	stateConf := &resource.StateChangeConf{
-		Pending:    []string{"removing"},
-		Target:     []string{"removed"},
-		Refresh:    multiClusterAppStateRefreshFunc(client, id),
-		Timeout:    d.Timeout(schema.TimeoutDelete),
-		Delay:      1 * time.Second,
-		MinTimeout: 3 * time.Second,
+	if !d.Get("wait_for_delete").(bool) {
+		log.Printf("[INFO] Not waiting for multi cluster app ID %s removal", id)
+		d.SetId("")
+		return nil
 	}
 
-	_, waitErr := stateConf.WaitForState()
+	stateConf := newStateChangeConf(ctx, meta, []string{"removing"}, []string{"removed"}, multiClusterAppStateRefreshFunc(client, id), multiClusterAppTimeout(d, meta, schema.TimeoutDelete))
+
+	_, waitErr := waitForStateWithBudget(meta, stateConf)
 	if waitErr != nil {
 		return fmt.Errorf(
 			"[ERROR] waiting for multi cluster app (%s) to be removed: %s", id, waitErr)
 	}
 	d.SetId("")
 
-	for i := range multiClusterApp.Targets {
-		client, err := meta.(*Config).ProjectClient(multiClusterApp.Targets[i].ProjectID)
+	if keepTargetApps {
+		log.Printf("[INFO] Keeping multi cluster app ID %s target apps, they are no longer managed", id)
+		return nil
+	}
+
+	stuck, err := multiClusterAppWaitForTargetAppsRemoval(ctx, multiClusterApp.Targets, func(target managementClient.Target) error {
+		projectID := target.ProjectID
+		if state, active := multiClusterAppTargetClusterActive(client, projectID); !active {
+			log.Printf("[WARN] Skipping target app removal wait on project %s, cluster is %s", projectID, state)
+			return nil
+		}
+		pClient, err := meta.(*Config).ProjectClient(projectID)
 		if err != nil {
-			continue
+			log.Printf("[WARN] Skipping target app removal wait on project %s: %v", projectID, err)
+			return nil
 		}
-		mappID := splitProjectIDPart(multiClusterApp.Targets[i].ProjectID) + ":" + multiClusterApp.Targets[i].AppID
-		stateConf = &resource.StateChangeConf{
-			Pending:    []string{"removing"},
-			Target:     []string{"removed"},
-			Refresh:    appStateRefreshFunc(client, mappID),
-			Timeout:    d.Timeout(schema.TimeoutDelete),
-			Delay:      1 * time.Second,
-			MinTimeout: 3 * time.Second,
+		stateConf := newStateChangeConf(ctx, meta, []string{"removing"}, []string{"removed"}, appStateRefreshFunc(pClient, multiClusterAppTargetAppID(target)), multiClusterAppTimeout(d, meta, schema.TimeoutDelete))
+		_, waitErr := waitForStateWithBudget(meta, stateConf)
+		return waitErr
+	})
+	if err != nil {
+		return fmt.Errorf("[ERROR] waiting for multi cluster app (%s) target apps to be removed: %v", id, err)
+	}
+	if len(stuck) > 0 {
+		log.Printf("[WARN] Multi cluster app ID %s target apps not removed yet on projects %s. They may have to be removed manually", id, strings.Join(stuck, ", "))
+	}
+
+	if d.Get("wait_for_namespaces_removal").(bool) {
+		err = multiClusterAppWaitForNamespacesRemoval(ctx, meta, multiClusterApp.Targets, d.Get("target_namespaces").(map[string]interface{}), multiClusterAppTimeout(d, meta, schema.TimeoutDelete))
+		if err != nil {
+			return err
 		}
-		stateConf.WaitForState()
 	}
-	time.Sleep(5 * time.Second)
 
 	return nil
 }
 
-func resourceRancher2MultiClusterAppGetVersion(d *schema.ResourceData, meta interface{}) error {
-	catalogName := d.Get("catalog_name").(string)
-	appName := d.Get("template_name").(string)
+// multiClusterAppWaitForTargetAppsRemoval calls wait for every target, logging wait errors. Returns the project IDs of
+// targets whose wait failed, or error without waiting for the remaining targets if ctx is done
+func multiClusterAppWaitForTargetAppsRemoval(ctx context.Context, targets []managementClient.Target, wait func(managementClient.Target) error) ([]string, error) {
+	stuck := []string{}
+	for _, target := range targets {
+		select {
+		case <-ctx.Done():
+			return stuck, ctx.Err()
+		default:
+		}
+		if err := wait(target); err != nil {
+			log.Printf("[WARN] Waiting for target app %s removal on project %s: %v", multiClusterAppTargetAppID(target), target.ProjectID, err)
+			stuck = append(stuck, target.ProjectID)
+		}
+	}
+
+	return stuck, nil
+}
+
+// multiClusterAppTargetClusterActive returns the target project cluster state and if it's active. Target apps on
+// clusters not active or not found can't be removed, so they aren't waited for
+func multiClusterAppTargetClusterActive(client *managementClient.Client, projectID string) (string, bool) {
+	clusterID, err := clusterIDFromProjectID(projectID)
+	if err != nil {
+		return "unknown", false
+	}
+	cluster, err := client.Cluster.ByID(clusterID)
+	if err != nil {
+		if IsNotFound(err) || IsForbidden(err) {
+			return "removed", false
+		}
+		return "unreachable", false
+	}
+
+	return cluster.State, cluster.State == "active"
+}
+
+func resourceRancher2MultiClusterAppGetVersion(d *schema.ResourceData, meta interface{}) error {
+	catalogName := d.Get("catalog_name").(string)
+	appName := d.Get("template_name").(string)
 	appVersion := d.Get("template_version").(string)
+	order := meta.(*Config).CatalogResolutionOrder
+	// Namespaced catalog_name, like c-xxxxx:mycatalog, is used as is
+	scoped := d.Get("catalog_scope").(string) != catalogScopeGlobal || strings.Contains(catalogName, ":")
+
+	if len(appVersion) > 0 && (scoped || !multiClusterAppCatalogResolutionAmbiguous(order)) {
+		return nil
+	}
+	if len(appVersion) == 0 && d.Get("lock_version").(bool) {
+		return fmt.Errorf("[ERROR] multi cluster app %s lock_version is true, template_version must be set", d.Get("name").(string))
+	}
+
+	var templateIDs []string
+	if scoped {
+		// Template scoped by catalog_scope, not following the provider catalog_resolution_order
+		templateIDs = []string{multiClusterAppTemplatePrefix(d.Get) + catalogName + "-" + appName}
+	} else {
+		projectIDs := []string{}
+		for _, t := range expandTargets(d.Get("targets").([]interface{})) {
+			projectIDs = append(projectIDs, t.ProjectID)
+		}
+		templateIDs = multiClusterAppCatalogTemplateIDs(catalogName, appName, projectIDs, order)
+	}
+
+	appID := strings.Join(templateIDs, ",")
+
+	client, err := multiClusterAppClient(d.Get, meta)
+	if err != nil {
+		return err
+	}
+
+	getTemplate := func() (*managementClient.Template, error) {
+		return multiClusterAppResolveTemplate(templateIDs, client.Template.ByID)
+	}
+
+	var template *managementClient.Template
+	if d.Get("wait_for_catalog").(bool) {
+		template, err = multiClusterAppWaitForTemplate(meta.(*Config), appID, multiClusterAppCatalogWaitTimeout(d, meta), getTemplate)
+	} else {
+		template, err = getTemplate()
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(appVersion) == 0 {
+		appVersion, err = getLatestVersion(template.VersionLinks)
+		if err != nil {
+			return err
+		}
+		log.Printf("[INFO] Resolved multi cluster app %s template version %s, latest on template %s", d.Get("name").(string), appVersion, template.ID)
+		d.Set("template_version", appVersion)
+	} else if _, ok := template.VersionLinks[appVersion]; !ok && len(template.VersionLinks) > 0 {
+		return fmt.Errorf("[ERROR] multi cluster app template version %s not found on template %s, available versions: %s", appVersion, template.ID, strings.Join(multiClusterAppTemplateVersions(template), ", "))
+	}
+	if len(template.ID) > 0 {
+		d.Set("template_version_id", template.ID+"-"+appVersion)
+	}
+
+	return nil
+}
+
+// multiClusterAppCatalogResolutionAmbiguous returns true if catalogs other than the global one are tried by order
+func multiClusterAppCatalogResolutionAmbiguous(order []string) bool {
+	for _, scope := range order {
+		if scope != catalogResolutionScopeGlobal {
+			return true
+		}
+	}
+
+	return false
+}
+
+// multiClusterAppCatalogTemplateIDs returns the template IDs of catalogName and templateName to try, following the order
+// scopes. Cluster and project catalogs are tried on the cluster and project of every target. Just global if order is empty.
+// Just catalogName if it's already namespaced, like c-xxxxx:mycatalog
+func multiClusterAppCatalogTemplateIDs(catalogName, templateName string, projectIDs, order []string) []string {
+	if strings.Contains(catalogName, ":") {
+		return []string{catalogName + "-" + templateName}
+	}
+	if len(order) == 0 {
+		order = []string{catalogResolutionScopeGlobal}
+	}
+
+	out := []string{}
+	seen := map[string]bool{}
+	add := func(namespace string) {
+		id := namespace + ":" + catalogName + "-" + templateName
+		if len(namespace) == 0 || seen[id] {
+			return
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	for _, scope := range order {
+		switch scope {
+		case catalogResolutionScopeGlobal:
+			add(strings.TrimSuffix(MultiClusterAppTemplatePrefix, ":"))
+		case catalogResolutionScopeCluster:
+			for _, projectID := range projectIDs {
+				clusterID, _ := splitProjectID(projectID)
+				add(clusterID)
+			}
+		case catalogResolutionScopeProject:
+			for _, projectID := range projectIDs {
+				add(splitProjectIDPart(projectID))
+			}
+		}
+	}
+
+	return out
+}
+
+// multiClusterAppResolveTemplate returns the first template found on templateIDs
+func multiClusterAppResolveTemplate(templateIDs []string, getTemplate func(string) (*managementClient.Template, error)) (*managementClient.Template, error) {
+	var err error
+	for _, id := range templateIDs {
+		var template *managementClient.Template
+		template, err = getTemplate(id)
+		if err == nil {
+			log.Printf("[INFO] Using multi cluster app template %s", id)
+			return template, nil
+		}
+		if !IsNotFound(err) {
+			return nil, err
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("[ERROR] No catalog to get multi cluster app template from, check the provider catalog_resolution_order")
+	}
+
+	return nil, err
+}
+
+func multiClusterAppCatalogWaitTimeout(d *schema.ResourceData, meta interface{}) time.Duration {
+	if v, ok := d.Get("catalog_wait_timeout").(string); ok && len(v) > 0 {
+		if timeout, err := time.ParseDuration(v); err == nil {
+			return timeout
+		}
+	}
+
+	return multiClusterAppTimeout(d, meta, schema.TimeoutCreate) / multiClusterAppCatalogWaitFraction
+}
+
+// multiClusterAppWaitForTemplate retries getTemplate until the catalog template is available or timeout is reached
+func multiClusterAppWaitForTemplate(c *Config, appID string, timeout time.Duration, getTemplate func() (*managementClient.Template, error)) (*managementClient.Template, error) {
+	ctx, cancel := context.WithTimeout(c.stopContext(), timeout)
+	defer cancel()
+	for {
+		template, err := getTemplate()
+		if err == nil {
+			return template, nil
+		}
+		if !IsNotFound(err) && !IsServerError(err) {
+			return nil, err
+		}
+		if budgetErr := c.consumeRetryBudget(rancher2RetriesWait * time.Second); budgetErr != nil {
+			return nil, budgetErr
+		}
+		select {
+		case <-time.After(rancher2RetriesWait * time.Second):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("[ERROR] Timeout waiting %s for catalog template %s: %v", timeout, appID, err)
+		}
+	}
+}
+
+// multiClusterAppValidateExclusiveFields returns error if mutually exclusive arguments are set or changed together
+func multiClusterAppValidateExclusiveFields(d *schema.ResourceDiff, meta interface{}) error {
+	isSet := func(key string) bool {
+		_, ok := d.GetOk(key)
+		return ok
+	}
+	hasChange := func(key string) bool {
+		return len(d.Id()) > 0 && d.HasChange(key)
+	}
+
+	return multiClusterAppExclusiveConflicts(isSet, hasChange)
+}
+
+// multiClusterAppValidateCatalogScope returns error if the catalog_scope catalog cluster or project ID isn't set
+func multiClusterAppValidateCatalogScope(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("catalog_scope") || !d.NewValueKnown("catalog_cluster_id") || !d.NewValueKnown("catalog_project_id") {
+		return nil
+	}
+	scope := d.Get("catalog_scope").(string)
+	clusterID := d.Get("catalog_cluster_id").(string)
+	projectID := d.Get("catalog_project_id").(string)
+
+	switch {
+	case scope == catalogScopeCluster && len(clusterID) == 0:
+		return fmt.Errorf("[ERROR] multi cluster app catalog_cluster_id is required for cluster catalog_scope")
+	case scope == catalogScopeProject && len(splitProjectIDPart(projectID)) == 0:
+		return fmt.Errorf("[ERROR] multi cluster app catalog_project_id is required for project catalog_scope, in <cluster_id>:<project_id> format")
+	case scope != catalogScopeCluster && len(clusterID) > 0:
+		return fmt.Errorf("[ERROR] multi cluster app catalog_cluster_id is just allowed for cluster catalog_scope")
+	case scope != catalogScopeProject && len(projectID) > 0:
+		return fmt.Errorf("[ERROR] multi cluster app catalog_project_id is just allowed for project catalog_scope")
+	}
+
+	return nil
+}
+
+// multiClusterAppExclusiveConflicts returns error naming the conflicting fields if any exclusive fields are set or changed together
+func multiClusterAppExclusiveConflicts(isSet, hasChange func(string) bool) error {
+	for _, fields := range multiClusterAppExclusiveFields {
+		set := []string{}
+		for _, field := range fields {
+			if isSet(field) {
+				set = append(set, field)
+			}
+		}
+		if len(set) > 1 {
+			return fmt.Errorf("[ERROR] multi cluster app fields %s are mutually exclusive, just one can be set", strings.Join(set, ", "))
+		}
+	}
+
+	keys := make([]string, 0, len(multiClusterAppExclusiveChanges))
+	for key := range multiClusterAppExclusiveChanges {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !hasChange(key) {
+			continue
+		}
+		changed := []string{}
+		for _, field := range multiClusterAppExclusiveChanges[key] {
+			if hasChange(field) {
+				changed = append(changed, field)
+			}
+		}
+		if len(changed) > 0 {
+			return fmt.Errorf("[ERROR] multi cluster app %s can't be changed together with %s", key, strings.Join(changed, ", "))
+		}
+	}
+
+	return nil
+}
+
+// multiClusterAppDecryptedGet returns get decrypting the sensitive answers stored on state
+func multiClusterAppDecryptedGet(get func(string) interface{}, meta interface{}) func(string) interface{} {
+	var encrypter answersEncrypter
+	if config, ok := meta.(*Config); ok && config != nil {
+		encrypter = config.AnswersEncrypter
+	}
+
+	return func(key string) interface{} {
+		v := get(key)
+		if key != "sensitive_answers" {
+			return v
+		}
+		sensitive, _ := v.(map[string]interface{})
+		return decryptSensitiveAnswers(encrypter, sensitive)
+	}
+}
+
+// multiClusterAppSuppressSensitiveAnswers clears the sensitive_answers diff if the encrypted state values match config
+func multiClusterAppSuppressSensitiveAnswers(d *schema.ResourceDiff, meta interface{}) error {
+	if len(d.Id()) == 0 || !d.HasChange("sensitive_answers") || !d.NewValueKnown("sensitive_answers") {
+		return nil
+	}
+	o, n := d.GetChange("sensitive_answers")
+	oldValues := multiClusterAppDecryptedGet(func(string) interface{} { return o }, meta)("sensitive_answers").(map[string]interface{})
+	newValues, _ := n.(map[string]interface{})
+	if len(oldValues) != len(newValues) {
+		return nil
+	}
+	for k, v := range newValues {
+		if oldValues[k] != v {
+			return nil
+		}
+	}
+
+	return d.Clear("sensitive_answers")
+}
+
+// multiClusterAppSetSensitiveAnswers sets on state the live sensitive answers, encrypted if encrypter isn't nil, replacing
+// their values on effective_answers too
+func multiClusterAppSetSensitiveAnswers(d *schema.ResourceData, encrypter answersEncrypter, in *managementClient.MultiClusterApp) error {
+	old, _ := d.Get("sensitive_answers").(map[string]interface{})
+	if len(old) == 0 {
+		return nil
+	}
+
+	live := effectiveAnswerValues(in.Answers, "")
+	sensitive := make(map[string]interface{}, len(old))
+	for k, v := range old {
+		value, ok := live[k]
+		if !ok {
+			continue
+		}
+		stateValue, err := sensitiveAnswerStateValue(encrypter, v.(string), value)
+		if err != nil {
+			return fmt.Errorf("[ERROR] Encrypting multi cluster app %s sensitive answer %s: %v", in.ID, k, err)
+		}
+		sensitive[k] = stateValue
+	}
+	err := d.Set("sensitive_answers", sensitive)
+	if err != nil {
+		return err
+	}
+
+	effective, _ := d.Get("effective_answers").([]interface{})
+	for _, e := range effective {
+		values, _ := e.(map[string]interface{})["values"].(map[string]interface{})
+		for k, v := range sensitive {
+			// Values overridden on a narrower scope aren't sensitive answers
+			if values[k] == live[k] {
+				values[k] = v
+			}
+		}
+	}
+
+	return d.Set("effective_answers", effective)
+}
+
+// multiClusterAppWarnRename logs that renaming replaces the multi cluster app. Rancher uses the name as the object
+// name, which is immutable, so the multi cluster app can't be renamed in place
+func multiClusterAppWarnRename(d *schema.ResourceDiff, meta interface{}) error {
+	if len(d.Id()) == 0 || !d.HasChange("name") {
+		return nil
+	}
+	oldName, newName := d.GetChange("name")
+	log.Printf("[WARN] Renaming multi cluster app %s to %s is not supported by Rancher, it will be replaced and all target apps reinstalled", oldName, newName)
+
+	return nil
+}
+
+// multiClusterAppPlanClusterTemplateTargets resolves target_from_cluster_template and target_fleet_workspace on plan,
+// so clusters provisioned from the cluster template or added to the fleet workspace since last apply are planned as new targets
+func multiClusterAppPlanClusterTemplateTargets(d *schema.ResourceDiff, meta interface{}) error {
+	if meta == nil || !d.NewValueKnown("target_from_cluster_template") || !d.NewValueKnown("target_fleet_workspace") {
+		return nil
+	}
+	oldTemplateTargets := toArrayString(d.Get("cluster_template_targets").([]interface{}))
+	block, _ := d.Get("target_from_cluster_template").([]interface{})
+	workspaceBlock, _ := d.Get("target_fleet_workspace").([]interface{})
+	if len(block) == 0 && len(workspaceBlock) == 0 && len(oldTemplateTargets) == 0 {
+		return nil
+	}
+
+	templateTargets, err := multiClusterAppResolveTargets(d.Get, meta)
+	if err != nil {
+		log.Printf("[WARN] Skipping multi cluster app cluster template and fleet workspace targets resolution on plan: %v", err)
+		return nil
+	}
+	if strings.Join(oldTemplateTargets, ",") == strings.Join(templateTargets, ",") {
+		return nil
+	}
+
+	return d.SetNew("cluster_template_targets", templateTargets)
+}
+
+// multiClusterAppResolveTargets returns the sorted project IDs targeted by target_from_cluster_template and
+// target_fleet_workspace
+func multiClusterAppResolveTargets(get func(string) interface{}, meta interface{}) ([]string, error) {
+	templateTargets, err := multiClusterAppResolveClusterTemplateTargets(get, meta)
+	if err != nil {
+		return nil, err
+	}
+	workspaceTargets, err := multiClusterAppResolveFleetWorkspaceTargets(get, meta)
+	if err != nil {
+		return nil, err
+	}
+	for _, projectID := range workspaceTargets {
+		if !containsString(templateTargets, projectID) {
+			templateTargets = append(templateTargets, projectID)
+		}
+	}
+	sort.Strings(templateTargets)
+
+	return templateTargets, nil
+}
+
+// multiClusterAppResolveFleetWorkspaceTargets returns the sorted project IDs targeted by target_fleet_workspace
+func multiClusterAppResolveFleetWorkspaceTargets(get func(string) interface{}, meta interface{}) ([]string, error) {
+	block, _ := get("target_fleet_workspace").([]interface{})
+	if len(block) == 0 || block[0] == nil {
+		return []string{}, nil
+	}
+
+	client, err := multiClusterAppClient(get, meta)
+	if err != nil {
+		return nil, err
+	}
+	getWorkspace := func(name string) error {
+		_, err := client.FleetWorkspace.ByID(name)
+		return err
+	}
+	listClusters := func(workspace string) ([]managementClient.Cluster, error) {
+		clusters, err := client.Cluster.List(NewListOpts(map[string]interface{}{"fleetWorkspaceName": workspace}))
+		if err != nil {
+			return nil, err
+		}
+		return clusters.Data, nil
+	}
+	listProjects := func(name string) ([]managementClient.Project, error) {
+		projects, err := client.Project.List(NewListOpts(map[string]interface{}{"name": name}))
+		if err != nil {
+			return nil, err
+		}
+		return projects.Data, nil
+	}
+
+	return multiClusterAppFleetWorkspaceTargets(block[0].(map[string]interface{}), getWorkspace, listClusters, listProjects)
+}
+
+// multiClusterAppResolveClusterTemplateTargets returns the sorted project IDs targeted by target_from_cluster_template
+func multiClusterAppResolveClusterTemplateTargets(get func(string) interface{}, meta interface{}) ([]string, error) {
+	block, _ := get("target_from_cluster_template").([]interface{})
+	if len(block) == 0 || block[0] == nil {
+		return []string{}, nil
+	}
+
+	client, err := multiClusterAppClient(get, meta)
+	if err != nil {
+		return nil, err
+	}
+	listClusters := func(clusterTemplateID string) ([]managementClient.Cluster, error) {
+		clusters, err := client.Cluster.List(NewListOpts(map[string]interface{}{"clusterTemplateId": clusterTemplateID}))
+		if err != nil {
+			return nil, err
+		}
+		return clusters.Data, nil
+	}
+	listProjects := func(name string) ([]managementClient.Project, error) {
+		projects, err := client.Project.List(NewListOpts(map[string]interface{}{"name": name}))
+		if err != nil {
+			return nil, err
+		}
+		return projects.Data, nil
+	}
+
+	return multiClusterAppClusterTemplateTargets(block[0].(map[string]interface{}), listClusters, listProjects)
+}
+
+// multiClusterAppClusterTemplateTargets returns the sorted IDs of the project_name projects of the clusters provisioned
+// from cluster_template_id. Clusters without such project are skipped
+func multiClusterAppClusterTemplateTargets(in map[string]interface{}, listClusters func(string) ([]managementClient.Cluster, error), listProjects func(string) ([]managementClient.Project, error)) ([]string, error) {
+	clusterTemplateID := in["cluster_template_id"].(string)
+	projectName := in["project_name"].(string)
+
+	clusters, err := listClusters(clusterTemplateID)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] listing clusters provisioned from cluster template %s: %v", clusterTemplateID, err)
+	}
+	clusterIDs := map[string]bool{}
+	for _, cluster := range clusters {
+		if cluster.ClusterTemplateID == clusterTemplateID {
+			clusterIDs[cluster.ID] = false
+		}
+	}
+	if len(clusterIDs) == 0 {
+		return []string{}, nil
+	}
+
+	return multiClusterAppClusterProjectTargets(clusterIDs, projectName, "provisioned from cluster template "+clusterTemplateID, listProjects)
+}
+
+// multiClusterAppFleetWorkspaceTargets returns the sorted IDs of the project_name projects of the clusters on the
+// fleet workspace name. Clusters without such project are skipped. The fleet workspace has to exist
+func multiClusterAppFleetWorkspaceTargets(in map[string]interface{}, getWorkspace func(string) error, listClusters func(string) ([]managementClient.Cluster, error), listProjects func(string) ([]managementClient.Project, error)) ([]string, error) {
+	workspace := in["name"].(string)
+	projectName := in["project_name"].(string)
+
+	err := getWorkspace(workspace)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, fmt.Errorf("[ERROR] fleet workspace %s not found", workspace)
+		}
+		return nil, fmt.Errorf("[ERROR] getting fleet workspace %s: %v", workspace, err)
+	}
+	clusters, err := listClusters(workspace)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] listing clusters on fleet workspace %s: %v", workspace, err)
+	}
+	clusterIDs := map[string]bool{}
+	for _, cluster := range clusters {
+		if cluster.FleetWorkspaceName == workspace {
+			clusterIDs[cluster.ID] = false
+		}
+	}
+	if len(clusterIDs) == 0 {
+		return []string{}, nil
+	}
+
+	return multiClusterAppClusterProjectTargets(clusterIDs, projectName, "on fleet workspace "+workspace, listProjects)
+}
+
+// multiClusterAppClusterProjectTargets returns the sorted IDs of the project_name projects of the clusterIDs clusters,
+// logging a warning for every cluster without such project
+func multiClusterAppClusterProjectTargets(clusterIDs map[string]bool, projectName, source string, listProjects func(string) ([]managementClient.Project, error)) ([]string, error) {
+	projects, err := listProjects(projectName)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] listing %s projects: %v", projectName, err)
+	}
+	out := []string{}
+	for _, project := range projects {
+		if found, ok := clusterIDs[project.ClusterID]; ok && !found && project.Name == projectName {
+			clusterIDs[project.ClusterID] = true
+			out = append(out, project.ID)
+		}
+	}
+	for clusterID, found := range clusterIDs {
+		if !found {
+			log.Printf("[WARN] Cluster %s %s has no %s project, it isn't targeted", clusterID, source, projectName)
+		}
+	}
+	sort.Strings(out)
+
+	return out, nil
+}
+
+// multiClusterAppClusterTemplateTargetsChange returns the cluster template targets to remove and to add, skipping the
+// ones also set on targets
+func multiClusterAppClusterTemplateTargetsChange(old, new []string, targets []interface{}) ([]string, []string) {
+	configured := map[string]bool{}
+	for _, t := range targets {
+		if target, ok := t.(map[string]interface{}); ok {
+			configured[target["project_id"].(string)] = true
+		}
+	}
+	diff := func(a, b []string) []string {
+		out := []string{}
+		for _, projectID := range a {
+			found := configured[projectID]
+			for _, other := range b {
+				if projectID == other {
+					found = true
+					break
+				}
+			}
+			if !found {
+				out = append(out, projectID)
+			}
+		}
+		return out
+	}
+
+	return diff(old, new), diff(new, old)
+}
+
+// multiClusterAppAnswerScope returns the answer scope: project/<project_id>, cluster/<cluster_id> or global
+func multiClusterAppAnswerScope(answer managementClient.Answer) string {
+	if len(answer.ProjectID) > 0 {
+		return "project/" + answer.ProjectID
+	}
+	if len(answer.ClusterID) > 0 {
+		return "cluster/" + answer.ClusterID
+	}
+
+	return "global"
+}
+
+func multiClusterAppAnswerScopes(answers []managementClient.Answer) []string {
+	out := make([]string, 0, len(answers))
+	for _, a := range answers {
+		out = append(out, multiClusterAppAnswerScope(a))
+	}
+
+	return out
+}
+
+// multiClusterAppAnswersToPatch returns the answers whose values differ from the mca answer of the same scope, and the
+// scopes answered on mca but not on answers. Both are empty if the mca answers don't have to be updated
+func multiClusterAppAnswersToPatch(answers []managementClient.Answer, mca *managementClient.MultiClusterApp) ([]managementClient.Answer, []string) {
+	live := map[string]map[string]string{}
+	for _, a := range mca.Answers {
+		if len(a.Values) > 0 {
+			live[multiClusterAppAnswerScope(a)] = a.Values
+		}
+	}
+
+	patch := []managementClient.Answer{}
+	desired := map[string]bool{}
+	for _, a := range answers {
+		if len(a.Values) == 0 {
+			continue
+		}
+		scope := multiClusterAppAnswerScope(a)
+		desired[scope] = true
+		liveValues, ok := live[scope]
+		if !ok || len(liveValues) != len(a.Values) {
+			patch = append(patch, a)
+			continue
+		}
+		for k, v := range a.Values {
+			if lv, ok := liveValues[k]; !ok || lv != v {
+				patch = append(patch, a)
+				break
+			}
+		}
+	}
+
+	removed := []string{}
+	for scope := range live {
+		if !desired[scope] {
+			removed = append(removed, scope)
+		}
+	}
+	sort.Strings(removed)
+
+	return patch, removed
+}
+
+// multiClusterAppValidateTargetQuotas checks the resource quota of every projectIDs project has headroom for the
+// template version resource requests, if validate_target_quota is set. Shortages are logged on warn mode
+func multiClusterAppValidateTargetQuotas(d *schema.ResourceData, client *managementClient.Client, templateVersionID string, answers []managementClient.Answer, projectIDs []string) error {
+	mode := d.Get("validate_target_quota").(string)
+	if len(mode) == 0 || len(projectIDs) == 0 {
+		return nil
+	}
+
+	templateVersion, err := client.TemplateVersion.ByID(templateVersionID)
+	if err != nil {
+		return fmt.Errorf("[ERROR] Getting template version %s to validate target quotas: %v", templateVersionID, err)
+	}
+	getProject := func(projectID string) (*managementClient.Project, error) {
+		return client.Project.ByID(projectID)
+	}
+	shortages, err := multiClusterAppTargetQuotaShortages(templateVersion.Questions, answers, projectIDs, getProject)
+	if err != nil {
+		return err
+	}
+	if len(shortages) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("multi cluster app %s targets resource quotas don't have headroom: %s", d.Get("name").(string), strings.Join(shortages, "; "))
+	if mode == "error" {
+		return fmt.Errorf("[ERROR] %s", msg)
+	}
+	log.Printf("[WARN] %s", msg)
+
+	return nil
+}
+
+// multiClusterAppTargetQuotaShortages returns the resource requests of every target exceeding its project quota headroom
+func multiClusterAppTargetQuotaShortages(questions []managementClient.Question, answers []managementClient.Answer, projectIDs []string, getProject func(string) (*managementClient.Project, error)) ([]string, error) {
+	out := []string{}
+	for _, projectID := range projectIDs {
+		requests, err := multiClusterAppTargetRequests(questions, effectiveAnswerValues(answers, projectID))
+		if err != nil {
+			return nil, err
+		}
+		if len(requests) == 0 {
+			continue
+		}
+		project, err := getProject(projectID)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] Getting target project %s to validate its quota: %v", projectID, err)
+		}
+		shortages, err := multiClusterAppQuotaShortages(project.ResourceQuota, requests)
+		if err != nil {
+			return nil, err
+		}
+		for _, shortage := range shortages {
+			out = append(out, "target "+projectID+" "+shortage)
+		}
+	}
+
+	return out, nil
+}
+
+// multiClusterAppCreatorUsername resolves creatorID to the creator username, or display name if it has no username.
+// Best effort, creatorID is returned if the user can't be resolved
+func multiClusterAppCreatorUsername(creatorID string, getUser func(string) (*managementClient.User, error)) string {
+	if len(creatorID) == 0 {
+		return ""
+	}
+
+	user, err := getUser(creatorID)
+	if err != nil {
+		log.Printf("[WARN] Resolving multi cluster app creator %s username: %v", creatorID, err)
+		return creatorID
+	}
+	if len(user.Username) > 0 {
+		return user.Username
+	}
+	if len(user.Name) > 0 {
+		return user.Name
+	}
+
+	return creatorID
+}
+
+// multiClusterAppWarnDeprecatedTemplate sets template_deprecation, logging a warning, if the catalog template or
+// template version is deprecated. Templates are just looked up on create or catalog_name, template_name or
+// template_version change
+func multiClusterAppWarnDeprecatedTemplate(d *schema.ResourceDiff, meta interface{}) error {
+	if meta == nil || !multiClusterAppTemplateChanged(len(d.Id()) == 0, d.HasChange) {
+		return nil
+	}
+	if !d.NewValueKnown("catalog_name") || !d.NewValueKnown("template_name") || !d.NewValueKnown("template_version") {
+		return d.SetNewComputed("template_deprecation")
+	}
+	templateID := multiClusterAppTemplatePrefix(d.Get) + d.Get("catalog_name").(string) + "-" + d.Get("template_name").(string)
+
+	client, err := multiClusterAppClient(d.Get, meta)
+	if err != nil {
+		log.Printf("[INFO] Skipping multi cluster app template deprecation check, getting management client: %v", err)
+		return nil
+	}
+	warning, err := multiClusterAppLookupTemplateDeprecation(templateID, d.Get("template_version").(string), client.Template.ByID, client.TemplateVersion.ByID)
+	if err != nil {
+		log.Printf("[INFO] Skipping multi cluster app template deprecation check, %v", err)
+		return nil
+	}
+	if len(warning) > 0 {
+		log.Printf("[WARN] multi cluster app %s: %s", d.Get("name").(string), warning)
+	}
+	if warning == d.Get("template_deprecation").(string) {
+		return nil
+	}
+
+	return d.SetNew("template_deprecation", warning)
+}
+
+// multiClusterAppTemplateChanged returns true if the multi cluster app is new or its catalog template or template
+// version changes
+func multiClusterAppTemplateChanged(isNew bool, hasChange func(string) bool) bool {
+	return isNew || hasChange("catalog_name") || hasChange("template_name") || hasChange("template_version")
+}
+
+// multiClusterAppLookupTemplateDeprecation returns the multiClusterAppTemplateDeprecation warning of templateID and
+// its appVersion template version, if set. A not found template version is logged and ignored
+func multiClusterAppLookupTemplateDeprecation(templateID, appVersion string, getTemplate func(string) (*managementClient.Template, error), getTemplateVersion func(string) (*managementClient.TemplateVersion, error)) (string, error) {
+	template, err := getTemplate(templateID)
+	if err != nil {
+		return "", fmt.Errorf("getting template %s: %v", templateID, err)
+	}
+	var templateVersion *managementClient.TemplateVersion
+	if len(appVersion) > 0 {
+		templateVersionID := templateID + "-" + appVersion
+		templateVersion, err = getTemplateVersion(templateVersionID)
+		if err != nil {
+			log.Printf("[INFO] Skipping multi cluster app template version deprecation check, getting template version %s: %v", templateVersionID, err)
+		}
+	}
+
+	return multiClusterAppTemplateDeprecation(template, templateVersion), nil
+}
+
+// multiClusterAppValidateLockVersion fails the plan if lock_version is true and template_version isn't set, so it
+// would be resolved to the latest template version
+func multiClusterAppValidateLockVersion(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get("lock_version").(bool) || !d.NewValueKnown("template_version") || len(d.Get("template_version").(string)) > 0 {
+		return nil
+	}
+
+	return fmt.Errorf("[ERROR] multi cluster app %s lock_version is true, template_version must be set", d.Get("name").(string))
+}
+
+// multiClusterAppWarnMajorVersionResolution logs a warning if the multi cluster app is replaced and the latest template
+// version, resolved if template_version isn't set, is a major version bump from the current template_version. The
+// replacement is planned without state, so the check is done on the plan of the resource being replaced. The warning is
+// just logged, the plugin SDK can't show diagnostics on the plan output
+func multiClusterAppWarnMajorVersionResolution(d *schema.ResourceDiff, meta interface{}) error {
+	if meta == nil || len(d.Id()) == 0 || d.Get("lock_version").(bool) || d.HasChange("template_version") || !d.NewValueKnown("catalog_name") || !d.NewValueKnown("template_name") {
+		return nil
+	}
+	if !d.HasChange("name") && !(d.Get("force_new_on_catalog_change").(bool) && d.HasChange("catalog_name")) {
+		return nil
+	}
+	current, _ := d.GetChange("template_version")
+	templateID := multiClusterAppTemplatePrefix(d.Get) + d.Get("catalog_name").(string) + "-" + d.Get("template_name").(string)
+
+	client, err := multiClusterAppClient(d.Get, meta)
+	if err != nil {
+		log.Printf("[INFO] Skipping multi cluster app template version resolution check, getting management client: %v", err)
+		return nil
+	}
+	template, err := client.Template.ByID(templateID)
+	if err != nil {
+		log.Printf("[INFO] Skipping multi cluster app template version resolution check, getting template %s: %v", templateID, err)
+		return nil
+	}
+
+	if resolved := multiClusterAppMajorVersionResolution(current.(string), template); len(resolved) > 0 {
+		log.Printf("[WARN] multi cluster app %s is replaced, if template_version isn't set it's resolved to %s, a major version bump from current template version %s. Set template_version or lock_version to avoid unplanned upgrades", d.Get("name").(string), resolved, current)
+	}
+
+	return nil
+}
+
+// multiClusterAppMajorVersionResolution returns the latest template version if it's a major version bump from
+// current, empty otherwise
+func multiClusterAppMajorVersionResolution(current string, template *managementClient.Template) string {
+	if template == nil || len(template.VersionLinks) == 0 {
+		return ""
+	}
+	resolved, err := getLatestVersion(template.VersionLinks)
+	if err != nil || !isMajorVersionBump(current, resolved) {
+		return ""
+	}
+
+	return resolved
+}
+
+// multiClusterAppTemplateDeprecation returns a warning if the template or template version is labeled or annotated
+// as deprecated, empty otherwise
+func multiClusterAppTemplateDeprecation(template *managementClient.Template, templateVersion *managementClient.TemplateVersion) string {
+	isDeprecated := func(labels, annotations map[string]string) bool {
+		return strings.EqualFold(labels[multiClusterAppDeprecatedAnnotation], "true") || strings.EqualFold(annotations[multiClusterAppDeprecatedAnnotation], "true")
+	}
+
+	if template != nil && isDeprecated(template.Labels, template.Annotations) {
+		return fmt.Sprintf("catalog template %s is deprecated, consider migrating to a maintained chart", template.ID)
+	}
+	if templateVersion != nil && isDeprecated(templateVersion.Labels, templateVersion.Annotations) {
+		return fmt.Sprintf("catalog template version %s is deprecated, consider upgrading template_version", templateVersion.ID)
+	}
+
+	return ""
+}
+
+// multiClusterAppForceNewOnCatalogChange forces replacement if catalog_name changes and force_new_on_catalog_change is true
+func multiClusterAppForceNewOnCatalogChange(d *schema.ResourceDiff, meta interface{}) error {
+	if len(d.Id()) == 0 || !d.Get("force_new_on_catalog_change").(bool) || !d.HasChange("catalog_name") {
+		return nil
+	}
+
+	return d.ForceNew("catalog_name")
+}
+
+// multiClusterAppReportUpgradeStrategy logs the upgrade strategy used to roll out the multi cluster app on create, or
+// on update if the changes roll out targets
+func multiClusterAppReportUpgradeStrategy(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("upgrade_strategy") {
+		return nil
+	}
+	if len(d.Id()) > 0 && !d.HasChange("template_version") && !d.HasChange("answers") && !d.HasChange("group_answers") && !d.HasChange("targets") && !d.HasChange("upgrade_strategy") {
+		return nil
+	}
+	log.Printf("[INFO] multi cluster app %s effective upgrade strategy: %s", d.Get("name").(string), multiClusterAppEffectiveUpgradeStrategy(d.Get("upgrade_strategy").([]interface{})))
+
+	return nil
+}
+
+// multiClusterAppEffectiveUpgradeStrategy returns a description of the upgrade strategy, naming the fields left to
+// Rancher defaults
+func multiClusterAppEffectiveUpgradeStrategy(in []interface{}) string {
+	strategy := expandUpgradeStrategy(in)
+	if strategy.RollingUpdate == nil {
+		return "Rancher default, all targets upgraded at once"
+	}
+	batchSize := "Rancher default"
+	if strategy.RollingUpdate.BatchSize > 0 {
+		batchSize = fmt.Sprintf("%d", strategy.RollingUpdate.BatchSize)
+	}
+	interval := "Rancher default"
+	if strategy.RollingUpdate.Interval > 0 {
+		interval = fmt.Sprintf("%ds", strategy.RollingUpdate.Interval)
+	}
+
+	return "rolling update, batch size " + batchSize + ", interval " + interval
+}
+
+// multiClusterAppSummarizeAnswersDiff sets answers_diff_summary with the answer keys added, removed or changed on plan.
+// It's informational, the summary of the last answers change is kept until answers change again
+func multiClusterAppSummarizeAnswersDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("answers") || !d.HasChange("answers") {
+		return nil
+	}
+	oldAnswers, newAnswers := d.GetChange("answers")
+	summary := multiClusterAppAnswersDiffSummary(oldAnswers.([]interface{}), newAnswers.([]interface{}))
+	if len(summary) == 0 {
+		return nil
+	}
+	for _, change := range summary {
+		log.Printf("[INFO] multi cluster app %s answers change: %s", d.Get("name").(string), change)
+	}
+
+	return d.SetNew("answers_diff_summary", summary)
+}
+
+// multiClusterAppAnswersDiffSummary returns the answer keys added (+), removed (-) or changed (~) from old to new answers,
+// sorted by scope and key. Values aren't included, they may be sensitive
+func multiClusterAppAnswersDiffSummary(oldAnswers, newAnswers []interface{}) []string {
+	values := func(answers []interface{}) map[string]map[string]string {
+		out := map[string]map[string]string{}
+		for _, a := range answers {
+			in, ok := a.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			scope := flattenedAnswerScope(in)
+			if out[scope] == nil {
+				out[scope] = map[string]string{}
+			}
+			if v, ok := in["values"].(map[string]interface{}); ok {
+				for k, value := range v {
+					out[scope][k] = fmt.Sprint(value)
+				}
+			}
+		}
+		return out
+	}
+	oldValues, newValues := values(oldAnswers), values(newAnswers)
+
+	scopes := []string{}
+	for scope := range oldValues {
+		scopes = append(scopes, scope)
+	}
+	for scope := range newValues {
+		if _, ok := oldValues[scope]; !ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+
+	out := []string{}
+	for _, scope := range scopes {
+		keys := []string{}
+		for k := range oldValues[scope] {
+			keys = append(keys, k)
+		}
+		for k := range newValues[scope] {
+			if _, ok := oldValues[scope][k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			oldValue, inOld := oldValues[scope][k]
+			newValue, inNew := newValues[scope][k]
+			switch {
+			case !inOld:
+				out = append(out, "+ "+scope+" "+k)
+			case !inNew:
+				out = append(out, "- "+scope+" "+k)
+			case oldValue != newValue:
+				out = append(out, "~ "+scope+" "+k)
+			}
+		}
+	}
+
+	return out
+}
+
+// multiClusterAppDebugAnswers logs the resolved value and source scope of every answer key on every target, if debug_answers is true
+func multiClusterAppDebugAnswers(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get("debug_answers").(bool) || !d.NewValueKnown("answers") || !d.NewValueKnown("answers_object") || !d.NewValueKnown("group_answers") || !d.NewValueKnown("targets") {
+		return nil
+	}
+	sources, err := multiClusterAppAnswerSources(d.Get)
+	if err != nil {
+		return err
+	}
+	projectIDs := make([]string, 0, len(sources))
+	for projectID := range sources {
+		projectIDs = append(projectIDs, projectID)
+	}
+	sort.Strings(projectIDs)
+	for _, projectID := range projectIDs {
+		keys := make([]string, 0, len(sources[projectID]))
+		for key := range sources[projectID] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			log.Printf("[WARN] multi cluster app %s target %s answer %s=%q from %s answers", d.Get("name").(string), projectID, key, sources[projectID][key].Value, sources[projectID][key].Scope)
+		}
+	}
+
+	return nil
+}
+
+// multiClusterAppValidateRequiredAnswers checks at plan time that chart required questions without default are answered on every target.
+// Validation is skipped if Rancher isn't reachable or template version is unknown
+func multiClusterAppValidateRequiredAnswers(d *schema.ResourceDiff, meta interface{}) error {
+	if meta == nil || !d.NewValueKnown("answers") || !d.NewValueKnown("answers_object") || !d.NewValueKnown("group_answers") || !d.NewValueKnown("targets") || !d.NewValueKnown("template_version") {
+		return nil
+	}
+	appVersion := d.Get("template_version").(string)
+	if len(appVersion) == 0 {
+		return nil
+	}
+	templateVersionID := multiClusterAppTemplatePrefix(d.Get) + d.Get("catalog_name").(string) + "-" + d.Get("template_name").(string) + "-" + appVersion
+
+	client, err := multiClusterAppClient(d.Get, meta)
+	if err != nil {
+		log.Printf("[WARN] Skipping multi cluster app answers validation, getting management client: %v", err)
+		return nil
+	}
+	templateVersion, err := client.TemplateVersion.ByID(templateVersionID)
+	if err != nil {
+		if IsNotFound(err) && multiClusterAppValidateTemplateVersion(d, meta) {
+			notFoundErr := multiClusterAppTemplateVersionNotFound(d.Get("catalog_name").(string), d.Get("template_name").(string), appVersion, client.Catalog.ByID, client.Template.ByID)
+			if notFoundErr != nil {
+				return notFoundErr
+			}
+		}
+		log.Printf("[WARN] Skipping multi cluster app answers validation, getting template version %s: %v", templateVersionID, err)
+		return nil
+	}
+
+	answers, err := expandMultiClusterAppAnswers(multiClusterAppDecryptedGet(d.Get, meta))
+	if err != nil {
+		return err
+	}
+	targets := expandTargets(d.Get("targets").([]interface{}))
+	missing := multiClusterAppMissingAnswers(templateVersion.Questions, answers, targets)
+	if len(missing) > 0 {
+		return fmt.Errorf("[ERROR] multi cluster app template version %s required answers are missing: %s", templateVersionID, strings.Join(missing, ", "))
+	}
+	invalid := multiClusterAppInvalidEnumAnswers(templateVersion.Questions, answers)
+	if len(invalid) > 0 {
+		return fmt.Errorf("[ERROR] multi cluster app template version %s answers are not allowed: %s", templateVersionID, strings.Join(invalid, "; "))
+	}
+
+	return nil
+}
+
+// multiClusterAppValidateTemplateVersion returns true if a not found template version should fail the plan. Just on
+// create or catalog, template or version change, if the template is global and wait_for_catalog isn't true
+func multiClusterAppValidateTemplateVersion(d *schema.ResourceDiff, meta interface{}) bool {
+	if d.Get("wait_for_catalog").(bool) || d.Get("catalog_scope").(string) != catalogScopeGlobal || multiClusterAppCatalogResolutionAmbiguous(meta.(*Config).CatalogResolutionOrder) {
+		return false
+	}
+
+	return len(d.Id()) == 0 || d.HasChange("catalog_name") || d.HasChange("template_name") || d.HasChange("template_version")
+}
+
+// multiClusterAppTemplateVersionNotFound returns an error naming the not found catalog, template and version, listing
+// the available template versions. Returns nil if the catalog isn't found either, as it may be created on apply
+func multiClusterAppTemplateVersionNotFound(catalogName, templateName, version string, getCatalog func(string) (*managementClient.Catalog, error), getTemplate func(string) (*managementClient.Template, error)) error {
+	templateID := catalogName + "-" + templateName
+	if !strings.Contains(catalogName, ":") {
+		templateID = MultiClusterAppTemplatePrefix + templateID
+	}
+	template, err := getTemplate(templateID)
+	if err != nil {
+		if !IsNotFound(err) {
+			return nil
+		}
+		if _, err := getCatalog(catalogName); err != nil {
+			log.Printf("[WARN] multi cluster app catalog %s not found, it may be created on apply: %v", catalogName, err)
+			return nil
+		}
+		return fmt.Errorf("[ERROR] multi cluster app template %s not found on catalog %s", templateName, catalogName)
+	}
+
+	return fmt.Errorf("[ERROR] multi cluster app template version %s not found on catalog %s template %s, available versions: %s", version, catalogName, templateName, strings.Join(multiClusterAppTemplateVersions(template), ", "))
+}
+
+// multiClusterAppTemplateVersions returns the template versions sorted, falling back to lexical order if any version
+// isn't semver
+func multiClusterAppTemplateVersions(template *managementClient.Template) []string {
+	out := make([]string, 0, len(template.VersionLinks))
+	sorted, err := sortVersions(template.VersionLinks)
+	if err != nil {
+		for version := range template.VersionLinks {
+			out = append(out, version)
+		}
+		sort.Strings(out)
+		return out
+	}
+	for _, v := range sorted {
+		out = append(out, v.Original())
+	}
+
+	return out
+}
+
+// multiClusterAppInvalidEnumAnswers returns the answers of enum questions and subquestions whose value isn't an allowed
+// option, with the allowed options. Values resolved on apply, like references or cluster tokens, are not checked
+func multiClusterAppInvalidEnumAnswers(questions []managementClient.Question, answers []managementClient.Answer) []string {
+	options := map[string][]string{}
+	for _, q := range questions {
+		if q.Type == "enum" && len(q.Options) > 0 {
+			options[q.Variable] = q.Options
+		}
+		for _, sq := range q.Subquestions {
+			if sq.Type == "enum" && len(sq.Options) > 0 {
+				options[sq.Variable] = sq.Options
+			}
+		}
+	}
+	if len(options) == 0 {
+		return nil
+	}
+
+	invalid := []string{}
+	for _, a := range answers {
+		keys := make([]string, 0, len(a.Values))
+		for k := range a.Values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := a.Values[k]
+			allowed, ok := options[k]
+			if !ok || strings.Contains(v, answerClusterProviderToken) || containsString(allowed, v) {
+				continue
+			}
+			if _, _, _, ok := splitAnswerConfigMapReference(v); ok {
+				continue
+			}
+			invalid = append(invalid, fmt.Sprintf("%s %s is %q, allowed values: %s", multiClusterAppAnswerScope(a), k, v, strings.Join(allowed, ", ")))
+		}
+	}
+
+	return invalid
+}
+
+// multiClusterAppMissingAnswers returns required questions without default not answered on every target.
+// Conditional questions are not checked
+func multiClusterAppMissingAnswers(questions []managementClient.Question, answers []managementClient.Answer, targets []managementClient.Target) []string {
+	projectIDs := []string{""}
+	if len(targets) > 0 {
+		projectIDs = make([]string, 0, len(targets))
+		for _, t := range targets {
+			projectIDs = append(projectIDs, t.ProjectID)
+		}
+	}
+
+	missing := []string{}
+	for _, q := range questions {
+		if !q.Required || len(q.Default) > 0 || len(q.ShowIf) > 0 {
+			continue
+		}
+		for _, projectID := range projectIDs {
+			values := effectiveAnswerValues(answers, projectID)
+			if _, ok := values[q.Variable]; !ok {
+				missing = append(missing, q.Variable)
+				break
+			}
+		}
+	}
+
+	return missing
+}
+
+// multiClusterAppResolveRoles returns the roles to submit, including the role templates they depend on if
+// resolve_role_dependencies is true. The auto included roles are set on role_dependencies
+func multiClusterAppResolveRoles(d *schema.ResourceData, client *managementClient.Client) ([]string, error) {
+	roles := toArrayString(d.Get("roles").([]interface{}))
+	if !d.Get("resolve_role_dependencies").(bool) {
+		d.Set("role_dependencies", []interface{}{})
+		return roles, nil
+	}
+
+	out, added, err := multiClusterAppRoleDependencies(roles, client.RoleTemplate.ByID)
+	if err != nil {
+		return nil, err
+	}
+	if len(added) > 0 {
+		log.Printf("[INFO] Auto including role dependencies %v on multi cluster app %s", added, d.Get("name").(string))
+	}
+	d.Set("role_dependencies", toArrayInterface(added))
+
+	return out, nil
+}
+
+// multiClusterAppRoleDependencies returns the roles plus the role templates they depend on, recursively, and the
+// auto included ones. Roles that can't be looked up are kept without resolving their dependencies
+func multiClusterAppRoleDependencies(roles []string, getRoleTemplate func(string) (*managementClient.RoleTemplate, error)) ([]string, []string, error) {
+	out := make([]string, 0, len(roles))
+	added := []string{}
+	seen := map[string]bool{}
+	for _, role := range roles {
+		if !seen[role] {
+			seen[role] = true
+			out = append(out, role)
+		}
+	}
+	for i := 0; i < len(out); i++ {
+		roleTemplate, err := getRoleTemplate(out[i])
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				log.Printf("[WARN] Skipping role %s dependencies resolution: %v", out[i], err)
+				continue
+			}
+			return nil, nil, fmt.Errorf("[ERROR] Getting role template %s: %v", out[i], err)
+		}
+		for _, dependency := range roleTemplate.RoleTemplateIDs {
+			if seen[dependency] {
+				continue
+			}
+			seen[dependency] = true
+			out = append(out, dependency)
+			added = append(added, dependency)
+		}
+	}
+
+	return out, added, nil
+}
+
+// multiClusterAppValidateTargetRemoval fails the plan if deployed targets are removed from targets, uninstalling their
+// apps, unless allow_target_removal is true or their project IDs are on allow_target_removal_projects.
+// Disabling a target isn't guarded, as it's explicit
+func multiClusterAppValidateTargetRemoval(d *schema.ResourceDiff, meta interface{}) error {
+	if len(d.Id()) == 0 || !d.NewValueKnown("targets") || !d.HasChange("targets") || d.Get("allow_target_removal").(bool) {
+		return nil
+	}
+	o, n := d.GetChange("targets")
+	blocked := multiClusterAppBlockedTargetRemovals(o.([]interface{}), n.([]interface{}), toArrayString(d.Get("allow_target_removal_projects").([]interface{})))
+	if len(blocked) > 0 {
+		return fmt.Errorf("[ERROR] Removing targets %v would uninstall their multi cluster app %s apps. Set allow_target_removal to true or add them to allow_target_removal_projects to allow it", blocked, d.Get("name").(string))
+	}
+
+	return nil
+}
+
+// multiClusterAppValidateSkipUpdateTargets fails the plan if targets skipping updates would be updated anyway, as
+// Rancher applies template version changes and rollbacks to every target, or if they are removed or disabled
+func multiClusterAppValidateSkipUpdateTargets(d *schema.ResourceDiff, meta interface{}) error {
+	if len(d.Id()) == 0 || !d.NewValueKnown("targets") {
+		return nil
+	}
+	o, n := d.GetChange("targets")
+	skipped := []string{}
+	for projectID := range skipUpdateTargetProjectIDs(n.([]interface{})) {
+		skipped = append(skipped, projectID)
+	}
+	sort.Strings(skipped)
+	if len(skipped) > 0 {
+		for _, key := range []string{"catalog_name", "template_name", "template_version", "revision_id"} {
+			if d.HasChange(key) {
+				return fmt.Errorf("[ERROR] Changing %s would update multi cluster app %s targets skipping update %v. Set their skip_update to false to allow it", key, d.Get("name").(string), skipped)
+			}
+		}
+	}
+
+	enabled := map[string]bool{}
+	for _, t := range expandTargets(n.([]interface{})) {
+		enabled[t.ProjectID] = true
+	}
+	removed := []string{}
+	for projectID := range skipUpdateTargetProjectIDs(o.([]interface{})) {
+		if !enabled[projectID] {
+			removed = append(removed, projectID)
+		}
+	}
+	if len(removed) > 0 {
+		sort.Strings(removed)
+		return fmt.Errorf("[ERROR] Targets %v skip update, they can't be removed nor disabled from multi cluster app %s. Set their skip_update to false first", removed, d.Get("name").(string))
+	}
+
+	return nil
+}
+
+// multiClusterAppValidateGitOpsOwner fails the plan if the multi cluster app is owned by a GitOps controller, e.g.
+// Fleet, and read_only isn't true, as both would fight over it
+func multiClusterAppValidateGitOpsOwner(d *schema.ResourceDiff, meta interface{}) error {
+	if len(d.Id()) == 0 || d.Get("read_only").(bool) {
+		return nil
+	}
+	if owner, _ := d.GetChange("gitops_owner"); len(owner.(string)) > 0 {
+		return fmt.Errorf("[ERROR] Multi cluster app %s is managed by %s. Set read_only to true to just read it, or remove it from %s before managing it with terraform", d.Id(), owner, owner)
+	}
+
+	return nil
+}
+
+// multiClusterAppValidateMembers fails the plan if a changed members principal doesn't exist on Rancher. Skipped if
+// members aren't known yet or Rancher is unreachable
+func multiClusterAppValidateMembers(d *schema.ResourceDiff, meta interface{}) error {
+	if meta == nil || !d.NewValueKnown("members") || !d.HasChange("members") {
+		return nil
+	}
+
+	client, err := multiClusterAppClient(d.Get, meta)
+	if err != nil {
+		log.Printf("[WARN] Skipping multi cluster app members validation, getting management client: %v", err)
+		return nil
+	}
+	getPrincipal := func(id string) (*managementClient.Principal, error) {
+		return client.Principal.ByID(url.PathEscape(id))
+	}
+
+	return validateMemberPrincipals(expandMembers(d.Get("members").([]interface{})), getPrincipal)
+}
+
+// multiClusterAppBlockedTargetRemovals returns the project IDs of old enabled targets not present on new targets nor allowed
+func multiClusterAppBlockedTargetRemovals(oldTargets, newTargets []interface{}, allowed []string) []string {
+	keep := map[string]bool{}
+	for _, project := range allowed {
+		keep[project] = true
+	}
+	for _, t := range newTargets {
+		if target, ok := t.(map[string]interface{}); ok {
+			keep[target["project_id"].(string)] = true
+		}
+	}
+
+	blocked := []string{}
+	for _, t := range oldTargets {
+		target, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if enabled, ok := target["enabled"].(bool); ok && !enabled {
+			continue
+		}
+		projectID := target["project_id"].(string)
+		if !keep[projectID] {
+			blocked = append(blocked, projectID)
+		}
+	}
+
+	return blocked
+}
+
+func multiClusterAppRemovedRoles(d *schema.ResourceData) []string {
+	o, n := d.GetChange("roles")
+	newRoles := toArrayString(n.([]interface{}))
+
+	removed := []string{}
+	for _, role := range toArrayString(o.([]interface{})) {
+		found := false
+		for _, newRole := range newRoles {
+			if role == newRole {
+				found = true
+				break
+			}
+		}
+		if !found {
+			removed = append(removed, role)
+		}
+	}
+
+	return removed
+}
+
+// multiClusterAppRolesRemovalRefreshFunc returns a resource.StateRefreshFunc, used to watch removed roles are revoked
+// on every multi cluster app target. Rancher binds the app roles on target projects to the multi cluster app system
+// user, whose principal ID is system://<name>
+func multiClusterAppRolesRemovalRefreshFunc(getBindings func(string) ([]managementClient.ProjectRoleTemplateBinding, error), mca *managementClient.MultiClusterApp, removedRoles []string) resource.StateRefreshFunc {
+	principalID := "system://" + strings.TrimPrefix(mca.ID, MultiClusterAppTemplatePrefix)
+
+	return func() (interface{}, string, error) {
+		for _, t := range mca.Targets {
+			bindings, err := getBindings(t.ProjectID)
+			if err != nil {
+				return nil, "", err
+			}
+			for _, binding := range bindings {
+				if binding.UserPrincipalID != principalID {
+					continue
+				}
+				for _, role := range removedRoles {
+					if binding.RoleTemplateID == role {
+						return mca, "removing", nil
+					}
+				}
+			}
+		}
+
+		return mca, "removed", nil
+	}
+}
+
+// multiClusterAppTargetAppID returns the project app ID deployed for target
+func multiClusterAppTargetAppID(target managementClient.Target) string {
+	return splitProjectIDPart(target.ProjectID) + ":" + target.AppID
+}
+
+// multiClusterAppClusterProvider returns a function getting the provider of a cluster, used to render answers
+func multiClusterAppClusterProvider(get func(string) interface{}, meta interface{}) func(clusterID string) (string, error) {
+	return func(clusterID string) (string, error) {
+		client, err := multiClusterAppClient(get, meta)
+		if err != nil {
+			return "", err
+		}
+		cluster, err := client.Cluster.ByID(clusterID)
+		if err != nil {
+			return "", err
+		}
+		return cluster.Provider, nil
+	}
+}
+
+// getMultiClusterAppTargetApp returns the project app deployed for target
+func getMultiClusterAppTargetApp(meta interface{}, target managementClient.Target) (*projectClient.App, error) {
+	client, err := meta.(*Config).ProjectClient(target.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.App.ByID(multiClusterAppTargetAppID(target))
+}
+
+// getMultiClusterAppTargetHelmReleases returns the Helm 3 release secrets of the target app namespace. Rancher API can't
+// filter by labels, so secrets are filtered by the Helm release secret type and the release is matched by labels
+func getMultiClusterAppTargetHelmReleases(meta interface{}, target managementClient.Target, namespace string) ([]projectClient.NamespacedSecret, error) {
+	client, err := meta.(*Config).ProjectClient(target.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{
+		"namespaceId": namespace,
+		"kind":        helmReleaseSecretType,
+	}
+	collection, err := client.NamespacedSecret.List(NewListOpts(filters))
+	if err != nil {
+		return nil, err
+	}
+
+	return collection.Data, nil
+}
+
+// multiClusterAppWaitForNamespacesRemoval waits until the target app namespaces are removed, e.g. lingering on finalizers.
+// Targets whose namespace is unknown or whose cluster is unreachable are skipped
+func multiClusterAppWaitForNamespacesRemoval(ctx context.Context, meta interface{}, targets []managementClient.Target, namespaces map[string]interface{}, timeout time.Duration) error {
+	for _, t := range targets {
+		namespace, ok := namespaces[t.ProjectID].(string)
+		if !ok || len(namespace) == 0 {
+			continue
+		}
+		clusterID, err := clusterIDFromProjectID(t.ProjectID)
+		if err != nil {
+			return err
+		}
+		client, err := meta.(*Config).ClusterClient(clusterID)
+		if err != nil {
+			log.Printf("[WARN] Skipping namespace %s removal wait on unreachable cluster %s: %v", namespace, clusterID, err)
+			continue
+		}
+		getNamespace := func() (*clusterClient.Namespace, error) {
+			return client.Namespace.ByID(namespace)
+		}
+		if _, err := getNamespace(); err != nil {
+			if !IsNotFound(err) && !IsForbidden(err) {
+				log.Printf("[WARN] Skipping namespace %s removal wait on unreachable cluster %s: %v", namespace, clusterID, err)
+			}
+			continue
+		}
+
+		log.Printf("[INFO] Waiting for namespace %s removal on cluster %s", namespace, clusterID)
+		stateConf := newStateChangeConf(ctx, meta, []string{"removing"}, []string{"removed"}, multiClusterAppNamespaceRemovalRefreshFunc(getNamespace), timeout)
+		_, waitErr := waitForStateWithBudget(meta, stateConf)
+		if waitErr != nil {
+			return fmt.Errorf("[ERROR] waiting for namespace %s to be removed on cluster %s: %s", namespace, clusterID, waitErr)
+		}
+	}
+
+	return nil
+}
+
+// multiClusterAppNamespaceRemovalRefreshFunc returns a resource.StateRefreshFunc, used to watch a target app namespace removal
+func multiClusterAppNamespaceRemovalRefreshFunc(getNamespace func() (*clusterClient.Namespace, error)) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		obj, err := getNamespace()
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				return obj, "removed", nil
+			}
+			return nil, "", err
+		}
+
+		return obj, "removing", nil
+	}
+}
+
+// multiClusterAppTargetNamespaces returns the namespace of every target app by project ID. Known namespaces are kept and
+// target apps are only read if getTargetApp is set, on create, import or when targets change. Targets on inactive clusters
+// are skipped and target app errors are just logged, not failing the read
+func multiClusterAppTargetNamespaces(id string, targets []managementClient.Target, known map[string]interface{}, clusterActive func(string) (string, bool), getTargetApp func(managementClient.Target) (*projectClient.App, error)) map[string]interface{} {
+	out := make(map[string]interface{}, len(targets))
+	for _, t := range targets {
+		if v, ok := known[t.ProjectID].(string); ok && len(v) > 0 {
+			out[t.ProjectID] = v
+			continue
+		}
+		if getTargetApp == nil || len(t.AppID) == 0 {
+			continue
+		}
+		if state, active := clusterActive(t.ProjectID); !active {
+			log.Printf("[INFO] multi cluster app %s target %s cluster is %s, skipping namespace", id, t.ProjectID, state)
+			continue
+		}
+		app, err := getTargetApp(t)
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				log.Printf("[INFO] multi cluster app %s target app %s not found reading namespace", id, t.AppID)
+				continue
+			}
+			log.Printf("[WARN] Getting multi cluster app %s target app %s namespace: %v", id, t.AppID, err)
+			continue
+		}
+		if len(app.TargetNamespace) > 0 {
+			out[t.ProjectID] = app.TargetNamespace
+		}
+	}
+
+	return out
+}
+
+// multiClusterAppSetTargetNamespaces sets target_namespaces from the known values, reading the target apps if lookup is true
+func multiClusterAppSetTargetNamespaces(d *schema.ResourceData, meta interface{}, client *managementClient.Client, targets []managementClient.Target, lookup bool) error {
+	clusterActive := func(projectID string) (string, bool) {
+		return multiClusterAppTargetClusterActive(client, projectID)
+	}
+	var getTargetApp func(managementClient.Target) (*projectClient.App, error)
+	if lookup {
+		getTargetApp = func(target managementClient.Target) (*projectClient.App, error) {
+			return getMultiClusterAppTargetApp(meta, target)
+		}
+	}
+
+	return d.Set("target_namespaces", multiClusterAppTargetNamespaces(d.Id(), targets, d.Get("target_namespaces").(map[string]interface{}), clusterActive, getTargetApp))
+}
+
+// multiClusterAppTargetRevisions returns the applied revision ID of every target app by project ID, to find targets lagging
+// behind the multi cluster app revision. Targets without app yet or whose app isn't found are skipped
+func multiClusterAppTargetRevisions(in *managementClient.MultiClusterApp, getTargetApp func(managementClient.Target) (*projectClient.App, error)) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(in.Targets))
+	for _, t := range in.Targets {
+		if len(t.AppID) == 0 {
+			continue
+		}
+		app, err := getTargetApp(t)
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				log.Printf("[INFO] multi cluster app %s target app %s not found reading revision", in.ID, t.AppID)
+				continue
+			}
+			return nil, fmt.Errorf("[ERROR] Getting multi cluster app %s target app %s: %v", in.ID, t.AppID, err)
+		}
+		if len(app.AppRevisionID) > 0 {
+			out[t.ProjectID] = app.AppRevisionID
+		}
+	}
+
+	return out, nil
+}
+
+// multiClusterAppTargetHelmRevisions returns the deployed Helm release revision of every target app by project ID.
+// Targets on not active clusters, not found target apps and target apps not deployed by Helm 3 are skipped
+func multiClusterAppTargetHelmRevisions(in *managementClient.MultiClusterApp, clusterActive func(string) bool, getTargetApp func(managementClient.Target) (*projectClient.App, error), getReleases func(managementClient.Target, string) ([]projectClient.NamespacedSecret, error)) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(in.Targets))
+	for _, t := range in.Targets {
+		if len(t.AppID) == 0 {
+			continue
+		}
+		if !clusterActive(t.ProjectID) {
+			log.Printf("[INFO] multi cluster app %s target %s cluster isn't active, skipping Helm revision", in.ID, t.ProjectID)
+			continue
+		}
+		app, err := getTargetApp(t)
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				log.Printf("[INFO] multi cluster app %s target app %s not found reading Helm revision", in.ID, t.AppID)
+				continue
+			}
+			return nil, fmt.Errorf("[ERROR] Getting multi cluster app %s target app %s: %v", in.ID, t.AppID, err)
+		}
+		if app.HelmVersion != catalogHelmV3 {
+			log.Printf("[INFO] multi cluster app %s target app %s isn't deployed by Helm 3, skipping Helm revision", in.ID, t.AppID)
+			continue
+		}
+		releases, err := getReleases(t, app.TargetNamespace)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] Getting multi cluster app %s target app %s Helm releases: %v", in.ID, t.AppID, err)
+		}
+		if revision, ok := helmReleaseRevision(releases, app.Name); ok {
+			out[t.ProjectID] = strconv.Itoa(revision)
+		}
+	}
+
+	return out, nil
+}
+
+// helmReleaseRevision returns the revision of the name Helm 3 release from its release secrets. The deployed revision
+// is returned if any, the latest one otherwise
+func helmReleaseRevision(releases []projectClient.NamespacedSecret, name string) (int, bool) {
+	deployed, latest := 0, 0
+	for _, r := range releases {
+		if r.Labels["owner"] != "helm" || r.Labels["name"] != name {
+			continue
+		}
+		version, err := strconv.Atoi(r.Labels["version"])
+		if err != nil {
+			continue
+		}
+		if r.Labels["status"] == "deployed" && version > deployed {
+			deployed = version
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	if deployed > 0 {
+		return deployed, true
+	}
+
+	return latest, latest > 0
+}
+
+// multiClusterAppTargetAnswersDrift reports target apps whose live answers differ from the multi cluster app effective answers.
+// Missing target apps are skipped, they are recreated by Rancher
+func multiClusterAppTargetAnswersDrift(in *managementClient.MultiClusterApp, getTargetApp func(managementClient.Target) (*projectClient.App, error)) ([]interface{}, error) {
+	out := []interface{}{}
+	for _, t := range in.Targets {
+		app, err := getTargetApp(t)
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				log.Printf("[INFO] multi cluster app %s target app %s not found reading answers", in.ID, t.AppID)
+				continue
+			}
+			return nil, fmt.Errorf("[ERROR] Getting multi cluster app %s target app %s: %v", in.ID, t.AppID, err)
+		}
+
+		driftedAnswers := multiClusterAppDriftedAnswers(effectiveAnswerValues(in.Answers, t.ProjectID), app.Answers)
+		if len(driftedAnswers) == 0 {
+			continue
+		}
+		out = append(out, map[string]interface{}{
+			"project_id":      t.ProjectID,
+			"app_id":          t.AppID,
+			"drifted_answers": toArrayInterface(driftedAnswers),
+		})
+	}
+
+	return out, nil
+}
+
+// multiClusterAppWriteSnapshot writes the multi cluster app spec as JSON to path, readable just by the owner as answers may be sensitive
+func multiClusterAppWriteSnapshot(path string, mca *managementClient.MultiClusterApp) error {
+	snapshot := map[string]interface{}{
+		"name":                 mca.Name,
+		"templateVersionId":    mca.TemplateVersionID,
+		"answers":              mca.Answers,
+		"targets":              mca.Targets,
+		"roles":                mca.Roles,
+		"members":              mca.Members,
+		"revisionHistoryLimit": mca.RevisionHistoryLimit,
+		"upgradeStrategy":      mca.UpgradeStrategy,
+		"annotations":          mca.Annotations,
+		"labels":               mca.Labels,
+	}
+	out, err := interfaceToJSON(snapshot)
+	if err != nil {
+		return fmt.Errorf("[ERROR] Marshalling multi cluster app %s snapshot: %v", mca.ID, err)
+	}
+	err = ioutil.WriteFile(path, []byte(out), 0600)
+	if err != nil {
+		return fmt.Errorf("[ERROR] Writing multi cluster app %s snapshot to %s: %v", mca.ID, path, err)
+	}
+
+	return nil
+}
+
+// multiClusterAppDetachTargetApps unsets the multi cluster app ID on target apps, so they are kept on multi cluster app deletion
+func multiClusterAppDetachTargetApps(meta interface{}, mca *managementClient.MultiClusterApp) error {
+	for _, t := range mca.Targets {
+		client, err := meta.(*Config).ProjectClient(t.ProjectID)
+		if err != nil {
+			return err
+		}
+		app, err := client.App.ByID(multiClusterAppTargetAppID(t))
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				continue
+			}
+			return err
+		}
+		update := map[string]interface{}{
+			"multiClusterAppId": "",
+		}
+		_, err = client.App.Update(app, update)
+		if err != nil {
+			return fmt.Errorf("[ERROR] detaching app %s from multi cluster app %s: %v", app.ID, mca.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// multiClusterAppDoWithRetry calls fn on a tracing span named name, retrying throttling and server errors, as set on the
+// provider retry_max_attempts. Retries are aborted once the key timeout is reached
+func multiClusterAppDoWithRetry(ctx context.Context, d *schema.ResourceData, meta interface{}, name, key string, fn func() error) error {
+	ctx, cancel := context.WithTimeout(ctx, multiClusterAppTimeout(d, meta, key))
+	defer cancel()
+
+	config := meta.(*Config)
+	return config.withSpan(ctx, name, traceSpanKindClient, func(ctx context.Context) error {
+		return config.doWithRetry(ctx, fn)
+	})
+}
+
+// multiClusterAppGet gets the multi cluster app by ID on a tracing span
+func multiClusterAppGet(ctx context.Context, meta interface{}, client *managementClient.Client, id string) (*managementClient.MultiClusterApp, error) {
+	var multiClusterApp *managementClient.MultiClusterApp
+	err := meta.(*Config).withSpan(ctx, "MultiClusterApp.ByID", traceSpanKindClient, func(ctx context.Context) (err error) {
+		multiClusterApp, err = getMultiClusterApp(ctx, client, id)
+		return err
+	})
+
+	return multiClusterApp, err
+}
+
+// getMultiClusterApp gets a multi cluster app by ID, retrying a bounded number of times on transient errors.
+// Not found and forbidden errors are returned as is, so callers handle them consistently
+func getMultiClusterApp(ctx context.Context, client *managementClient.Client, id string) (*managementClient.MultiClusterApp, error) {
+	var obj *managementClient.MultiClusterApp
+	var err error
+	for i := 0; ; i++ {
+		obj, err = client.MultiClusterApp.ByID(id)
+		if err == nil || !isMultiClusterAppTransientError(err) || i >= multiClusterAppGetRetries {
+			return obj, err
+		}
+		log.Printf("[DEBUG] Getting multi cluster app ID %s failed, retrying (%d/%d): %v", id, i+1, multiClusterAppGetRetries, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(i+1) * time.Second):
+		}
+	}
+}
+
+// isMultiClusterAppTransientError checks if the error is worth retrying: server side errors,
+// throttling or errors not coming from the API at all, like connection errors
+func isMultiClusterAppTransientError(err error) bool {
+	if IsNotFound(err) || IsForbidden(err) {
+		return false
+	}
+	apiError, ok := err.(*clientbase.APIError)
+	if !ok {
+		return true
+	}
+	return apiError.StatusCode >= http.StatusInternalServerError || apiError.StatusCode == http.StatusTooManyRequests
+}
+
+// multiClusterAppStateRefreshFunc returns a resource.StateRefreshFunc, used to watch a Rancher MultiClusterApp.
+func multiClusterAppStateRefreshFunc(client *managementClient.Client, appID string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		obj, err := getMultiClusterApp(context.Background(), client, appID)
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				return obj, "removed", nil
+			}
+			return nil, "", err
+		}
+
+		return obj, obj.State, nil
+	}
+}
+
+// multiClusterAppTargetPriorities returns the priority of every target by project ID
+func multiClusterAppTargetPriorities(targets []interface{}) map[string]int {
+	out := map[string]int{}
+	for _, t := range targets {
+		in, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		projectID, _ := in["project_id"].(string)
+		priority, _ := in["priority"].(int)
+		out[projectID] = priority
+	}
+
+	return out
+}
+
+// multiClusterAppTargetStages splits the targets to add into stages by priority, the lowest first. Targets with the
+// same priority are added together, in config order
+func multiClusterAppTargetStages(in *managementClient.UpdateMultiClusterAppTargetsInput, priorities map[string]int) []*managementClient.UpdateMultiClusterAppTargetsInput {
+	levels := []int{}
+	byPriority := map[int]*managementClient.UpdateMultiClusterAppTargetsInput{}
+	for _, projectID := range in.Projects {
+		priority := priorities[projectID]
+		stage, ok := byPriority[priority]
+		if !ok {
+			stage = &managementClient.UpdateMultiClusterAppTargetsInput{}
+			byPriority[priority] = stage
+			levels = append(levels, priority)
+		}
+		stage.Projects = append(stage.Projects, projectID)
+		for _, a := range in.Answers {
+			if a.ProjectID == projectID {
+				stage.Answers = append(stage.Answers, a)
+			}
+		}
+	}
+	sort.Ints(levels)
 
-	if len(appVersion) > 0 {
-		return nil
+	out := make([]*managementClient.UpdateMultiClusterAppTargetsInput, 0, len(levels))
+	for _, priority := range levels {
+		out = append(out, byPriority[priority])
 	}
 
-	catalogName = MultiClusterAppTemplatePrefix + catalogName
+	return out
+}
 
-	appID := catalogName + "-" + appName
+// multiClusterAppSetAddedTargets sets on state the targets actually added when adding targets fails,
+// so a retry only attempts the remainder
+func multiClusterAppSetAddedTargets(ctx context.Context, d *schema.ResourceData, client *managementClient.Client, appID string, addErr error) error {
+	multiClusterApp, err := getMultiClusterApp(ctx, client, appID)
+	if err != nil {
+		return fmt.Errorf("[ERROR] adding targets on multi cluster app %s: %v", appID, addErr)
+	}
 
-	client, err := meta.(*Config).ManagementClient()
+	_, scales, err := flattenMultiClusterAppTargetScales(multiClusterApp.Annotations)
 	if err != nil {
 		return err
 	}
-
-	template, err := client.Template.ByID(appID)
+	err = d.Set("targets", flattenMultiClusterAppKeptTargets(d, multiClusterApp.Targets, scales))
 	if err != nil {
 		return err
 	}
 
-	appVersion, err = getLatestVersion(template.VersionLinks)
+	return fmt.Errorf("[ERROR] adding targets on multi cluster app %s, %d targets are set: %v", appID, len(multiClusterApp.Targets), addErr)
+}
+
+// multiClusterAppClient returns the management client whose calls are bounded by client_timeouts.
+// get is the Get function of the resource data or diff
+func multiClusterAppClient(get func(string) interface{}, meta interface{}) (*managementClient.Client, error) {
+	timeouts, _ := get("client_timeouts").([]interface{})
+	connect, read := expandMultiClusterAppClientTimeouts(timeouts)
+
+	return meta.(*Config).ManagementClientWithTimeouts(connect, read)
+}
+
+// multiClusterAppWithReadClient calls fn like withManagementReadClient, with clients bounded by client_timeouts
+func multiClusterAppWithReadClient(d *schema.ResourceData, meta interface{}, fn func(*managementClient.Client) error) error {
+	connect, read := expandMultiClusterAppClientTimeouts(d.Get("client_timeouts").([]interface{}))
+
+	return meta.(*Config).withManagementReadClientTimeouts(connect, read, fn)
+}
+
+// multiClusterAppTimeout returns the key resource timeout. If the resource timeouts block doesn't override the
+// default, the provider default_<key>_timeout is used when set
+func multiClusterAppTimeout(d *schema.ResourceData, meta interface{}, key string) time.Duration {
+	timeout := d.Timeout(key)
+	if timeout != multiClusterAppDefaultTimeout {
+		return timeout
+	}
+	if config, ok := meta.(*Config); ok {
+		if defaultTimeout, ok := config.DefaultTimeouts[key]; ok {
+			return defaultTimeout
+		}
+	}
+
+	return timeout
+}
+
+// multiClusterAppOperationTimeout returns the duration set on key, defaulting to update timeout
+func multiClusterAppOperationTimeout(d *schema.ResourceData, meta interface{}, key string) time.Duration {
+	if v, ok := d.Get(key).(string); ok && len(v) > 0 {
+		if timeout, err := time.ParseDuration(v); err == nil {
+			return timeout
+		}
+	}
+
+	return multiClusterAppTimeout(d, meta, schema.TimeoutUpdate)
+}
+
+func multiClusterAppWaitForActive(ctx context.Context, d *schema.ResourceData, meta interface{}, client *managementClient.Client, appID string, timeout time.Duration) error {
+	refresh := multiClusterAppWaitRefreshFunc(d, client, appID)
+	if webhookURL := d.Get("progress_webhook_url").(string); len(webhookURL) > 0 {
+		post := func(body string) error {
+			return postMultiClusterAppProgress(webhookURL, body)
+		}
+		refresh = multiClusterAppProgressWebhookRefreshFunc(refresh, d.Get("name").(string), post)
+	}
+
+	target, pending := multiClusterAppWaitStates(d)
+	refresh = multiClusterAppNoProgressRefreshFunc(refresh, appID, d.Get("wait_no_progress_polls").(int), target)
+	stateConf := newStateChangeConf(ctx, meta, pending, target, refresh, timeout)
+	_, err := waitForStateWithBudget(meta, stateConf)
+
+	return err
+}
+
+// multiClusterAppWaitStates returns the wait_states, active by default, and wait_pending_states
+func multiClusterAppWaitStates(d *schema.ResourceData) ([]string, []string) {
+	target := toArrayString(d.Get("wait_states").([]interface{}))
+	if len(target) == 0 {
+		target = []string{"active"}
+	}
+
+	return target, toArrayString(d.Get("wait_pending_states").([]interface{}))
+}
+
+// multiClusterAppHandleInFlight handles a multi cluster app rollout still transitioning before updating it. The new
+// spec supersedes the in-flight rollout if supersede_in_flight is true, otherwise the rollout is waited for, if wait is true
+func multiClusterAppHandleInFlight(ctx context.Context, d *schema.ResourceData, meta interface{}, client *managementClient.Client, multiClusterApp *managementClient.MultiClusterApp, timeout time.Duration) error {
+	if multiClusterApp.Transitioning != "yes" {
+		return nil
+	}
+
+	if d.Get("supersede_in_flight").(bool) {
+		log.Printf("[WARN] Superseding multi cluster app ID %s in-flight rollout, state: %s, transitioning: %s", multiClusterApp.ID, multiClusterApp.State, multiClusterApp.TransitioningMessage)
+		return nil
+	}
+
+	if !d.Get("wait").(bool) {
+		return nil
+	}
+
+	log.Printf("[INFO] Waiting for multi cluster app ID %s in-flight rollout before updating it", multiClusterApp.ID)
+	err := multiClusterAppWaitForActive(ctx, d, meta, client, multiClusterApp.ID, timeout)
+	if err != nil {
+		return fmt.Errorf("[ERROR] waiting %s for multi cluster app (%s) in-flight rollout: %s", timeout, multiClusterApp.ID, err)
+	}
+
+	return nil
+}
+
+// multiClusterAppWaitForConsistency waits until the multi cluster app got reflects the submitted targets and answer scopes
+func multiClusterAppWaitForConsistency(getApp func() (*managementClient.MultiClusterApp, error), submitted *managementClient.MultiClusterApp, timeout time.Duration) error {
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{"pending"},
+		Target:     []string{"consistent"},
+		Refresh:    multiClusterAppConsistencyRefreshFunc(getApp, submitted),
+		Timeout:    timeout,
+		MinTimeout: 1 * time.Second,
+	}
+	_, err := stateConf.WaitForState()
+
+	return err
+}
+
+// multiClusterAppConsistencyRefreshFunc returns a resource.StateRefreshFunc, consistent once the multi cluster app got
+// has at least the submitted targets and answer scopes. Not found multi cluster app is pending, it may not be persisted yet
+func multiClusterAppConsistencyRefreshFunc(getApp func() (*managementClient.MultiClusterApp, error), submitted *managementClient.MultiClusterApp) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		obj, err := getApp()
+		if err != nil {
+			if IsNotFound(err) {
+				return submitted, "pending", nil
+			}
+			return nil, "", err
+		}
+
+		if len(obj.Targets) < len(submitted.Targets) {
+			log.Printf("[DEBUG] Multi cluster app ID %s has %d/%d submitted targets", obj.ID, len(obj.Targets), len(submitted.Targets))
+			return obj, "pending", nil
+		}
+		scopes := map[string]bool{}
+		for _, a := range obj.Answers {
+			scopes[multiClusterAppAnswerScope(a)] = true
+		}
+		for _, a := range submitted.Answers {
+			if len(a.Values) > 0 && !scopes[multiClusterAppAnswerScope(a)] {
+				log.Printf("[DEBUG] Multi cluster app ID %s hasn't submitted %s answers yet", obj.ID, multiClusterAppAnswerScope(a))
+				return obj, "pending", nil
+			}
+		}
+
+		return obj, "consistent", nil
+	}
+}
+
+// multiClusterAppWaitForOperation waits until the multi cluster app is active after operation, if wait is true
+func multiClusterAppWaitForOperation(ctx context.Context, d *schema.ResourceData, meta interface{}, client *managementClient.Client, appID, operation string, timeout time.Duration) error {
+	if !d.Get("wait").(bool) {
+		return nil
+	}
+
+	err := multiClusterAppWaitForActive(ctx, d, meta, client, appID, timeout)
+	if err != nil {
+		return fmt.Errorf("[ERROR] waiting %s for multi cluster app (%s) %s: %s", timeout, appID, operation, err)
+	}
+
+	return nil
+}
+
+// multiClusterAppWaitForTargetsSettled waits until no target app of the multi cluster app is transitioning
+func multiClusterAppWaitForTargetsSettled(ctx context.Context, meta interface{}, client *managementClient.Client, appID string, timeout time.Duration) error {
+	getApp := func() (*managementClient.MultiClusterApp, error) {
+		return getMultiClusterApp(ctx, client, appID)
+	}
+	getTargetApp := func(target managementClient.Target) (*projectClient.App, error) {
+		return getMultiClusterAppTargetApp(meta, target)
+	}
+
+	stateConf := newStateChangeConf(ctx, meta, []string{"transitioning"}, []string{"settled"}, multiClusterAppTargetsSettledRefreshFunc(getApp, getTargetApp), timeout)
+	_, err := waitForStateWithBudget(meta, stateConf)
+	if err != nil {
+		return fmt.Errorf("[ERROR] waiting for multi cluster app (%s) targets to be settled: %s", appID, err)
+	}
+
+	return nil
+}
+
+// multiClusterAppWaitForTargetNamespaces waits until the target app namespaces of the multi cluster app are active
+func multiClusterAppWaitForTargetNamespaces(ctx context.Context, meta interface{}, client *managementClient.Client, appID string, timeout time.Duration) error {
+	multiClusterApp, err := getMultiClusterApp(ctx, client, appID)
 	if err != nil {
 		return err
 	}
+	getTargetApp := func(target managementClient.Target) (*projectClient.App, error) {
+		return getMultiClusterAppTargetApp(meta, target)
+	}
+	getNamespace := func(clusterID, namespace string) (*clusterClient.Namespace, error) {
+		client, err := meta.(*Config).ClusterClient(clusterID)
+		if err != nil {
+			return nil, err
+		}
+		return client.Namespace.ByID(namespace)
+	}
+
+	return multiClusterAppWaitForTargetNamespacesActive(ctx, meta, multiClusterApp.Targets, getTargetApp, getNamespace, timeout)
+}
 
-	d.Set("template_version", appVersion)
+// multiClusterAppWaitForTargetNamespacesActive waits until every target app namespace is active, bounded by timeout
+// for all targets. Targets without app yet, or whose project or cluster is unreachable, are skipped
+func multiClusterAppWaitForTargetNamespacesActive(ctx context.Context, meta interface{}, targets []managementClient.Target, getTargetApp func(managementClient.Target) (*projectClient.App, error), getNamespace func(string, string) (*clusterClient.Namespace, error), timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for _, t := range targets {
+		if len(t.AppID) == 0 {
+			continue
+		}
+		app, err := getTargetApp(t)
+		if err != nil {
+			log.Printf("[WARN] Skipping target %s namespace wait, getting target app %s: %v", t.ProjectID, t.AppID, err)
+			continue
+		}
+		namespace := app.TargetNamespace
+		if len(namespace) == 0 {
+			continue
+		}
+		clusterID, err := clusterIDFromProjectID(t.ProjectID)
+		if err != nil {
+			return err
+		}
+		get := func() (*clusterClient.Namespace, error) {
+			return getNamespace(clusterID, namespace)
+		}
+		refresh := multiClusterAppNamespaceActiveRefreshFunc(get)
+		_, state, err := refresh()
+		if err != nil {
+			log.Printf("[WARN] Skipping namespace %s wait on unreachable cluster %s: %v", namespace, clusterID, err)
+			continue
+		}
+		if state == "active" {
+			continue
+		}
+
+		log.Printf("[INFO] Waiting for namespace %s to be active on cluster %s", namespace, clusterID)
+		stateConf := newStateChangeConf(ctx, meta, []string{"creating"}, []string{"active"}, refresh, time.Until(deadline))
+		_, waitErr := waitForStateWithBudget(meta, stateConf)
+		if waitErr != nil {
+			return fmt.Errorf("[ERROR] waiting for namespace %s to be active on cluster %s: %s", namespace, clusterID, waitErr)
+		}
+	}
 
 	return nil
 }
 
-// multiClusterAppStateRefreshFunc returns a resource.StateRefreshFunc, used to watch a Rancher MultiClusterApp.
-func multiClusterAppStateRefreshFunc(client *managementClient.Client, appID string) resource.StateRefreshFunc {
+// multiClusterAppNamespaceActiveRefreshFunc returns a resource.StateRefreshFunc, used to watch a target app namespace
+// until it's active. Not found or not active namespaces are creating
+func multiClusterAppNamespaceActiveRefreshFunc(getNamespace func() (*clusterClient.Namespace, error)) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		obj, err := getNamespace()
+		if err != nil {
+			if IsNotFound(err) {
+				return obj, "creating", nil
+			}
+			return nil, "", err
+		}
+		if obj.State == "active" {
+			return obj, "active", nil
+		}
+
+		return obj, "creating", nil
+	}
+}
+
+// multiClusterAppWaitForCondition waits until the multi cluster app status condition set on wait_for_condition reaches its status
+func multiClusterAppWaitForCondition(ctx context.Context, d *schema.ResourceData, meta interface{}, client *managementClient.Client, appID string, timeout time.Duration) error {
+	v, ok := d.Get("wait_for_condition").([]interface{})
+	if !ok || len(v) == 0 || v[0] == nil {
+		return nil
+	}
+	in := v[0].(map[string]interface{})
+	conditionType := in["type"].(string)
+	conditionStatus := in["status"].(string)
+
+	getApp := func() (*managementClient.MultiClusterApp, error) {
+		return getMultiClusterApp(ctx, client, appID)
+	}
+	refresh := multiClusterAppConditionRefreshFunc(getApp, conditionType, conditionStatus)
+	stateConf := newStateChangeConf(ctx, meta, []string{"waiting"}, []string{"met"}, refresh, timeout)
+	_, err := waitForStateWithBudget(meta, stateConf)
+	if err != nil {
+		return fmt.Errorf("[ERROR] waiting for multi cluster app (%s) condition %s to be %s: %s", appID, conditionType, conditionStatus, err)
+	}
+
+	return nil
+}
+
+// multiClusterAppConditionRefreshFunc returns a resource.StateRefreshFunc, used to watch a Rancher MultiClusterApp
+// status condition until it reaches conditionStatus
+func multiClusterAppConditionRefreshFunc(getMultiClusterApp func() (*managementClient.MultiClusterApp, error), conditionType, conditionStatus string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		obj, err := getMultiClusterApp()
+		if err != nil {
+			return nil, "", err
+		}
+
+		if obj.Status != nil {
+			for _, c := range obj.Status.Conditions {
+				if c.Type != conditionType {
+					continue
+				}
+				if strings.EqualFold(c.Status, conditionStatus) {
+					return obj, "met", nil
+				}
+				log.Printf("[INFO] Multi cluster app ID %s condition %s is %s, waiting for %s: %s", obj.ID, conditionType, c.Status, conditionStatus, c.Message)
+			}
+		}
+
+		return obj, "waiting", nil
+	}
+}
+
+// multiClusterAppTargetsSettledRefreshFunc returns a resource.StateRefreshFunc aggregating the transitioning messages of
+// the multi cluster app target apps. It's settled once no target app is transitioning
+func multiClusterAppTargetsSettledRefreshFunc(getMultiClusterApp func() (*managementClient.MultiClusterApp, error), getTargetApp func(managementClient.Target) (*projectClient.App, error)) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		obj, err := getMultiClusterApp()
+		if err != nil {
+			return nil, "", err
+		}
+
+		messages := []string{}
+		for _, t := range obj.Targets {
+			app, err := getTargetApp(t)
+			if err != nil {
+				if IsNotFound(err) {
+					messages = append(messages, fmt.Sprintf("%s: app not found", t.ProjectID))
+					continue
+				}
+				return nil, "", err
+			}
+			if app.Transitioning == "error" {
+				return nil, "", fmt.Errorf("target %s app %s failed: %s", t.ProjectID, app.ID, app.TransitioningMessage)
+			}
+			if app.Transitioning == "yes" || len(app.TransitioningMessage) > 0 {
+				messages = append(messages, fmt.Sprintf("%s: %s", t.ProjectID, app.TransitioningMessage))
+			}
+		}
+
+		if len(messages) > 0 {
+			log.Printf("[INFO] Multi cluster app ID %s rollout, %d/%d targets settled. Transitioning: %s", obj.ID, len(obj.Targets)-len(messages), len(obj.Targets), strings.Join(messages, "; "))
+			return obj, "transitioning", nil
+		}
+
+		return obj, "settled", nil
+	}
+}
+
+func multiClusterAppWaitRefreshFunc(d *schema.ResourceData, client *managementClient.Client, appID string) resource.StateRefreshFunc {
+	if d.Get("exclude_unavailable").(bool) {
+		return multiClusterAppTargetsStateRefreshFunc(client, appID)
+	}
+	return multiClusterAppStateRefreshFunc(client, appID)
+}
+
+// multiClusterAppProgress is the multi cluster app state transition posted to progress_webhook_url
+type multiClusterAppProgress struct {
+	Name    string         `json:"name"`
+	State   string         `json:"state"`
+	Message string         `json:"message,omitempty"`
+	Targets map[string]int `json:"targets"`
+}
+
+// multiClusterAppProgressWebhookRefreshFunc wraps refresh, posting the multi cluster app state, message and target
+// count by state as JSON every time they change. Posting failures are logged and ignored
+func multiClusterAppProgressWebhookRefreshFunc(refresh resource.StateRefreshFunc, name string, post func(string) error) resource.StateRefreshFunc {
+	last := ""
+	return func() (interface{}, string, error) {
+		obj, state, err := refresh()
+		if err != nil {
+			return obj, state, err
+		}
+
+		progress := &multiClusterAppProgress{
+			Name:    name,
+			State:   state,
+			Targets: map[string]int{},
+		}
+		if mca, ok := obj.(*managementClient.MultiClusterApp); ok && mca != nil {
+			progress.Message = mca.TransitioningMessage
+			for _, t := range mca.Targets {
+				targetState := t.State
+				if len(targetState) == 0 {
+					targetState = "unknown"
+				}
+				progress.Targets[targetState]++
+			}
+		}
+		body, jsonErr := interfaceToJSON(progress)
+		if jsonErr != nil || body == last {
+			return obj, state, nil
+		}
+		last = body
+		if postErr := post(body); postErr != nil {
+			log.Printf("[WARN] Posting multi cluster app %s progress to webhook: %v", name, postErr)
+		}
+
+		return obj, state, nil
+	}
+}
+
+// postMultiClusterAppProgress posts the JSON body to the progress webhook url
+func postMultiClusterAppProgress(url, body string) error {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+	resp, err := client.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+
+	return nil
+}
+
+// multiClusterAppNoProgressRefreshFunc wraps refresh, failing once it returns the same non target state and
+// transitioning message on polls consecutive calls. Disabled if polls is 0
+func multiClusterAppNoProgressRefreshFunc(refresh resource.StateRefreshFunc, appID string, polls int, target []string) resource.StateRefreshFunc {
+	if polls <= 0 {
+		return refresh
+	}
+
+	lastState, lastMessage, count := "", "", 0
+	return func() (interface{}, string, error) {
+		obj, state, err := refresh()
+		if err != nil || containsString(target, state) {
+			return obj, state, err
+		}
+
+		message := ""
+		if mca, ok := obj.(*managementClient.MultiClusterApp); ok && mca != nil {
+			message = mca.TransitioningMessage
+		}
+		if count > 0 && state == lastState && message == lastMessage {
+			count++
+		} else {
+			lastState, lastMessage, count = state, message, 1
+		}
+		if count >= polls {
+			return obj, state, fmt.Errorf("[ERROR] multi cluster app %s no progress after %d polls, state %q message %q", appID, count, state, message)
+		}
+
+		return obj, state, nil
+	}
+}
+
+// multiClusterAppTargetsStateRefreshFunc returns a resource.StateRefreshFunc, used to watch a Rancher MultiClusterApp
+// targets, excluding those whose cluster is unavailable or provisioning
+func multiClusterAppTargetsStateRefreshFunc(client *managementClient.Client, appID string) resource.StateRefreshFunc {
+	getClusterState := func(clusterID string) (string, error) {
+		cluster, err := client.Cluster.ByID(clusterID)
+		if err != nil {
+			return "", err
+		}
+		return cluster.State, nil
+	}
+
 	return func() (interface{}, string, error) {
-		obj, err := client.MultiClusterApp.ByID(appID)
+		obj, err := getMultiClusterApp(context.Background(), client, appID)
 		if err != nil {
 			if IsNotFound(err) || IsForbidden(err) {
 				return obj, "removed", nil
@@ -301,15 +3077,60 @@ func multiClusterAppStateRefreshFunc(client *managementClient.Client, appID stri
 			return nil, "", err
 		}
 
-		return obj, obj.State, nil
+		state, excluded, err := multiClusterAppTargetsState(obj.State, obj.Targets, getClusterState)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(excluded) > 0 {
+			log.Printf("[INFO] Excluding targets %v of multi cluster app ID %s from wait, cluster is unavailable or provisioning", excluded, appID)
+		}
+
+		return obj, state, nil
+	}
+}
+
+// multiClusterAppTargetsState returns active if all targets are active, excluding those whose cluster is unavailable or provisioning
+func multiClusterAppTargetsState(state string, targets []managementClient.Target, getClusterState func(string) (string, error)) (string, []string, error) {
+	if state == "active" {
+		return state, nil, nil
+	}
+
+	excluded := []string{}
+	for _, t := range targets {
+		clusterID, err := clusterIDFromProjectID(t.ProjectID)
+		if err != nil {
+			return "", nil, err
+		}
+		clusterState, err := getClusterState(clusterID)
+		if err != nil {
+			return "", nil, err
+		}
+		if clusterState == "unavailable" || clusterState == "provisioning" {
+			excluded = append(excluded, t.ProjectID)
+			continue
+		}
+		if t.State != "active" {
+			return state, excluded, nil
+		}
 	}
+
+	return "active", excluded, nil
 }
 
 func multiClusterAppTargetToRemove(d *schema.ResourceData, mca *managementClient.MultiClusterApp) *managementClient.UpdateMultiClusterAppTargetsInput {
 	newTargets := expandTargets(d.Get("targets").([]interface{}))
+	// targets resolved from target_from_cluster_template and target_fleet_workspace are managed apart
+	templateTargets := map[string]bool{}
+	oldTemplateTargets, newTemplateTargets := d.GetChange("cluster_template_targets")
+	for _, projectID := range append(toArrayString(oldTemplateTargets.([]interface{})), toArrayString(newTemplateTargets.([]interface{}))...) {
+		templateTargets[projectID] = true
+	}
 
 	removeTarget := &managementClient.UpdateMultiClusterAppTargetsInput{}
 	for _, t := range mca.Targets {
+		if templateTargets[t.ProjectID] {
+			continue
+		}
 		found := false
 		for _, newT := range newTargets {
 			if t == newT {
@@ -337,8 +3158,54 @@ func multiClusterAppTargetToRemove(d *schema.ResourceData, mca *managementClient
 	return removeTarget
 }
 
-func multiClusterAppTargetToAdd(d *schema.ResourceData, mca *managementClient.MultiClusterApp) *managementClient.UpdateMultiClusterAppTargetsInput {
+// multiClusterAppTargetGroupsChanged returns true if the group of any kept target changed
+func multiClusterAppTargetGroupsChanged(d *schema.ResourceData) bool {
+	oldTargets, newTargets := d.GetChange("targets")
+	groups := map[string]string{}
+	for _, t := range oldTargets.([]interface{}) {
+		if in, ok := t.(map[string]interface{}); ok {
+			groups[in["project_id"].(string)], _ = in["group"].(string)
+		}
+	}
+	for _, t := range newTargets.([]interface{}) {
+		in, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _ := in["group"].(string)
+		if oldGroup, ok := groups[in["project_id"].(string)]; ok && oldGroup != group {
+			return true
+		}
+	}
+
+	return false
+}
+
+func multiClusterAppTargetScalesChanged(d *schema.ResourceData) bool {
+	oldTargets, newTargets := d.GetChange("targets")
+	oldAnnotations, _ := expandMultiClusterAppAnnotations(nil, oldTargets.([]interface{}))
+	newAnnotations, _ := expandMultiClusterAppAnnotations(nil, newTargets.([]interface{}))
+
+	return oldAnnotations[multiClusterAppTargetScaleAnnotation] != newAnnotations[multiClusterAppTargetScaleAnnotation]
+}
+
+// multiClusterAppRenderAddTargetAnswers returns the added target answers with the cluster provider token rendered for the
+// added target clusters, like create does for every target
+func multiClusterAppRenderAddTargetAnswers(addTarget *managementClient.UpdateMultiClusterAppTargetsInput, getClusterProvider func(clusterID string) (string, error)) ([]managementClient.Answer, error) {
+	targets := make([]managementClient.Target, 0, len(addTarget.Projects))
+	for _, projectID := range addTarget.Projects {
+		targets = append(targets, managementClient.Target{ProjectID: projectID})
+	}
+
+	return renderAnswersClusterProvider(addTarget.Answers, targets, getClusterProvider)
+}
+
+func multiClusterAppTargetToAdd(d *schema.ResourceData, mca *managementClient.MultiClusterApp, meta interface{}) (*managementClient.UpdateMultiClusterAppTargetsInput, error) {
 	newTargets := expandTargets(d.Get("targets").([]interface{}))
+	newAnswers, err := expandMultiClusterAppAnswers(multiClusterAppDecryptedGet(d.Get, meta))
+	if err != nil {
+		return nil, err
+	}
 
 	addTarget := &managementClient.UpdateMultiClusterAppTargetsInput{}
 	for _, newT := range newTargets {
@@ -351,13 +3218,10 @@ func multiClusterAppTargetToAdd(d *schema.ResourceData, mca *managementClient.Mu
 		}
 		if !found {
 			var a *managementClient.Answer
-			if d.HasChange("answers") {
-				newAnswers := expandAnswers(d.Get("answers").([]interface{}))
-				for _, answer := range newAnswers {
-					if newT.ProjectID == answer.ProjectID {
-						a = &answer
-						break
-					}
+			for _, answer := range newAnswers {
+				if newT.ProjectID == answer.ProjectID {
+					a = &answer
+					break
 				}
 			}
 			addTarget.Projects = append(addTarget.Projects, newT.ProjectID)
@@ -367,5 +3231,5 @@ func multiClusterAppTargetToAdd(d *schema.ResourceData, mca *managementClient.Mu
 		}
 	}
 
-	return addTarget
+	return addTarget, nil
 }
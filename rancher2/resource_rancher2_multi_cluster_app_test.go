@@ -1,13 +1,27 @@
 package rancher2
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/rancher/norman/clientbase"
+	"github.com/rancher/norman/types"
+	clusterClient "github.com/rancher/rancher/pkg/client/generated/cluster/v3"
 	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	projectClient "github.com/rancher/rancher/pkg/client/generated/project/v3"
+	"github.com/stretchr/testify/assert"
 )
 
 const (
@@ -220,3 +234,2486 @@ func testAccCheckRancher2MultiClusterAppDestroy(s *terraform.State) error {
 	}
 	return nil
 }
+
+func TestMultiClusterAppCatalogWaitTimeout(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	assert.Equal(t, d.Timeout(schema.TimeoutCreate)/multiClusterAppCatalogWaitFraction, multiClusterAppCatalogWaitTimeout(d, &Config{}))
+
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"catalog_wait_timeout": "30s",
+	})
+	assert.Equal(t, 30*time.Second, multiClusterAppCatalogWaitTimeout(d, &Config{}))
+}
+
+func TestMultiClusterAppWaitForTemplate(t *testing.T) {
+	calls := 0
+	getTemplate := func() (*managementClient.Template, error) {
+		calls++
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+
+	start := time.Now()
+	_, err := multiClusterAppWaitForTemplate(&Config{}, "cattle-global-data:test-demo", 100*time.Millisecond, getTemplate)
+	assert.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(rancher2RetriesWait*time.Second), "catalog wait should honor its own deadline")
+	assert.Equal(t, 1, calls)
+
+	getTemplate = func() (*managementClient.Template, error) {
+		return &managementClient.Template{Name: "demo"}, nil
+	}
+	template, err := multiClusterAppWaitForTemplate(&Config{}, "cattle-global-data:test-demo", 100*time.Millisecond, getTemplate)
+	assert.NoError(t, err)
+	assert.Equal(t, "demo", template.Name)
+
+	getTemplate = func() (*managementClient.Template, error) {
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start = time.Now()
+	_, err = multiClusterAppWaitForTemplate(&Config{StopContext: ctx}, "cattle-global-data:test-demo", time.Minute, getTemplate)
+	assert.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(rancher2RetriesWait*time.Second), "catalog wait should stop with the provider")
+}
+
+func TestMultiClusterAppTargetsState(t *testing.T) {
+	targets := []managementClient.Target{
+		{
+			ProjectID: "c-active:p-one",
+			State:     "active",
+		},
+		{
+			ProjectID: "c-gone:p-two",
+			State:     "deploying",
+		},
+	}
+	getClusterState := func(clusterID string) (string, error) {
+		if clusterID == "c-gone" {
+			return "unavailable", nil
+		}
+		return "active", nil
+	}
+
+	state, excluded, err := multiClusterAppTargetsState("deploying", targets, getClusterState)
+	assert.NoError(t, err)
+	assert.Equal(t, "active", state)
+	assert.Equal(t, []string{"c-gone:p-two"}, excluded)
+
+	targets[0].State = "deploying"
+	state, _, err = multiClusterAppTargetsState("deploying", targets, getClusterState)
+	assert.NoError(t, err)
+	assert.Equal(t, "deploying", state)
+}
+
+type testMultiClusterAppOperations struct {
+	managementClient.MultiClusterAppOperations
+	byID   func(id string) (*managementClient.MultiClusterApp, error)
+	create func(mca *managementClient.MultiClusterApp) (*managementClient.MultiClusterApp, error)
+	delete func(mca *managementClient.MultiClusterApp) error
+}
+
+func (o *testMultiClusterAppOperations) Create(mca *managementClient.MultiClusterApp) (*managementClient.MultiClusterApp, error) {
+	return o.create(mca)
+}
+
+func (o *testMultiClusterAppOperations) ByID(id string) (*managementClient.MultiClusterApp, error) {
+	return o.byID(id)
+}
+
+func (o *testMultiClusterAppOperations) Delete(mca *managementClient.MultiClusterApp) error {
+	return o.delete(mca)
+}
+
+type testRoleTemplateOperations struct {
+	managementClient.RoleTemplateOperations
+	roleTemplates map[string]*managementClient.RoleTemplate
+}
+
+func (o *testRoleTemplateOperations) ByID(id string) (*managementClient.RoleTemplate, error) {
+	roleTemplate, ok := o.roleTemplates[id]
+	if !ok {
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+	return roleTemplate, nil
+}
+
+type testTemplateOperations struct {
+	managementClient.TemplateOperations
+	calls int
+}
+
+func (o *testTemplateOperations) ByID(id string) (*managementClient.Template, error) {
+	o.calls++
+	return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+}
+
+type testTemplateVersionOperations struct {
+	managementClient.TemplateVersionOperations
+	templateVersions map[string]*managementClient.TemplateVersion
+	calls            int
+}
+
+func (o *testTemplateVersionOperations) ByID(id string) (*managementClient.TemplateVersion, error) {
+	o.calls++
+	templateVersion, ok := o.templateVersions[id]
+	if !ok {
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+	return templateVersion, nil
+}
+
+type testAppOperations struct {
+	projectClient.AppOperations
+	apps    map[string]*projectClient.App
+	updates map[string]map[string]interface{}
+	deleted []string
+}
+
+func (o *testAppOperations) ByID(id string) (*projectClient.App, error) {
+	app, ok := o.apps[id]
+	if !ok {
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+	return app, nil
+}
+
+func (o *testAppOperations) Update(app *projectClient.App, update interface{}) (*projectClient.App, error) {
+	o.updates[app.ID] = update.(map[string]interface{})
+	return app, nil
+}
+
+func (o *testAppOperations) Delete(app *projectClient.App) error {
+	o.deleted = append(o.deleted, app.ID)
+	delete(o.apps, app.ID)
+	return nil
+}
+
+type testClusterOperations struct {
+	managementClient.ClusterOperations
+	clusters map[string]*managementClient.Cluster
+}
+
+func (o *testClusterOperations) ByID(id string) (*managementClient.Cluster, error) {
+	cluster, ok := o.clusters[id]
+	if !ok {
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+	return cluster, nil
+}
+
+func testMultiClusterAppConfig(ops managementClient.MultiClusterAppOperations) *Config {
+	return &Config{
+		Client: Client{
+			Management: &managementClient.Client{
+				MultiClusterApp: ops,
+				RoleTemplate:    &testRoleTemplateOperations{},
+			},
+		},
+	}
+}
+
+func TestResourceRancher2MultiClusterAppReadNotFound(t *testing.T) {
+	ops := &testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	d.SetId("cattle-global-data:foo")
+
+	err := resourceRancher2MultiClusterAppRead(d, testMultiClusterAppConfig(ops))
+	assert.NoError(t, err)
+	assert.Equal(t, "", d.Id(), "Not found multi cluster app should be removed from state")
+}
+
+func TestResourceRancher2MultiClusterAppReadForbidden(t *testing.T) {
+	ops := &testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			return nil, &clientbase.APIError{StatusCode: http.StatusForbidden}
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	d.SetId("cattle-global-data:foo")
+
+	err := resourceRancher2MultiClusterAppRead(d, testMultiClusterAppConfig(ops))
+	assert.Error(t, err)
+	assert.Equal(t, "cattle-global-data:foo", d.Id(), "Forbidden multi cluster app should be kept on state")
+}
+
+func TestResourceRancher2MultiClusterAppDeleteForbidden(t *testing.T) {
+	ops := &testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			return nil, &clientbase.APIError{StatusCode: http.StatusForbidden}
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	d.SetId("cattle-global-data:foo")
+
+	err := resourceRancher2MultiClusterAppDelete(d, testMultiClusterAppConfig(ops))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "forbidden")
+	}
+	assert.Equal(t, "cattle-global-data:foo", d.Id(), "Forbidden multi cluster app should be kept on state")
+}
+
+func TestResourceRancher2MultiClusterAppReadClientTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3":
+			w.Header().Set("X-API-Schemas", server.URL+"/v3/schemas")
+			w.WriteHeader(http.StatusOK)
+		case "/v3/schemas":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"data":[{"id":"multiClusterApp","resourceMethods":["GET","PUT","DELETE"],"links":{"collection":"%s/v3/multiclusterapps"}}]}`, server.URL)
+		default:
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := &Config{
+		URL: server.URL,
+		Client: Client{
+			Management: &managementClient.Client{},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"client_timeouts": []interface{}{
+			map[string]interface{}{"connect": "1s", "read": "100ms"},
+		},
+	})
+	d.SetId("cattle-global-data:foo")
+
+	start := time.Now()
+	err := resourceRancher2MultiClusterAppRead(d, config)
+	assert.Error(t, err, "Read should fail once the slow call reaches the read timeout")
+	assert.Less(t, time.Since(start), 30*time.Second, "Read should be bounded by client_timeouts")
+	assert.Equal(t, "cattle-global-data:foo", d.Id(), "Timed out multi cluster app should be kept on state")
+}
+
+func TestGetMultiClusterAppTransientThenSuccess(t *testing.T) {
+	calls := 0
+	ops := &testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			calls++
+			if calls == 1 {
+				return nil, &clientbase.APIError{StatusCode: http.StatusServiceUnavailable}
+			}
+			return &managementClient.MultiClusterApp{Resource: types.Resource{ID: id}}, nil
+		},
+	}
+	client := &managementClient.Client{MultiClusterApp: ops}
+
+	obj, err := getMultiClusterApp(context.Background(), client, "cattle-global-data:foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "cattle-global-data:foo", obj.ID)
+	assert.Equal(t, 2, calls, "Transient error should be retried")
+
+	calls = 0
+	ops.byID = func(id string) (*managementClient.MultiClusterApp, error) {
+		calls++
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+	_, err = getMultiClusterApp(context.Background(), client, "cattle-global-data:foo")
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, 1, calls, "Not found error should not be retried")
+}
+
+func TestMultiClusterAppRoleDependencies(t *testing.T) {
+	ops := &testRoleTemplateOperations{
+		roleTemplates: map[string]*managementClient.RoleTemplate{
+			"app-operator":    {RoleTemplateIDs: []string{"view-namespaces", "project-member"}},
+			"view-namespaces": {},
+			"project-member":  {RoleTemplateIDs: []string{"view-namespaces"}},
+		},
+	}
+
+	roles, added, err := multiClusterAppRoleDependencies([]string{"app-operator", "custom-role"}, ops.ByID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app-operator", "custom-role", "view-namespaces", "project-member"}, roles)
+	assert.Equal(t, []string{"view-namespaces", "project-member"}, added)
+
+	assert.Equal(t, []string{"app-operator", "custom-role"}, keepMultiClusterAppRoles([]string{"app-operator", "custom-role"}, roles, added), "Auto included roles should not be read back")
+	assert.Equal(t, []string{"app-operator", "project-member"}, keepMultiClusterAppRoles([]string{"app-operator", "project-member"}, []string{"app-operator", "view-namespaces", "project-member"}, added), "Configured roles should be read back")
+}
+
+func TestResourceRancher2MultiClusterAppDeleteSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mca-snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "foo.json")
+
+	mca := &managementClient.MultiClusterApp{
+		Resource:          types.Resource{ID: "cattle-global-data:foo"},
+		Name:              "foo",
+		TemplateVersionID: "cattle-global-data:test-test-demo-1.23.0",
+		Roles:             []string{"project-member"},
+		Targets:           []managementClient.Target{},
+		Answers: []managementClient.Answer{
+			{Values: map[string]string{"password": "secret"}},
+		},
+	}
+	deleted := false
+	snapshotBeforeDelete := false
+	ops := &testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			if deleted {
+				return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+			}
+			return mca, nil
+		},
+		delete: func(in *managementClient.MultiClusterApp) error {
+			_, statErr := os.Stat(path)
+			snapshotBeforeDelete = statErr == nil
+			deleted = true
+			return nil
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"delete_snapshot_path": path,
+	})
+	d.SetId(mca.ID)
+
+	err = resourceRancher2MultiClusterAppDelete(d, testMultiClusterAppConfig(ops))
+	assert.NoError(t, err)
+	assert.True(t, snapshotBeforeDelete, "Snapshot should be written before deleting the multi cluster app")
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	out, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	snapshot, err := jsonToMapInterface(string(out))
+	assert.NoError(t, err)
+	assert.Equal(t, "cattle-global-data:test-test-demo-1.23.0", snapshot["templateVersionId"])
+	assert.Equal(t, []interface{}{"project-member"}, snapshot["roles"])
+}
+
+func TestMultiClusterAppExclusiveConflicts(t *testing.T) {
+	type exclusiveCase struct {
+		name     string
+		set      []string
+		changed  []string
+		expected string
+	}
+	cases := []exclusiveCase{
+		{
+			name:    "no conflicts",
+			set:     []string{"keep_target_apps"},
+			changed: []string{"answers", "targets"},
+		},
+		{
+			name:     "keep target apps waiting for namespaces removal",
+			set:      []string{"keep_target_apps", "wait_for_namespaces_removal"},
+			expected: "keep_target_apps, wait_for_namespaces_removal are mutually exclusive",
+		},
+		{
+			name:    "rollback alone",
+			changed: []string{"revision_id"},
+		},
+	}
+	for _, field := range multiClusterAppExclusiveChanges["revision_id"] {
+		cases = append(cases, exclusiveCase{
+			name:     "rollback changing " + field,
+			changed:  []string{"revision_id", field},
+			expected: "revision_id can't be changed together with " + field,
+		})
+	}
+
+	for _, tc := range cases {
+		isSet := func(key string) bool {
+			for _, v := range tc.set {
+				if v == key {
+					return true
+				}
+			}
+			return false
+		}
+		hasChange := func(key string) bool {
+			for _, v := range tc.changed {
+				if v == key {
+					return true
+				}
+			}
+			return false
+		}
+		err := multiClusterAppExclusiveConflicts(isSet, hasChange)
+		if len(tc.expected) == 0 {
+			assert.NoError(t, err, tc.name)
+			continue
+		}
+		if assert.Error(t, err, tc.name) {
+			assert.Contains(t, err.Error(), tc.expected, tc.name)
+		}
+	}
+}
+
+func TestMultiClusterAppRolesRemovalRefreshFunc(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-one"},
+			{ProjectID: "c-abcde:p-two"},
+		},
+	}
+	calls := map[string]int{}
+	getBindings := func(projectID string) ([]managementClient.ProjectRoleTemplateBinding, error) {
+		calls[projectID]++
+		bindings := []managementClient.ProjectRoleTemplateBinding{
+			{Name: "foo-project-member", RoleTemplateID: "project-member", UserPrincipalID: "system://foo"},
+			// Bindings of other principals aren't revoked by the multi cluster app
+			{Name: "foo-admin", RoleTemplateID: "cluster-admin", UserPrincipalID: "local://u-admin"},
+		}
+		// p-one is clean from the start, p-two lags revoking the removed role
+		if projectID == "c-abcde:p-two" && calls[projectID] < 3 {
+			bindings = append(bindings, managementClient.ProjectRoleTemplateBinding{
+				Name: "foo-cluster-admin", RoleTemplateID: "cluster-admin", UserPrincipalID: "system://foo",
+			})
+		}
+		return bindings, nil
+	}
+
+	refresh := multiClusterAppRolesRemovalRefreshFunc(getBindings, mca, []string{"cluster-admin"})
+	for _, expected := range []string{"removing", "removing", "removed"} {
+		_, state, err := refresh()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, state)
+	}
+}
+
+func TestResourceRancher2MultiClusterAppDeleteKeepTargetApps(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-one", AppID: "mcapp-foo"},
+		},
+	}
+	deleted := false
+	mcaOps := &testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			if deleted {
+				return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+			}
+			return mca, nil
+		},
+		delete: func(*managementClient.MultiClusterApp) error {
+			deleted = true
+			return nil
+		},
+	}
+	appOps := &testAppOperations{
+		apps: map[string]*projectClient.App{
+			"p-one:mcapp-foo": {
+				Resource: types.Resource{
+					ID: "p-one:mcapp-foo",
+				},
+				MultiClusterAppID: "cattle-global-data:foo",
+			},
+		},
+		updates: map[string]map[string]interface{}{},
+	}
+	config := testMultiClusterAppConfig(mcaOps)
+	config.Client.Project = map[string]*projectClient.Client{
+		"c-abcde:p-one": {
+			App: appOps,
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"keep_target_apps": true,
+	})
+	d.SetId(mca.ID)
+
+	err := resourceRancher2MultiClusterAppDelete(d, config)
+	assert.NoError(t, err)
+	assert.True(t, deleted, "Multi cluster app should be deleted")
+	assert.Equal(t, "", d.Id())
+	assert.Empty(t, appOps.deleted, "Target apps should survive")
+	assert.Contains(t, appOps.apps, "p-one:mcapp-foo")
+	assert.Equal(t, map[string]interface{}{"multiClusterAppId": ""}, appOps.updates["p-one:mcapp-foo"])
+}
+
+func TestResourceRancher2MultiClusterAppDeleteNoWait(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+	}
+	deleted := false
+	ops := &testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			// Never removed, waiting would time out
+			return mca, nil
+		},
+		delete: func(*managementClient.MultiClusterApp) error {
+			deleted = true
+			return nil
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"wait_for_delete": false,
+	})
+	d.SetId(mca.ID)
+
+	err := resourceRancher2MultiClusterAppDelete(d, testMultiClusterAppConfig(ops))
+	assert.NoError(t, err)
+	assert.True(t, deleted, "Multi cluster app should be deleted")
+	assert.Equal(t, "", d.Id())
+}
+
+func TestResourceRancher2MultiClusterAppDeleteUnavailableTarget(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-one", AppID: "mcapp-foo"},
+			{ProjectID: "c-fghij:p-two", AppID: "mcapp-foo"},
+		},
+	}
+	deleted := false
+	ops := &testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			if deleted {
+				return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+			}
+			return mca, nil
+		},
+		delete: func(*managementClient.MultiClusterApp) error {
+			deleted = true
+			return nil
+		},
+	}
+	config := testMultiClusterAppConfig(ops)
+	config.Client.Management.Cluster = &testClusterOperations{
+		clusters: map[string]*managementClient.Cluster{
+			"c-abcde": {State: "active"},
+			"c-fghij": {State: "unavailable"},
+		},
+	}
+	config.Client.Project = map[string]*projectClient.Client{
+		"c-abcde:p-one": {
+			App: &testAppOperations{apps: map[string]*projectClient.App{}},
+		},
+		// Target app on unavailable cluster is never removed, waiting would time out
+		"c-fghij:p-two": {
+			App: &testAppOperations{apps: map[string]*projectClient.App{
+				"p-two:mcapp-foo": {Resource: types.Resource{ID: "p-two:mcapp-foo"}, State: "removing"},
+			}},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	d.SetId(mca.ID)
+
+	err := resourceRancher2MultiClusterAppDelete(d, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "", d.Id())
+
+	state, active := multiClusterAppTargetClusterActive(config.Client.Management, "c-klmno:p-three")
+	assert.False(t, active)
+	assert.Equal(t, "removed", state)
+}
+
+func TestMultiClusterAppMissingAnswers(t *testing.T) {
+	questions := []managementClient.Question{
+		{Variable: "ingress.host", Required: true},
+		{Variable: "image.tag", Required: true, Default: "latest"},
+		{Variable: "persistence.size", Required: true, ShowIf: "persistence.enabled=true"},
+		{Variable: "replicas"},
+	}
+	targets := []managementClient.Target{
+		{ProjectID: "c-abcde:p-one"},
+		{ProjectID: "c-abcde:p-two"},
+	}
+	answers := []managementClient.Answer{
+		{
+			ProjectID: "c-abcde:p-one",
+			Values:    map[string]string{"ingress.host": "one.example.com"},
+		},
+	}
+
+	missing := multiClusterAppMissingAnswers(questions, answers, targets)
+	assert.Equal(t, []string{"ingress.host"}, missing)
+
+	answers = append(answers, managementClient.Answer{
+		Values: map[string]string{"ingress.host": "global.example.com"},
+	})
+	missing = multiClusterAppMissingAnswers(questions, answers, targets)
+	assert.Empty(t, missing)
+}
+
+func TestMultiClusterAppInvalidEnumAnswers(t *testing.T) {
+	questions := []managementClient.Question{
+		{Variable: "service.type", Type: "enum", Options: []string{"ClusterIP", "NodePort", "LoadBalancer"}},
+		{
+			Variable: "persistence.enabled",
+			Type:     "boolean",
+			Subquestions: []managementClient.SubQuestion{
+				{Variable: "persistence.accessMode", Type: "enum", Options: []string{"ReadWriteOnce", "ReadWriteMany"}},
+			},
+		},
+		{Variable: "ingress.host", Type: "string"},
+	}
+	answers := []managementClient.Answer{
+		{Values: map[string]string{"service.type": "ClusterIP", "ingress.host": "test.example.com"}},
+		{ProjectID: "c-abcde:p-one", Values: map[string]string{"service.type": "LoadBalancr"}},
+		{ClusterID: "c-abcde", Values: map[string]string{"persistence.accessMode": "ReadWriteOnce"}},
+	}
+
+	assert.Equal(t, []string{
+		`project/c-abcde:p-one service.type is "LoadBalancr", allowed values: ClusterIP, NodePort, LoadBalancer`,
+	}, multiClusterAppInvalidEnumAnswers(questions, answers))
+
+	answers[2].Values["persistence.accessMode"] = "ReadOnlyMany"
+	assert.Len(t, multiClusterAppInvalidEnumAnswers(questions, answers), 2)
+
+	// Values resolved on apply aren't checked
+	answers = []managementClient.Answer{
+		{Values: map[string]string{"service.type": answerClusterProviderToken}},
+	}
+	assert.Empty(t, multiClusterAppInvalidEnumAnswers(questions, answers))
+	assert.Empty(t, multiClusterAppInvalidEnumAnswers([]managementClient.Question{{Variable: "service.type"}}, []managementClient.Answer{{Values: map[string]string{"service.type": "foo"}}}))
+}
+
+func TestMultiClusterAppOperationTimeout(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	for _, key := range []string{"rollback_timeout", "add_targets_timeout", "remove_targets_timeout"} {
+		assert.Equal(t, d.Timeout(schema.TimeoutUpdate), multiClusterAppOperationTimeout(d, &Config{}, key), "%s should default to update timeout", key)
+	}
+
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"rollback_timeout":       "1m",
+		"add_targets_timeout":    "2m",
+		"remove_targets_timeout": "30m",
+	})
+	assert.Equal(t, 1*time.Minute, multiClusterAppOperationTimeout(d, &Config{}, "rollback_timeout"))
+	assert.Equal(t, 2*time.Minute, multiClusterAppOperationTimeout(d, &Config{}, "add_targets_timeout"))
+	assert.Equal(t, 30*time.Minute, multiClusterAppOperationTimeout(d, &Config{}, "remove_targets_timeout"))
+}
+
+func TestMultiClusterAppTimeoutProviderDefaults(t *testing.T) {
+	config := &Config{
+		DefaultTimeouts: map[string]time.Duration{
+			schema.TimeoutCreate: 30 * time.Minute,
+			schema.TimeoutDelete: 5 * time.Minute,
+		},
+	}
+	d := resourceRancher2MultiClusterApp().Data(nil)
+	assert.Equal(t, 30*time.Minute, multiClusterAppTimeout(d, config, schema.TimeoutCreate))
+	assert.Equal(t, multiClusterAppDefaultTimeout, multiClusterAppTimeout(d, config, schema.TimeoutUpdate))
+	assert.Equal(t, 5*time.Minute, multiClusterAppTimeout(d, config, schema.TimeoutDelete))
+	assert.Equal(t, 30*time.Minute/multiClusterAppCatalogWaitFraction, multiClusterAppCatalogWaitTimeout(d, config))
+	assert.Equal(t, multiClusterAppDefaultTimeout, multiClusterAppTimeout(d, &Config{}, schema.TimeoutCreate))
+
+	r := resourceRancher2MultiClusterApp()
+	r.Timeouts.Create = schema.DefaultTimeout(15 * time.Minute)
+	d = r.Data(nil)
+	assert.Equal(t, 15*time.Minute, multiClusterAppTimeout(d, config, schema.TimeoutCreate), "resource timeout should win over provider default")
+}
+
+func TestMultiClusterAppRenderAddTargetAnswers(t *testing.T) {
+	addTarget := &managementClient.UpdateMultiClusterAppTargetsInput{
+		Projects: []string{"c-eks:p-one", "c-gke:p-one"},
+		Answers: []managementClient.Answer{
+			{Values: map[string]string{"persistence.storageClass": "sc-" + answerClusterProviderToken}},
+			{ProjectID: "c-gke:p-one", Values: map[string]string{"cloud": answerClusterProviderToken}},
+		},
+	}
+	providers := map[string]string{
+		"c-eks": "eks",
+		"c-gke": "gke",
+	}
+	getClusterProvider := func(clusterID string) (string, error) {
+		provider, ok := providers[clusterID]
+		if !ok {
+			return "", fmt.Errorf("cluster %s not found", clusterID)
+		}
+		return provider, nil
+	}
+
+	answers, err := multiClusterAppRenderAddTargetAnswers(addTarget, getClusterProvider)
+	assert.NoError(t, err)
+	assert.Equal(t, []managementClient.Answer{
+		{Values: map[string]string{"persistence.storageClass": "sc-" + answerClusterProviderToken}},
+		{ProjectID: "c-gke:p-one", Values: map[string]string{"cloud": "gke"}},
+		{ClusterID: "c-eks", Values: map[string]string{"persistence.storageClass": "sc-eks"}},
+		{ClusterID: "c-gke", Values: map[string]string{"persistence.storageClass": "sc-gke"}},
+	}, answers, "Global answers should be rendered for every added target cluster")
+}
+
+func TestMultiClusterAppSetAddedTargets(t *testing.T) {
+	ops := &testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			return &managementClient.MultiClusterApp{
+				Targets: []managementClient.Target{
+					{ProjectID: "c-abcde:p-one", AppID: "mcapp-foo"},
+					{ProjectID: "c-abcde:p-two", AppID: "mcapp-foo"},
+				},
+				Annotations: map[string]string{
+					multiClusterAppTargetScaleAnnotation: `{"c-abcde:p-one":3}`,
+				},
+			}, nil
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"targets": []interface{}{
+			map[string]interface{}{"project_id": "c-abcde:p-one", "group": "canary", "priority": 1, "scale": 3},
+			map[string]interface{}{"project_id": "c-abcde:p-two", "skip_update": true, "answers": map[string]interface{}{"replicaCount": "2"}},
+			map[string]interface{}{"project_id": "c-abcde:p-three"},
+			map[string]interface{}{"project_id": "c-abcde:p-four", "enabled": false},
+		},
+	})
+	client := &managementClient.Client{MultiClusterApp: ops}
+
+	err := multiClusterAppSetAddedTargets(context.Background(), d, client, "cattle-global-data:foo", fmt.Errorf("project c-abcde:p-three not found"))
+	assert.Error(t, err)
+	targets := d.Get("targets").([]interface{})
+	if !assert.Len(t, targets, 3) {
+		return
+	}
+	assert.Equal(t, "c-abcde:p-one", d.Get("targets.0.project_id"))
+	assert.Equal(t, "canary", d.Get("targets.0.group"))
+	assert.Equal(t, 1, d.Get("targets.0.priority"))
+	assert.Equal(t, 3, d.Get("targets.0.scale"))
+	assert.Equal(t, "c-abcde:p-two", d.Get("targets.1.project_id"))
+	assert.Equal(t, true, d.Get("targets.1.skip_update"))
+	assert.Equal(t, map[string]interface{}{"replicaCount": "2"}, d.Get("targets.1.answers"))
+	assert.Equal(t, "c-abcde:p-four", d.Get("targets.2.project_id"), "Disabled target should be kept")
+	assert.Equal(t, false, d.Get("targets.2.enabled"))
+}
+
+func TestMultiClusterAppTargetAnswersDrift(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-synced", AppID: "mcapp-foo"},
+			{ProjectID: "c-abcde:p-drifted", AppID: "mcapp-foo"},
+			{ProjectID: "c-abcde:p-missing", AppID: "mcapp-foo"},
+		},
+		Answers: []managementClient.Answer{
+			{
+				Values: map[string]string{
+					"ingress.host": "test.example.com",
+					"replicaCount": "2",
+				},
+			},
+		},
+	}
+	config := testMultiClusterAppConfig(&testMultiClusterAppOperations{})
+	config.Client.Project = map[string]*projectClient.Client{
+		"c-abcde:p-synced": {
+			App: &testAppOperations{
+				apps: map[string]*projectClient.App{
+					"p-synced:mcapp-foo": {
+						Answers: map[string]string{"ingress.host": "test.example.com", "replicaCount": "2"},
+					},
+				},
+			},
+		},
+		"c-abcde:p-drifted": {
+			App: &testAppOperations{
+				apps: map[string]*projectClient.App{
+					"p-drifted:mcapp-foo": {
+						Answers: map[string]string{"ingress.host": "test.example.com", "replicaCount": "5"},
+					},
+				},
+			},
+		},
+		"c-abcde:p-missing": {
+			App: &testAppOperations{},
+		},
+	}
+	getTargetApp := func(target managementClient.Target) (*projectClient.App, error) {
+		return getMultiClusterAppTargetApp(config, target)
+	}
+
+	output, err := multiClusterAppTargetAnswersDrift(mca, getTargetApp)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"project_id":      "c-abcde:p-drifted",
+			"app_id":          "mcapp-foo",
+			"drifted_answers": []interface{}{"replicaCount"},
+		},
+	}, output)
+}
+
+func TestMultiClusterAppTargetRevisions(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-latest", AppID: "mcapp-foo"},
+			{ProjectID: "c-abcde:p-lagging", AppID: "mcapp-foo"},
+			{ProjectID: "c-abcde:p-missing", AppID: "mcapp-foo"},
+			{ProjectID: "c-abcde:p-pending"},
+		},
+	}
+	config := testMultiClusterAppConfig(&testMultiClusterAppOperations{})
+	config.Client.Project = map[string]*projectClient.Client{
+		"c-abcde:p-latest": {
+			App: &testAppOperations{
+				apps: map[string]*projectClient.App{
+					"p-latest:mcapp-foo": {AppRevisionID: "apprevision-2"},
+				},
+			},
+		},
+		"c-abcde:p-lagging": {
+			App: &testAppOperations{
+				apps: map[string]*projectClient.App{
+					"p-lagging:mcapp-foo": {AppRevisionID: "apprevision-1"},
+				},
+			},
+		},
+		"c-abcde:p-missing": {
+			App: &testAppOperations{},
+		},
+	}
+	getTargetApp := func(target managementClient.Target) (*projectClient.App, error) {
+		return getMultiClusterAppTargetApp(config, target)
+	}
+
+	output, err := multiClusterAppTargetRevisions(mca, getTargetApp)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"c-abcde:p-latest":  "apprevision-2",
+		"c-abcde:p-lagging": "apprevision-1",
+	}, output)
+}
+
+func TestResourceRancher2MultiClusterAppRename(t *testing.T) {
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	d.SetId("cattle-global-data:foo")
+
+	config["name"] = "bar"
+	diff, err := resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err)
+	if assert.NotNil(t, diff) && assert.Contains(t, diff.Attributes, "name") {
+		assert.Equal(t, "foo", diff.Attributes["name"].Old)
+		assert.Equal(t, "bar", diff.Attributes["name"].New)
+		assert.True(t, diff.Attributes["name"].RequiresNew, "Renaming multi cluster app should force replacement")
+	}
+}
+
+func TestResourceRancher2MultiClusterAppForceNewOnCatalogChange(t *testing.T) {
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	d.SetId("cattle-global-data:foo")
+
+	config["catalog_name"] = "other"
+	diff, err := resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err)
+	if assert.NotNil(t, diff) && assert.Contains(t, diff.Attributes, "catalog_name") {
+		assert.False(t, diff.Attributes["catalog_name"].RequiresNew, "Catalog change should be updated in place by default")
+	}
+
+	config["catalog_name"] = "test"
+	config["force_new_on_catalog_change"] = true
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	d.SetId("cattle-global-data:foo")
+
+	config["catalog_name"] = "other"
+	diff, err = resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err)
+	if assert.NotNil(t, diff) && assert.Contains(t, diff.Attributes, "catalog_name") {
+		assert.Equal(t, "test", diff.Attributes["catalog_name"].Old)
+		assert.Equal(t, "other", diff.Attributes["catalog_name"].New)
+		assert.True(t, diff.Attributes["catalog_name"].RequiresNew, "Catalog change should force replacement")
+	}
+}
+
+func TestResourceRancher2MultiClusterAppTargetRemovalGuard(t *testing.T) {
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"targets": []interface{}{
+			map[string]interface{}{"project_id": "c-abcde:p-one"},
+			map[string]interface{}{"project_id": "c-abcde:p-two"},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	d.SetId("cattle-global-data:foo")
+
+	config["targets"] = []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}}
+	_, err := resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	if assert.Error(t, err, "Target removal should be blocked without allow_target_removal") {
+		assert.Contains(t, err.Error(), "c-abcde:p-two")
+	}
+
+	config["allow_target_removal_projects"] = []interface{}{"c-abcde:p-two"}
+	_, err = resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err)
+
+	delete(config, "allow_target_removal_projects")
+	config["allow_target_removal"] = true
+	_, err = resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err)
+
+	delete(config, "allow_target_removal")
+	config["targets"] = []interface{}{
+		map[string]interface{}{"project_id": "c-abcde:p-one"},
+		map[string]interface{}{"project_id": "c-abcde:p-two", "enabled": false},
+	}
+	_, err = resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err, "Disabling a target shouldn't be blocked")
+}
+
+func TestResourceRancher2MultiClusterAppSkipUpdateGuard(t *testing.T) {
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"targets": []interface{}{
+			map[string]interface{}{"project_id": "c-abcde:p-one"},
+			map[string]interface{}{"project_id": "c-abcde:p-two", "skip_update": true},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	d.SetId("cattle-global-data:foo")
+
+	config["answers"] = []interface{}{map[string]interface{}{"values": map[string]interface{}{"image.tag": "2.0"}}}
+	_, err := resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err, "Answer changes should be allowed, pinning targets skipping update")
+
+	config["template_version"] = "1.24.0"
+	_, err = resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	if assert.Error(t, err, "Template version change should be blocked while a target skips update") {
+		assert.Contains(t, err.Error(), "c-abcde:p-two")
+	}
+
+	config["template_version"] = "1.23.0"
+	config["allow_target_removal"] = true
+	config["targets"] = []interface{}{
+		map[string]interface{}{"project_id": "c-abcde:p-one"},
+		map[string]interface{}{"project_id": "c-abcde:p-two", "skip_update": true, "enabled": false},
+	}
+	_, err = resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	assert.Error(t, err, "Disabling a target skipping update should be blocked")
+
+	config["targets"] = []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}}
+	_, err = resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	assert.Error(t, err, "Removing a target skipping update should be blocked")
+}
+
+func TestResourceRancher2MultiClusterAppLockVersion(t *testing.T) {
+	config := map[string]interface{}{
+		"catalog_name":  "test",
+		"name":          "foo",
+		"roles":         []interface{}{"role1"},
+		"template_name": "test-demo",
+		"lock_version":  true,
+		"targets": []interface{}{
+			map[string]interface{}{"project_id": "c-abcde:p-one"},
+		},
+	}
+	_, err := resourceRancher2MultiClusterApp().Diff(nil, terraform.NewResourceConfigRaw(config), nil)
+	assert.Error(t, err, "lock_version without template_version should be blocked")
+
+	config["template_version"] = "1.23.0"
+	_, err = resourceRancher2MultiClusterApp().Diff(nil, terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err, "lock_version with template_version should be allowed")
+}
+
+func TestMultiClusterAppMajorVersionResolution(t *testing.T) {
+	cases := []struct {
+		current  string
+		versions map[string]string
+		expected string
+	}{
+		{"1.3.0", map[string]string{"1.3.0": "", "2.0.0": "", "2.1.0": ""}, "2.1.0"},
+		{"2.0.0", map[string]string{"1.9.0": "", "2.0.0": ""}, ""},
+		{"1.3.0", map[string]string{"1.3.0": "", "1.4.0": ""}, ""},
+		{"", map[string]string{"1.3.0": "", "2.0.0": ""}, ""},
+		{"1.3.0", map[string]string{}, ""},
+	}
+
+	for _, tc := range cases {
+		output := multiClusterAppMajorVersionResolution(tc.current, &managementClient.Template{VersionLinks: tc.versions})
+		assert.Equal(t, tc.expected, output, "Unexpected resolved version from %s for %v", tc.current, tc.versions)
+	}
+}
+
+func TestMultiClusterAppWaitForTargetAppsRemoval(t *testing.T) {
+	targets := []managementClient.Target{
+		{ProjectID: "c-abcde:p-one", AppID: "p-one:mcapp-foo"},
+		{ProjectID: "c-abcde:p-two", AppID: "p-two:mcapp-foo"},
+		{ProjectID: "c-abcde:p-three", AppID: "p-three:mcapp-foo"},
+	}
+	waited := []string{}
+	wait := func(target managementClient.Target) error {
+		waited = append(waited, target.ProjectID)
+		if target.ProjectID == "c-abcde:p-two" {
+			return fmt.Errorf("timeout while waiting for state to become 'removed'")
+		}
+		return nil
+	}
+
+	stuck, err := multiClusterAppWaitForTargetAppsRemoval(context.Background(), targets, wait)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c-abcde:p-two"}, stuck, "Failed target app removal waits should be returned")
+	assert.Len(t, waited, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waited = []string{}
+	_, err = multiClusterAppWaitForTargetAppsRemoval(ctx, targets, func(target managementClient.Target) error {
+		cancel()
+		return wait(target)
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"c-abcde:p-one"}, waited, "Remaining target waits should be skipped once ctx is done")
+}
+
+func TestMultiClusterAppTargetsSettledRefreshFunc(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+		State: "active",
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-fast", AppID: "mcapp-foo"},
+			{ProjectID: "c-abcde:p-slow", AppID: "mcapp-foo"},
+		},
+	}
+	getApp := func() (*managementClient.MultiClusterApp, error) {
+		return mca, nil
+	}
+	// Targets settle after a number of refreshes
+	settleAfter := map[string]int{
+		"c-abcde:p-fast": 1,
+		"c-abcde:p-slow": 3,
+	}
+	refreshes := map[string]int{}
+	getTargetApp := func(target managementClient.Target) (*projectClient.App, error) {
+		refreshes[target.ProjectID]++
+		if refreshes[target.ProjectID] < settleAfter[target.ProjectID] {
+			return &projectClient.App{
+				Transitioning:        "yes",
+				TransitioningMessage: "upgrading",
+			}, nil
+		}
+		return &projectClient.App{Transitioning: "no"}, nil
+	}
+
+	refresh := multiClusterAppTargetsSettledRefreshFunc(getApp, getTargetApp)
+	for i, expected := range []string{"transitioning", "transitioning", "settled", "settled"} {
+		_, state, err := refresh()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, state, "Unexpected state at refresh %d", i+1)
+	}
+
+	failed := func(target managementClient.Target) (*projectClient.App, error) {
+		return &projectClient.App{
+			Transitioning:        "error",
+			TransitioningMessage: "failed to install",
+		}, nil
+	}
+	_, _, err := multiClusterAppTargetsSettledRefreshFunc(getApp, failed)()
+	assert.Error(t, err)
+}
+
+func TestResourceRancher2MultiClusterAppImportTargetNamespaces(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+		Name:                 "foo",
+		TemplateVersionID:    "cattle-global-data:test-test-demo-1.23.0",
+		RevisionHistoryLimit: 10,
+		Roles:                []string{"project-member"},
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-one", AppID: "mcapp-foo"},
+			{ProjectID: "c-abcde:p-two", AppID: "mcapp-foo"},
+			{ProjectID: "c-abcde:p-three", AppID: "mcapp-foo"},
+			{ProjectID: "c-fghij:p-four", AppID: "mcapp-foo"},
+		},
+	}
+	config := testMultiClusterAppConfig(&testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			return mca, nil
+		},
+	})
+	config.Client.Management.TemplateVersion = &testTemplateVersionOperations{
+		templateVersions: map[string]*managementClient.TemplateVersion{
+			"cattle-global-data:test-test-demo-1.23.0": {
+				ExternalID: "catalog://?catalog=test&template=test-demo&version=1.23.0",
+			},
+		},
+	}
+	config.Client.Management.Cluster = &testClusterOperations{
+		clusters: map[string]*managementClient.Cluster{
+			"c-abcde": {State: "active"},
+			"c-fghij": {State: "unavailable"},
+		},
+	}
+	config.Client.Project = map[string]*projectClient.Client{
+		// Failing target app is logged, not failing the import
+		"c-abcde:p-three": {
+			App: &testMultiClusterAppTargetAppErrorOperations{},
+		},
+		// Target on unavailable cluster is never read
+		"c-fghij:p-four": {
+			App: &testMultiClusterAppTargetAppErrorOperations{t: t},
+		},
+		"c-abcde:p-one": {
+			App: &testAppOperations{
+				apps: map[string]*projectClient.App{
+					"p-one:mcapp-foo": {TargetNamespace: "monitoring"},
+				},
+			},
+		},
+		"c-abcde:p-two": {
+			App: &testAppOperations{
+				apps: map[string]*projectClient.App{
+					"p-two:mcapp-foo": {TargetNamespace: "custom-ns"},
+				},
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	d.SetId("foo")
+	imported, err := resourceRancher2MultiClusterAppImport(d, config)
+	assert.NoError(t, err)
+	if !assert.Len(t, imported, 1) {
+		return
+	}
+	assert.Equal(t, map[string]interface{}{
+		"c-abcde:p-one": "monitoring",
+		"c-abcde:p-two": "custom-ns",
+	}, imported[0].Get("target_namespaces"))
+
+	resourceConfig := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"project-member"},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"targets": []interface{}{
+			map[string]interface{}{"project_id": "c-abcde:p-one"},
+			map[string]interface{}{"project_id": "c-abcde:p-two"},
+			map[string]interface{}{"project_id": "c-abcde:p-three"},
+			map[string]interface{}{"project_id": "c-fghij:p-four"},
+		},
+	}
+	diff, err := resourceRancher2MultiClusterApp().Diff(imported[0].State(), terraform.NewResourceConfigRaw(resourceConfig), nil)
+	assert.NoError(t, err)
+	if diff != nil {
+		for k, attr := range diff.Attributes {
+			assert.False(t, strings.HasPrefix(k, "target"), "Unexpected diff on imported %s: %#v", k, attr)
+		}
+		assert.False(t, diff.RequiresNew(), "Imported multi cluster app should not be replaced")
+	}
+}
+
+type testMultiClusterAppTargetAppErrorOperations struct {
+	projectClient.AppOperations
+	t *testing.T
+}
+
+func (o *testMultiClusterAppTargetAppErrorOperations) ByID(id string) (*projectClient.App, error) {
+	if o.t != nil {
+		o.t.Errorf("Unexpected target app %s read", id)
+	}
+	return nil, &clientbase.APIError{StatusCode: http.StatusInternalServerError}
+}
+
+func TestResourceRancher2MultiClusterAppRefreshKeepsTargetNamespaces(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+		Name:                 "foo",
+		TemplateVersionID:    "cattle-global-data:test-test-demo-1.23.0",
+		RevisionHistoryLimit: 10,
+		Roles:                []string{"project-member"},
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-one", AppID: "mcapp-foo"},
+			{ProjectID: "c-abcde:p-two", AppID: "mcapp-foo"},
+		},
+	}
+	config := testMultiClusterAppConfig(&testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			return mca, nil
+		},
+	})
+	config.Client.Management.TemplateVersion = &testTemplateVersionOperations{
+		templateVersions: map[string]*managementClient.TemplateVersion{
+			"cattle-global-data:test-test-demo-1.23.0": {
+				ExternalID: "catalog://?catalog=test&template=test-demo&version=1.23.0",
+			},
+		},
+	}
+	// Refresh never reads target apps, the namespace of p-two stays unknown until import or targets change
+	config.Client.Project = map[string]*projectClient.Client{
+		"c-abcde:p-one": {App: &testMultiClusterAppTargetAppErrorOperations{t: t}},
+		"c-abcde:p-two": {App: &testMultiClusterAppTargetAppErrorOperations{t: t}},
+	}
+
+	state := &terraform.InstanceState{
+		ID: "cattle-global-data:foo",
+		Attributes: map[string]string{
+			"target_namespaces.%":             "1",
+			"target_namespaces.c-abcde:p-one": "monitoring",
+		},
+	}
+	d := resourceRancher2MultiClusterApp().Data(state)
+	err := resourceRancher2MultiClusterAppRead(d, config)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"c-abcde:p-one": "monitoring",
+	}, d.Get("target_namespaces"))
+}
+
+func TestResourceRancher2MultiClusterAppCreatePinnedVersion(t *testing.T) {
+	var created *managementClient.MultiClusterApp
+	config := testMultiClusterAppConfig(&testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			return created, nil
+		},
+		create: func(mca *managementClient.MultiClusterApp) (*managementClient.MultiClusterApp, error) {
+			created = mca
+			created.ID = MultiClusterAppTemplatePrefix + mca.Name
+			return created, nil
+		},
+	})
+	templates := &testTemplateOperations{}
+	templateVersions := &testTemplateVersionOperations{}
+	config.Client.Management.Template = templates
+	config.Client.Management.TemplateVersion = templateVersions
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"project-member"},
+		"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"wait":             false,
+	})
+
+	err := resourceRancher2MultiClusterAppCreate(d, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "cattle-global-data:foo", d.Id())
+	assert.Equal(t, "cattle-global-data:test-test-demo-1.23.0", created.TemplateVersionID)
+	assert.Equal(t, "1.23.0", d.Get("template_version"))
+	assert.Equal(t, 0, templates.calls, "Pinned template version should not look up the template")
+	assert.Equal(t, 0, templateVersions.calls, "Pinned template version should not look up the template version")
+}
+
+func TestResourceRancher2MultiClusterAppImportAnswers(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+		Name:                 "foo",
+		TemplateVersionID:    "cattle-global-data:test-test-demo-1.23.0",
+		RevisionHistoryLimit: 10,
+		Roles:                []string{"project-member"},
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-one"},
+			{ProjectID: "c-abcde:p-two"},
+		},
+		Answers: []managementClient.Answer{
+			{ProjectID: "c-abcde:p-two", Values: map[string]string{"replicaCount": "2"}},
+			{ProjectID: "c-abcde:p-removed", Values: map[string]string{"replicaCount": "5"}},
+			{ProjectID: "c-abcde:p-one", Values: map[string]string{"replicaCount": "1"}},
+			{Values: map[string]string{"ingress.host": "test.example.com"}},
+		},
+	}
+	config := testMultiClusterAppConfig(&testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			return mca, nil
+		},
+	})
+	config.Client.Management.TemplateVersion = &testTemplateVersionOperations{
+		templateVersions: map[string]*managementClient.TemplateVersion{
+			"cattle-global-data:test-test-demo-1.23.0": {
+				ExternalID: "catalog://?catalog=test&template=test-demo&version=1.23.0",
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	d.SetId("foo")
+	imported, err := resourceRancher2MultiClusterAppImport(d, config)
+	assert.NoError(t, err)
+	if !assert.Len(t, imported, 1) {
+		return
+	}
+	assert.Equal(t, 3, imported[0].Get("answers.#"), "Answers of not targeted projects should be dropped")
+
+	resourceConfig := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"project-member"},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"targets": []interface{}{
+			map[string]interface{}{"project_id": "c-abcde:p-one"},
+			map[string]interface{}{"project_id": "c-abcde:p-two"},
+		},
+		"answers": []interface{}{
+			map[string]interface{}{"values": map[string]interface{}{"ingress.host": "test.example.com"}},
+			map[string]interface{}{"project_id": "c-abcde:p-one", "values": map[string]interface{}{"replicaCount": "1"}},
+			map[string]interface{}{"project_id": "c-abcde:p-two", "values": map[string]interface{}{"replicaCount": "2"}},
+		},
+	}
+	diff, err := resourceRancher2MultiClusterApp().Diff(imported[0].State(), terraform.NewResourceConfigRaw(resourceConfig), nil)
+	assert.NoError(t, err)
+	if diff != nil {
+		for k, attr := range diff.Attributes {
+			assert.False(t, strings.HasPrefix(k, "answers") || strings.HasPrefix(k, "targets"), "Unexpected diff on imported %s: %#v", k, attr)
+		}
+	}
+}
+
+func TestResourceRancher2MultiClusterAppReadEndpoint(t *testing.T) {
+	var created *managementClient.MultiClusterApp
+	primaryReads, replicaReads := 0, 0
+	config := testMultiClusterAppConfig(&testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			primaryReads++
+			return created, nil
+		},
+		create: func(mca *managementClient.MultiClusterApp) (*managementClient.MultiClusterApp, error) {
+			created = mca
+			created.ID = MultiClusterAppTemplatePrefix + mca.Name
+			return created, nil
+		},
+	})
+	config.Client.Management.Template = &testTemplateOperations{}
+	config.Client.Management.TemplateVersion = &testTemplateVersionOperations{}
+	config.ReadURL = "https://rancher-read.example.com"
+	replicaSynced := true
+	config.Client.ManagementRead = &managementClient.Client{
+		MultiClusterApp: &testMultiClusterAppOperations{
+			byID: func(id string) (*managementClient.MultiClusterApp, error) {
+				replicaReads++
+				if !replicaSynced {
+					return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+				}
+				return created, nil
+			},
+			create: func(mca *managementClient.MultiClusterApp) (*managementClient.MultiClusterApp, error) {
+				t.Fatal("Multi cluster app should be created on the primary endpoint")
+				return nil, nil
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"project-member"},
+		"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"wait":             false,
+	})
+
+	err := resourceRancher2MultiClusterAppCreate(d, config)
+	assert.NoError(t, err)
+	// Create consistency check and read
+	assert.Equal(t, 2, replicaReads)
+	assert.Equal(t, 0, primaryReads)
+
+	// Reads fall back to the primary endpoint if the read endpoint fails, e.g. lagging behind
+	replicaSynced = false
+	err = resourceRancher2MultiClusterAppRead(d, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "cattle-global-data:foo", d.Id())
+	assert.Equal(t, 3, replicaReads)
+	assert.Equal(t, 1, primaryReads)
+}
+
+type testSpanExporter struct {
+	exports [][]traceSpan
+}
+
+func (e *testSpanExporter) ExportSpans(spans []traceSpan) error {
+	e.exports = append(e.exports, spans)
+	return nil
+}
+
+func TestResourceRancher2MultiClusterAppCreateTracing(t *testing.T) {
+	var created *managementClient.MultiClusterApp
+	config := testMultiClusterAppConfig(&testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			return created, nil
+		},
+		create: func(mca *managementClient.MultiClusterApp) (*managementClient.MultiClusterApp, error) {
+			created = mca
+			created.ID = MultiClusterAppTemplatePrefix + mca.Name
+			return created, nil
+		},
+	})
+	config.Client.Management.Template = &testTemplateOperations{}
+	config.Client.Management.TemplateVersion = &testTemplateVersionOperations{}
+	exporter := &testSpanExporter{}
+	config.Tracer = newTracer(exporter)
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"project-member"},
+		"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"wait":             false,
+	})
+
+	err := resourceRancher2MultiClusterAppCreate(d, config)
+	assert.NoError(t, err)
+	config.Tracer.flush()
+
+	// The trace is exported once, when the create span ends
+	if !assert.Len(t, exporter.exports, 1) {
+		return
+	}
+	spans := map[string]traceSpan{}
+	for _, span := range exporter.exports[0] {
+		spans[span.Name] = span
+		assert.Equal(t, exporter.exports[0][0].TraceID, span.TraceID)
+		assert.NoError(t, span.Err)
+	}
+	root := spans["rancher2_multi_cluster_app.create"]
+	assert.Equal(t, "", root.ParentSpanID)
+	assert.Equal(t, traceSpanKindInternal, root.Kind)
+	assert.Equal(t, root.SpanID, spans["MultiClusterApp.Create"].ParentSpanID)
+	assert.Equal(t, traceSpanKindClient, spans["MultiClusterApp.Create"].Kind)
+	assert.Equal(t, root.SpanID, spans["rancher2_multi_cluster_app.read"].ParentSpanID)
+	assert.Equal(t, spans["rancher2_multi_cluster_app.read"].SpanID, spans["MultiClusterApp.ByID"].ParentSpanID)
+	assert.Empty(t, config.Tracer.pending)
+
+	// Failed calls are reported on the span status
+	request := otlpTracesRequest([]traceSpan{{TraceID: root.TraceID, SpanID: root.SpanID, Name: "MultiClusterApp.Update", Err: fmt.Errorf("conflict")}})
+	span := request["resourceSpans"].([]interface{})[0].(map[string]interface{})["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"code": traceStatusError, "message": "conflict"}, span["status"])
+	assert.NotContains(t, span, "parentSpanId")
+}
+
+func TestMultiClusterAppCatalogResolutionOrder(t *testing.T) {
+	projectIDs := []string{"c-abcde:p-one", "c-abcde:p-two"}
+
+	assert.Equal(t, []string{"cattle-global-data:test-demo"}, multiClusterAppCatalogTemplateIDs("test", "demo", projectIDs, nil))
+	assert.Equal(t, []string{
+		"p-one:test-demo",
+		"p-two:test-demo",
+		"c-abcde:test-demo",
+		"cattle-global-data:test-demo",
+	}, multiClusterAppCatalogTemplateIDs("test", "demo", projectIDs, []string{"project", "cluster", "global"}))
+	assert.Equal(t, []string{"c-abcde:test-demo"}, multiClusterAppCatalogTemplateIDs("c-abcde:test", "demo", projectIDs, []string{"project", "cluster", "global"}), "Namespaced catalog should be used as is")
+	assert.False(t, multiClusterAppCatalogResolutionAmbiguous([]string{"global"}))
+	assert.True(t, multiClusterAppCatalogResolutionAmbiguous([]string{"project", "global"}))
+
+	// Template name present on a global and a project catalog
+	templates := map[string]*managementClient.Template{
+		"cattle-global-data:test-demo": {Resource: types.Resource{ID: "cattle-global-data:test-demo"}},
+		"p-two:test-demo":              {Resource: types.Resource{ID: "p-two:test-demo"}},
+	}
+	getTemplate := func(id string) (*managementClient.Template, error) {
+		template, ok := templates[id]
+		if !ok {
+			return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+		}
+		return template, nil
+	}
+
+	template, err := multiClusterAppResolveTemplate(multiClusterAppCatalogTemplateIDs("test", "demo", projectIDs, nil), getTemplate)
+	assert.NoError(t, err)
+	assert.Equal(t, "cattle-global-data:test-demo", template.ID)
+
+	template, err = multiClusterAppResolveTemplate(multiClusterAppCatalogTemplateIDs("test", "demo", projectIDs, []string{"project", "global"}), getTemplate)
+	assert.NoError(t, err)
+	assert.Equal(t, "p-two:test-demo", template.ID)
+
+	_, err = multiClusterAppResolveTemplate(multiClusterAppCatalogTemplateIDs("test", "missing", projectIDs, []string{"project", "global"}), getTemplate)
+	assert.True(t, IsNotFound(err))
+
+	// Template version resolved on the project catalog is kept on create and read back
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"catalog_name":     "test",
+		"template_name":    "demo",
+		"template_version": "1.23.0",
+	})
+	d.Set("template_version_id", "p-two:test-demo-1.23.0")
+	assert.Equal(t, "p-two:test-demo-1.23.0", expandMultiClusterAppTemplateVersionID(d))
+	assert.Equal(t, "p-two:test-demo-1.23.0", flattenMultiClusterAppTemplateVersionID(d, "catalog://?catalog=p-two/test&type=projectCatalog&template=demo&version=1.23.0"))
+	assert.Equal(t, "test", d.Get("catalog_name"))
+}
+
+type testNamespaceOperations struct {
+	clusterClient.NamespaceOperations
+	byID func(id string) (*clusterClient.Namespace, error)
+}
+
+func (o *testNamespaceOperations) ByID(id string) (*clusterClient.Namespace, error) {
+	return o.byID(id)
+}
+
+func TestMultiClusterAppNamespaceRemovalRefreshFunc(t *testing.T) {
+	// Namespace lingers on finalizers for some refreshes after the target app is removed
+	lingering := 2
+	getNamespace := func() (*clusterClient.Namespace, error) {
+		if lingering > 0 {
+			lingering--
+			return &clusterClient.Namespace{State: "removing"}, nil
+		}
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+
+	refresh := multiClusterAppNamespaceRemovalRefreshFunc(getNamespace)
+	for i, expected := range []string{"removing", "removing", "removed"} {
+		_, state, err := refresh()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, state, "Unexpected state at refresh %d", i+1)
+	}
+
+	failed := func() (*clusterClient.Namespace, error) {
+		return nil, &clientbase.APIError{StatusCode: http.StatusInternalServerError}
+	}
+	_, _, err := multiClusterAppNamespaceRemovalRefreshFunc(failed)()
+	assert.Error(t, err)
+}
+
+func TestMultiClusterAppWaitForNamespacesRemovalSkipsTargets(t *testing.T) {
+	config := testMultiClusterAppConfig(&testMultiClusterAppOperations{})
+	config.Client.Cluster = map[string]*clusterClient.Client{
+		"c-removed": {
+			Namespace: &testNamespaceOperations{
+				byID: func(id string) (*clusterClient.Namespace, error) {
+					return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+				},
+			},
+		},
+		"c-unreachable": {
+			Namespace: &testNamespaceOperations{
+				byID: func(id string) (*clusterClient.Namespace, error) {
+					return nil, &clientbase.APIError{StatusCode: http.StatusServiceUnavailable}
+				},
+			},
+		},
+	}
+	targets := []managementClient.Target{
+		{ProjectID: "c-removed:p-one", AppID: "mcapp-foo"},
+		{ProjectID: "c-unreachable:p-one", AppID: "mcapp-foo"},
+		{ProjectID: "c-unknown:p-one", AppID: "mcapp-foo"},
+	}
+	namespaces := map[string]interface{}{
+		"c-removed:p-one":     "foo",
+		"c-unreachable:p-one": "foo",
+	}
+
+	err := multiClusterAppWaitForNamespacesRemoval(context.Background(), config, targets, namespaces, time.Second)
+	assert.NoError(t, err)
+}
+
+func TestMultiClusterAppConditionRefreshFunc(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+		State: "active",
+		Status: &managementClient.MultiClusterAppStatus{
+			Conditions: []managementClient.AppCondition{
+				{Type: "Deployed", Status: "True"},
+				{Type: "Installed", Status: "Unknown", Message: "installing"},
+			},
+		},
+	}
+	getApp := func() (*managementClient.MultiClusterApp, error) {
+		return mca, nil
+	}
+
+	refresh := multiClusterAppConditionRefreshFunc(getApp, "Installed", "True")
+	_, state, err := refresh()
+	assert.NoError(t, err)
+	assert.Equal(t, "waiting", state, "Active multi cluster app should wait for condition")
+
+	mca.Status.Conditions[1].Status = "True"
+	_, state, err = refresh()
+	assert.NoError(t, err)
+	assert.Equal(t, "met", state)
+
+	_, state, err = multiClusterAppConditionRefreshFunc(getApp, "Missing", "True")()
+	assert.NoError(t, err)
+	assert.Equal(t, "waiting", state, "Missing condition should not be met")
+}
+
+func TestMultiClusterAppTemplateDeprecation(t *testing.T) {
+	template := &managementClient.Template{Resource: types.Resource{ID: "cattle-global-data:library-foo"}}
+	templateVersion := &managementClient.TemplateVersion{Resource: types.Resource{ID: "cattle-global-data:library-foo-1.0.0"}}
+	assert.Empty(t, multiClusterAppTemplateDeprecation(template, templateVersion))
+	assert.Empty(t, multiClusterAppTemplateDeprecation(nil, nil))
+
+	template.Annotations = map[string]string{multiClusterAppDeprecatedAnnotation: "true"}
+	assert.Contains(t, multiClusterAppTemplateDeprecation(template, nil), "catalog template cattle-global-data:library-foo is deprecated")
+
+	template.Annotations = nil
+	templateVersion.Labels = map[string]string{multiClusterAppDeprecatedAnnotation: "true"}
+	assert.Contains(t, multiClusterAppTemplateDeprecation(template, templateVersion), "catalog template version cattle-global-data:library-foo-1.0.0 is deprecated")
+}
+
+func TestMultiClusterAppLookupTemplateDeprecation(t *testing.T) {
+	getTemplate := func(id string) (*managementClient.Template, error) {
+		if id != "cattle-global-data:library-foo" {
+			return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+		}
+		return &managementClient.Template{Resource: types.Resource{ID: id}}, nil
+	}
+	getTemplateVersion := func(id string) (*managementClient.TemplateVersion, error) {
+		if id != "cattle-global-data:library-foo-1.0.0" {
+			return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+		}
+		return &managementClient.TemplateVersion{
+			Resource: types.Resource{ID: id},
+			Labels:   map[string]string{multiClusterAppDeprecatedAnnotation: "true"},
+		}, nil
+	}
+
+	warning, err := multiClusterAppLookupTemplateDeprecation("cattle-global-data:library-foo", "1.0.0", getTemplate, getTemplateVersion)
+	assert.NoError(t, err)
+	assert.Contains(t, warning, "catalog template version cattle-global-data:library-foo-1.0.0 is deprecated")
+
+	warning, err = multiClusterAppLookupTemplateDeprecation("cattle-global-data:library-foo", "2.0.0", getTemplate, getTemplateVersion)
+	assert.NoError(t, err, "Not found template version should be ignored")
+	assert.Empty(t, warning)
+
+	_, err = multiClusterAppLookupTemplateDeprecation("cattle-global-data:library-bar", "", getTemplate, getTemplateVersion)
+	assert.Error(t, err)
+
+	changed := map[string]bool{}
+	hasChange := func(key string) bool {
+		return changed[key]
+	}
+	assert.True(t, multiClusterAppTemplateChanged(true, hasChange), "New multi cluster app should be looked up")
+	assert.False(t, multiClusterAppTemplateChanged(false, hasChange), "Unchanged template should not be looked up")
+	changed["template_version"] = true
+	assert.True(t, multiClusterAppTemplateChanged(false, hasChange))
+}
+
+func TestMultiClusterAppClusterTemplateTargets(t *testing.T) {
+	listClusters := func(clusterTemplateID string) ([]managementClient.Cluster, error) {
+		return []managementClient.Cluster{
+			{Resource: types.Resource{ID: "c-b"}, ClusterTemplateID: clusterTemplateID},
+			{Resource: types.Resource{ID: "c-a"}, ClusterTemplateID: clusterTemplateID},
+			{Resource: types.Resource{ID: "c-noproject"}, ClusterTemplateID: clusterTemplateID},
+			{Resource: types.Resource{ID: "c-other"}, ClusterTemplateID: "cattle-global-data:ct-other"},
+		}, nil
+	}
+	listProjects := func(name string) ([]managementClient.Project, error) {
+		return []managementClient.Project{
+			{Resource: types.Resource{ID: "c-a:p-a"}, ClusterID: "c-a", Name: name},
+			{Resource: types.Resource{ID: "c-b:p-b"}, ClusterID: "c-b", Name: name},
+			{Resource: types.Resource{ID: "c-other:p-other"}, ClusterID: "c-other", Name: name},
+			{Resource: types.Resource{ID: "c-a:p-system"}, ClusterID: "c-a", Name: "System"},
+		}, nil
+	}
+	in := map[string]interface{}{
+		"cluster_template_id": "cattle-global-data:ct-foo",
+		"project_name":        "Default",
+	}
+
+	projectIDs, err := multiClusterAppClusterTemplateTargets(in, listClusters, listProjects)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c-a:p-a", "c-b:p-b"}, projectIDs)
+
+	targets := []interface{}{map[string]interface{}{"project_id": "c-b:p-b"}}
+	remove, add := multiClusterAppClusterTemplateTargetsChange([]string{"c-a:p-a", "c-gone:p-gone"}, []string{"c-a:p-a", "c-b:p-b", "c-new:p-new"}, targets)
+	assert.Equal(t, []string{"c-gone:p-gone"}, remove)
+	assert.Equal(t, []string{"c-new:p-new"}, add)
+}
+
+func TestMultiClusterAppFleetWorkspaceTargets(t *testing.T) {
+	getWorkspace := func(name string) error {
+		if name != "fleet-default" {
+			return &clientbase.APIError{StatusCode: http.StatusNotFound}
+		}
+		return nil
+	}
+	listClusters := func(workspace string) ([]managementClient.Cluster, error) {
+		return []managementClient.Cluster{
+			{Resource: types.Resource{ID: "c-b"}, FleetWorkspaceName: workspace},
+			{Resource: types.Resource{ID: "c-a"}, FleetWorkspaceName: workspace},
+			{Resource: types.Resource{ID: "c-noproject"}, FleetWorkspaceName: workspace},
+			{Resource: types.Resource{ID: "c-other"}, FleetWorkspaceName: "fleet-other"},
+		}, nil
+	}
+	listProjects := func(name string) ([]managementClient.Project, error) {
+		return []managementClient.Project{
+			{Resource: types.Resource{ID: "c-a:p-a"}, ClusterID: "c-a", Name: name},
+			{Resource: types.Resource{ID: "c-b:p-b"}, ClusterID: "c-b", Name: name},
+			{Resource: types.Resource{ID: "c-other:p-other"}, ClusterID: "c-other", Name: name},
+		}, nil
+	}
+
+	projectIDs, err := multiClusterAppFleetWorkspaceTargets(map[string]interface{}{"name": "fleet-default", "project_name": "Default"}, getWorkspace, listClusters, listProjects)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c-a:p-a", "c-b:p-b"}, projectIDs)
+
+	_, err = multiClusterAppFleetWorkspaceTargets(map[string]interface{}{"name": "fleet-typo", "project_name": "Default"}, getWorkspace, listClusters, listProjects)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "fleet workspace fleet-typo not found")
+	}
+}
+
+func TestMultiClusterAppNoProgressRefreshFunc(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{}
+	mca.TransitioningMessage = "waiting for target apps"
+	calls := 0
+	refresh := func() (interface{}, string, error) {
+		calls++
+		return mca, "transitioning", nil
+	}
+
+	noProgress := multiClusterAppNoProgressRefreshFunc(refresh, "test", 3, []string{"active"})
+	for i := 0; i < 2; i++ {
+		_, state, err := noProgress()
+		assert.NoError(t, err)
+		assert.Equal(t, "transitioning", state)
+	}
+	_, _, err := noProgress()
+	assert.EqualError(t, err, `[ERROR] multi cluster app test no progress after 3 polls, state "transitioning" message "waiting for target apps"`)
+
+	// a message change is progress
+	noProgress = multiClusterAppNoProgressRefreshFunc(refresh, "test", 2, []string{"active"})
+	_, _, err = noProgress()
+	assert.NoError(t, err)
+	mca.TransitioningMessage = "installing on 2 of 3 targets"
+	_, _, err = noProgress()
+	assert.NoError(t, err)
+
+	// disabled if polls is 0
+	noProgress = multiClusterAppNoProgressRefreshFunc(refresh, "test", 0, []string{"active"})
+	for i := 0; i < 5; i++ {
+		_, _, err = noProgress()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 10, calls)
+}
+
+func TestMultiClusterAppCreatorUsername(t *testing.T) {
+	getUser := func(userID string) (*managementClient.User, error) {
+		switch userID {
+		case "user-abcde":
+			return &managementClient.User{Username: "admin", Name: "Default Admin"}, nil
+		case "user-fghij":
+			return &managementClient.User{Name: "External User"}, nil
+		}
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+
+	assert.Equal(t, "admin", multiClusterAppCreatorUsername("user-abcde", getUser))
+	assert.Equal(t, "External User", multiClusterAppCreatorUsername("user-fghij", getUser))
+	assert.Equal(t, "user-gone", multiClusterAppCreatorUsername("user-gone", getUser), "Creator ID should be returned if the user is gone")
+	assert.Empty(t, multiClusterAppCreatorUsername("", getUser))
+}
+
+func TestMultiClusterAppProgressWebhookRefreshFunc(t *testing.T) {
+	payloads := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		payloads = append(payloads, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mca := &managementClient.MultiClusterApp{
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-one", State: "installing"},
+			{ProjectID: "c-fghij:p-two"},
+		},
+	}
+	mca.TransitioningMessage = "installing"
+	states := []string{"transitioning", "transitioning", "active"}
+	calls := 0
+	refresh := func() (interface{}, string, error) {
+		state := states[calls]
+		calls++
+		if state == "active" {
+			mca.Targets[0].State = "active"
+			mca.Targets[1].State = "active"
+			mca.TransitioningMessage = ""
+		}
+		return mca, state, nil
+	}
+	post := func(body string) error {
+		return postMultiClusterAppProgress(server.URL, body)
+	}
+
+	webhook := multiClusterAppProgressWebhookRefreshFunc(refresh, "foo", post)
+	for range states {
+		_, _, err := webhook()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{
+		`{"name":"foo","state":"transitioning","message":"installing","targets":{"installing":1,"unknown":1}}`,
+		`{"name":"foo","state":"active","targets":{"active":2}}`,
+	}, payloads, "Progress should just be posted on state changes")
+
+	// Posting failures are not fatal
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	assert.Error(t, postMultiClusterAppProgress(failing.URL, "{}"))
+	calls = 0
+	webhook = multiClusterAppProgressWebhookRefreshFunc(refresh, "foo", func(body string) error {
+		return postMultiClusterAppProgress(failing.URL, body)
+	})
+	_, state, err := webhook()
+	assert.NoError(t, err)
+	assert.Equal(t, "transitioning", state)
+}
+
+func TestMultiClusterAppTargetQuotaShortages(t *testing.T) {
+	questions := []managementClient.Question{
+		{Variable: "resources.requests.cpu", Default: "500m"},
+		{Variable: "resources.requests.memory", Default: "256Mi"},
+		{Variable: "replicaCount", Default: "1"},
+	}
+	answers := []managementClient.Answer{
+		{Values: map[string]string{"replicaCount": "2"}},
+		{ProjectID: "c-small:p-one", Values: map[string]string{"replicaCount": "3"}},
+	}
+	projects := map[string]*managementClient.Project{
+		"c-large:p-one": {
+			ResourceQuota: &managementClient.ProjectResourceQuota{
+				Limit:     &managementClient.ResourceQuotaLimit{RequestsCPU: "4000m", RequestsMemory: "4Gi"},
+				UsedLimit: &managementClient.ResourceQuotaLimit{RequestsCPU: "1000m", RequestsMemory: "1Gi"},
+			},
+		},
+		"c-small:p-one": {
+			ResourceQuota: &managementClient.ProjectResourceQuota{
+				Limit:     &managementClient.ResourceQuotaLimit{RequestsCPU: "2000m", RequestsMemory: "1Gi"},
+				UsedLimit: &managementClient.ResourceQuotaLimit{RequestsCPU: "1000m"},
+			},
+		},
+		"c-none:p-one": {},
+	}
+	getProject := func(projectID string) (*managementClient.Project, error) {
+		return projects[projectID], nil
+	}
+
+	shortages, err := multiClusterAppTargetQuotaShortages(questions, answers, []string{"c-large:p-one", "c-small:p-one", "c-none:p-one"}, getProject)
+	assert.NoError(t, err)
+	if assert.Len(t, shortages, 1) {
+		assert.Contains(t, shortages[0], "target c-small:p-one cpu requests 1500m exceed quota headroom")
+	}
+
+	answers[1].Values["resources.requests.cpu"] = "not-a-quantity"
+	_, err = multiClusterAppTargetQuotaShortages(questions, answers, []string{"c-small:p-one"}, getProject)
+	assert.Error(t, err)
+}
+
+func TestMultiClusterAppWaitStates(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	target, pending := multiClusterAppWaitStates(d)
+	assert.Equal(t, []string{"active"}, target, "Default wait state should be active")
+	assert.Empty(t, pending, "Any pending state should be allowed by default")
+
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"wait_states":         []interface{}{"active", "deployed"},
+		"wait_pending_states": []interface{}{"installing", "updating"},
+	})
+	target, pending = multiClusterAppWaitStates(d)
+	assert.Equal(t, []string{"active", "deployed"}, target)
+	assert.Equal(t, []string{"installing", "updating"}, pending)
+}
+
+func TestResourceRancher2MultiClusterAppResourceV0(t *testing.T) {
+	// Schema version 0 is frozen, arguments added later aren't part of it
+	v0 := resourceRancher2MultiClusterAppResourceV0().CoreConfigSchema().ImpliedType()
+	assert.True(t, v0.HasAttribute("answers"))
+	assert.False(t, v0.HasAttribute("client_timeouts"))
+	targets := v0.AttributeType("targets").ElementType()
+	assert.True(t, targets.HasAttribute("project_id"))
+	assert.False(t, targets.HasAttribute("priority"))
+}
+
+func TestResourceRancher2MultiClusterAppStateUpgradeV0(t *testing.T) {
+	rawState := map[string]interface{}{
+		"name": "foo",
+		"answers": []interface{}{
+			map[string]interface{}{
+				"project_id": "c-abcde:p-one",
+				"values":     map[string]interface{}{"replicaCount": 3, "debug": true},
+			},
+			map[string]interface{}{
+				"values": map[string]interface{}{"ingress.host": "example.com"},
+			},
+			map[string]interface{}{
+				"cluster_id": "c-abcde",
+				"values":     map[string]interface{}{},
+			},
+		},
+	}
+
+	upgraded, err := resourceRancher2MultiClusterAppStateUpgradeV0(rawState, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"cluster_id": "",
+			"project_id": "c-abcde:p-one",
+			"values":     map[string]interface{}{"replicaCount": "3", "debug": "true"},
+		},
+		map[string]interface{}{
+			"cluster_id": "",
+			"project_id": "",
+			"values":     map[string]interface{}{"ingress.host": "example.com"},
+		},
+		map[string]interface{}{
+			"cluster_id": "c-abcde",
+			"project_id": "",
+		},
+	}, upgraded["answers"], "Answers order should be kept and values converted to strings")
+	assert.Equal(t, "foo", upgraded["name"])
+
+	// New answers are appended as global, cluster and project answers
+	old := upgraded["answers"].([]interface{})[:1]
+	answers := canonicalMultiClusterAppAnswers(old, []interface{}{
+		map[string]interface{}{"project_id": "c-fghij:p-two"},
+		map[string]interface{}{"cluster_id": "c-fghij"},
+		map[string]interface{}{"values": map[string]string{"a": "b"}},
+		map[string]interface{}{"project_id": "c-abcde:p-one"},
+	})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"cluster_id": "", "project_id": "c-abcde:p-one"},
+		map[string]interface{}{"cluster_id": "", "project_id": "", "values": map[string]interface{}{"a": "b"}},
+		map[string]interface{}{"cluster_id": "c-fghij", "project_id": ""},
+		map[string]interface{}{"cluster_id": "", "project_id": "c-fghij:p-two"},
+	}, answers)
+}
+
+func TestMultiClusterAppToggleTargetEnabled(t *testing.T) {
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"targets": []interface{}{
+			map[string]interface{}{"project_id": "c-abcde:p-one"},
+			map[string]interface{}{"project_id": "c-fghij:p-two", "enabled": false},
+		},
+		"answers": []interface{}{
+			map[string]interface{}{
+				"values": map[string]interface{}{"replicaCount": "1"},
+			},
+			map[string]interface{}{
+				"project_id": "c-fghij:p-two",
+				"values":     map[string]interface{}{"replicaCount": "3"},
+			},
+		},
+	}
+
+	// Disabled targets and their project answers are not deployed, but kept on state
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	targets := expandTargets(d.Get("targets").([]interface{}))
+	assert.Equal(t, []managementClient.Target{{ProjectID: "c-abcde:p-one"}}, targets)
+	answers, err := expandMultiClusterAppAnswers(d.Get)
+	assert.NoError(t, err)
+	assert.Equal(t, []managementClient.Answer{{Values: map[string]string{"replicaCount": "1"}}}, answers)
+
+	mca := &managementClient.MultiClusterApp{
+		Name:                 "foo",
+		Answers:              answers,
+		RevisionHistoryLimit: 10,
+		Targets:              []managementClient.Target{{ProjectID: "c-abcde:p-one", AppID: "p-one:mcapp-foo"}},
+	}
+	err = flattenMultiClusterApp(d, mca, testMultiClusterAppExternalID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, d.Get("targets.#"))
+	assert.Equal(t, "c-fghij:p-two", d.Get("targets.1.project_id"))
+	assert.Equal(t, false, d.Get("targets.1.enabled"))
+	assert.Equal(t, true, d.Get("targets.0.enabled"))
+	assert.Equal(t, "c-fghij:p-two", d.Get("answers.1.project_id"))
+	assert.Equal(t, "3", d.Get("answers.1.values.replicaCount"))
+
+	// Re-enabled targets are added with their answers
+	config["targets"].([]interface{})[1].(map[string]interface{})["enabled"] = true
+	mca.Targets[0].AppID = ""
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	addTarget, err := multiClusterAppTargetToAdd(d, mca, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c-fghij:p-two"}, addTarget.Projects)
+	if assert.Len(t, addTarget.Answers, 1) {
+		assert.Equal(t, map[string]string{"replicaCount": "3"}, addTarget.Answers[0].Values)
+	}
+}
+
+func TestMultiClusterAppAnswersToPatch(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Answers: []managementClient.Answer{
+			{Values: map[string]string{"replicaCount": "1"}},
+			{ProjectID: "c-abcde:p-one", Values: map[string]string{"image": "nginx"}},
+			{ProjectID: "c-fghij:p-two", Values: map[string]string{"image": "nginx"}},
+		},
+	}
+
+	patch, removed := multiClusterAppAnswersToPatch(mca.Answers, mca)
+	assert.Empty(t, patch)
+	assert.Empty(t, removed)
+
+	answers := []managementClient.Answer{
+		{Values: map[string]string{"replicaCount": "1"}},
+		{ProjectID: "c-abcde:p-one", Values: map[string]string{"image": "nginx:alpine"}},
+		{ClusterID: "c-klmno", Values: map[string]string{"debug": "true"}},
+		{ProjectID: "c-pqrst:p-three"},
+	}
+	patch, removed = multiClusterAppAnswersToPatch(answers, mca)
+	assert.Equal(t, []string{"project/c-abcde:p-one", "cluster/c-klmno"}, multiClusterAppAnswerScopes(patch))
+	assert.Equal(t, []string{"project/c-fghij:p-two"}, removed)
+}
+
+func TestResourceRancher2MultiClusterAppImportGitOpsOwned(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{
+			ID: "cattle-global-data:foo",
+		},
+		Name:                 "foo",
+		TemplateVersionID:    "cattle-global-data:test-test-demo-1.23.0",
+		RevisionHistoryLimit: 10,
+		Roles:                []string{"project-member"},
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-one"},
+		},
+		Annotations: map[string]string{"objectset.rio.cattle.io/id": "fleet-mca"},
+		Labels:      map[string]string{"objectset.rio.cattle.io/hash": "abcdef"},
+	}
+	// Update and Delete operations aren't faked, writing would panic
+	config := testMultiClusterAppConfig(&testMultiClusterAppOperations{
+		byID: func(id string) (*managementClient.MultiClusterApp, error) {
+			return mca, nil
+		},
+	})
+	config.Client.Management.TemplateVersion = &testTemplateVersionOperations{
+		templateVersions: map[string]*managementClient.TemplateVersion{
+			"cattle-global-data:test-test-demo-1.23.0": {
+				ExternalID: "catalog://?catalog=test&template=test-demo&version=1.23.0",
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	d.SetId("foo")
+	imported, err := resourceRancher2MultiClusterAppImport(d, config)
+	assert.NoError(t, err)
+	if !assert.Len(t, imported, 1) {
+		return
+	}
+	assert.Equal(t, "fleet", imported[0].Get("gitops_owner"))
+	assert.Equal(t, true, imported[0].Get("read_only"), "Fleet owned multi cluster app should be imported read only")
+
+	resourceConfig := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"project-member"},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+	}
+	_, err = resourceRancher2MultiClusterApp().Diff(imported[0].State(), terraform.NewResourceConfigRaw(resourceConfig), nil)
+	if assert.Error(t, err, "Managing a fleet owned multi cluster app should require read_only") {
+		assert.Contains(t, err.Error(), "fleet")
+	}
+	resourceConfig["read_only"] = true
+	_, err = resourceRancher2MultiClusterApp().Diff(imported[0].State(), terraform.NewResourceConfigRaw(resourceConfig), nil)
+	assert.NoError(t, err)
+
+	resourceConfig["template_version"] = "1.24.0"
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), resourceConfig)
+	d.SetId(mca.ID)
+	err = resourceRancher2MultiClusterAppUpdate(d, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.23.0", d.Get("template_version"), "Read only multi cluster app shouldn't be updated")
+
+	err = resourceRancher2MultiClusterAppDelete(d, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "", d.Id())
+}
+
+func TestMultiClusterAppTemplateVersionNotFound(t *testing.T) {
+	catalogs := map[string]*managementClient.Catalog{
+		"test": {Name: "test"},
+	}
+	templates := map[string]*managementClient.Template{
+		"cattle-global-data:test-demo": {
+			Resource: types.Resource{ID: "cattle-global-data:test-demo"},
+			VersionLinks: map[string]string{
+				"1.10.0": "link",
+				"1.9.1":  "link",
+				"1.23.0": "link",
+			},
+		},
+	}
+	getCatalog := func(id string) (*managementClient.Catalog, error) {
+		catalog, ok := catalogs[id]
+		if !ok {
+			return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+		}
+		return catalog, nil
+	}
+	getTemplate := func(id string) (*managementClient.Template, error) {
+		template, ok := templates[id]
+		if !ok {
+			return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+		}
+		return template, nil
+	}
+
+	err := multiClusterAppTemplateVersionNotFound("test", "demo", "1.24.0", getCatalog, getTemplate)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "version 1.24.0 not found on catalog test template demo")
+		assert.Contains(t, err.Error(), "available versions: 1.9.1, 1.10.0, 1.23.0")
+	}
+
+	err = multiClusterAppTemplateVersionNotFound("test", "missing", "1.24.0", getCatalog, getTemplate)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "template missing not found on catalog test")
+	}
+
+	// Catalog may be created on apply
+	assert.NoError(t, multiClusterAppTemplateVersionNotFound("other", "demo", "1.24.0", getCatalog, getTemplate))
+}
+
+func TestMultiClusterAppWaitForTargetNamespacesActive(t *testing.T) {
+	// Lagging namespace is not found, then activating, then active
+	calls := 0
+	refresh := multiClusterAppNamespaceActiveRefreshFunc(func() (*clusterClient.Namespace, error) {
+		calls++
+		switch calls {
+		case 1:
+			return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+		case 2:
+			return &clusterClient.Namespace{State: "activating"}, nil
+		}
+		return &clusterClient.Namespace{State: "active"}, nil
+	})
+	for _, expected := range []string{"creating", "creating", "active"} {
+		_, state, err := refresh()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, state)
+	}
+
+	targets := []managementClient.Target{
+		{ProjectID: "c-abcde:p-one", AppID: "mcapp-foo"},
+		{ProjectID: "c-fghij:p-two", AppID: "mcapp-foo"},
+		{ProjectID: "c-klmno:p-three", AppID: "mcapp-foo"},
+		{ProjectID: "c-pqrst:p-four"},
+	}
+	getTargetApp := func(target managementClient.Target) (*projectClient.App, error) {
+		if target.ProjectID == "c-klmno:p-three" {
+			return nil, &clientbase.APIError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return &projectClient.App{TargetNamespace: "foo"}, nil
+	}
+	namespaceCalls := map[string]int{}
+	getNamespace := func(clusterID, namespace string) (*clusterClient.Namespace, error) {
+		namespaceCalls[clusterID]++
+		assert.Equal(t, "foo", namespace)
+		if clusterID == "c-fghij" {
+			return nil, fmt.Errorf("cluster c-fghij is unreachable")
+		}
+		if namespaceCalls[clusterID] < 2 {
+			return &clusterClient.Namespace{State: "activating"}, nil
+		}
+		return &clusterClient.Namespace{State: "active"}, nil
+	}
+
+	err := multiClusterAppWaitForTargetNamespacesActive(context.Background(), nil, targets, getTargetApp, getNamespace, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"c-abcde": 2, "c-fghij": 1}, namespaceCalls, "Unreachable targets should be skipped")
+}
+
+func TestResourceRancher2MultiClusterAppReportUpgradeStrategy(t *testing.T) {
+	assert.Equal(t, "Rancher default, all targets upgraded at once", multiClusterAppEffectiveUpgradeStrategy(nil))
+	assert.Equal(t, "rolling update, batch size 5, interval 30s", multiClusterAppEffectiveUpgradeStrategy([]interface{}{
+		map[string]interface{}{
+			"rolling_update": []interface{}{map[string]interface{}{"batch_size": 5, "interval": 30}},
+		},
+	}))
+
+	var out strings.Builder
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"upgrade_strategy": []interface{}{
+			map[string]interface{}{
+				"rolling_update": []interface{}{map[string]interface{}{"batch_size": 5}},
+			},
+		},
+	}
+	_, err := resourceRancher2MultiClusterApp().Diff(nil, terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "multi cluster app foo effective upgrade strategy: rolling update, batch size 5, interval Rancher default")
+}
+
+func TestMultiClusterAppTargetStages(t *testing.T) {
+	targets := []interface{}{
+		map[string]interface{}{"project_id": "c-abcde:p-one", "priority": 10},
+		map[string]interface{}{"project_id": "c-abcde:p-two"},
+		map[string]interface{}{"project_id": "c-fghij:p-three", "priority": 5},
+		map[string]interface{}{"project_id": "c-fghij:p-four", "priority": 10},
+		map[string]interface{}{"project_id": "c-klmno:p-five", "priority": 5},
+	}
+	addTarget := &managementClient.UpdateMultiClusterAppTargetsInput{
+		Projects: []string{"c-abcde:p-one", "c-abcde:p-two", "c-fghij:p-three", "c-fghij:p-four", "c-klmno:p-five"},
+		Answers: []managementClient.Answer{
+			{ProjectID: "c-abcde:p-one", Values: map[string]string{"replicaCount": "1"}},
+			{ProjectID: "c-klmno:p-five", Values: map[string]string{"replicaCount": "5"}},
+		},
+	}
+
+	stages := multiClusterAppTargetStages(addTarget, multiClusterAppTargetPriorities(targets))
+	if !assert.Len(t, stages, 3) {
+		return
+	}
+	added := [][]string{}
+	for _, stage := range stages {
+		added = append(added, stage.Projects)
+	}
+	assert.Equal(t, [][]string{
+		{"c-abcde:p-two"},
+		{"c-fghij:p-three", "c-klmno:p-five"},
+		{"c-abcde:p-one", "c-fghij:p-four"},
+	}, added, "Targets should be added by priority, the lowest first, keeping config order on ties")
+	assert.Empty(t, stages[0].Answers)
+	assert.Equal(t, []managementClient.Answer{addTarget.Answers[1]}, stages[1].Answers)
+	assert.Equal(t, []managementClient.Answer{addTarget.Answers[0]}, stages[2].Answers)
+}
+
+func TestMultiClusterAppHandleInFlight(t *testing.T) {
+	byIDCalls := 0
+	client := &managementClient.Client{
+		MultiClusterApp: &testMultiClusterAppOperations{
+			byID: func(id string) (*managementClient.MultiClusterApp, error) {
+				byIDCalls++
+				return &managementClient.MultiClusterApp{Resource: types.Resource{ID: id}, State: "active"}, nil
+			},
+		},
+	}
+	mca := &managementClient.MultiClusterApp{
+		Resource:             types.Resource{ID: "cattle-global-data:foo"},
+		State:                "updating",
+		Transitioning:        "yes",
+		TransitioningMessage: "upgrading target apps",
+	}
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{"supersede_in_flight": true})
+	err := multiClusterAppHandleInFlight(context.Background(), d, nil, client, mca, 10*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, byIDCalls, "Superseded in-flight rollout shouldn't be waited for")
+
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{"wait": false})
+	err = multiClusterAppHandleInFlight(context.Background(), d, nil, client, mca, 10*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, byIDCalls, "In-flight rollout shouldn't be waited for if wait is false")
+
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	err = multiClusterAppHandleInFlight(context.Background(), d, nil, client, mca, 10*time.Second)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, byIDCalls, "In-flight rollout should be waited for before updating")
+
+	byIDCalls = 0
+	mca.Transitioning = "no"
+	err = multiClusterAppHandleInFlight(context.Background(), d, nil, client, mca, 10*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, byIDCalls, "Settled multi cluster app shouldn't be waited for")
+}
+
+func TestMultiClusterAppTargetHelmRevisions(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Resource: types.Resource{ID: "cattle-global-data:foo"},
+		Targets: []managementClient.Target{
+			{ProjectID: "c-one:p-one", AppID: "foo-one"},
+			{ProjectID: "c-two:p-two", AppID: "foo-two"},
+			{ProjectID: "c-down:p-down", AppID: "foo-down"},
+			{ProjectID: "c-gone:p-gone", AppID: "foo-gone"},
+			{ProjectID: "c-helm2:p-helm2", AppID: "foo-helm2"},
+		},
+	}
+	clusterActive := func(projectID string) bool {
+		return projectID != "c-down:p-down"
+	}
+	getTargetApp := func(target managementClient.Target) (*projectClient.App, error) {
+		switch target.ProjectID {
+		case "c-gone:p-gone":
+			return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+		case "c-helm2:p-helm2":
+			return &projectClient.App{Name: target.AppID, TargetNamespace: "foo", HelmVersion: catalogHelmV2}, nil
+		}
+		return &projectClient.App{Name: target.AppID, TargetNamespace: "foo", HelmVersion: catalogHelmV3}, nil
+	}
+	release := func(name, version, status string) projectClient.NamespacedSecret {
+		return projectClient.NamespacedSecret{
+			Labels: map[string]string{"owner": "helm", "name": name, "version": version, "status": status},
+		}
+	}
+	releases := map[string][]projectClient.NamespacedSecret{
+		"c-one:p-one": {
+			release("foo-one", "1", "superseded"),
+			release("foo-one", "2", "deployed"),
+			release("foo-one", "3", "failed"),
+			release("other", "7", "deployed"),
+		},
+		"c-two:p-two": {
+			release("foo-two", "1", "pending-install"),
+		},
+	}
+	getReleases := func(target managementClient.Target, namespace string) ([]projectClient.NamespacedSecret, error) {
+		if target.ProjectID == "c-down:p-down" || target.ProjectID == "c-helm2:p-helm2" {
+			t.Errorf("Helm releases shouldn't be read for target %s", target.ProjectID)
+		}
+		return releases[target.ProjectID], nil
+	}
+
+	revisions, err := multiClusterAppTargetHelmRevisions(mca, clusterActive, getTargetApp, getReleases)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"c-one:p-one": "2",
+		"c-two:p-two": "1",
+	}, revisions)
+}
+
+func TestMultiClusterAppWaitForConsistency(t *testing.T) {
+	submitted := &managementClient.MultiClusterApp{
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-one"},
+			{ProjectID: "c-abcde:p-two"},
+		},
+		Answers: []managementClient.Answer{
+			{Values: map[string]string{"replicaCount": "1"}},
+			{ProjectID: "c-abcde:p-two", Values: map[string]string{"replicaCount": "2"}},
+		},
+	}
+	lagging := []*managementClient.MultiClusterApp{
+		{
+			Resource: types.Resource{ID: "cattle-global-data:foo"},
+			Targets:  submitted.Targets[:1],
+			Answers:  submitted.Answers[:1],
+		},
+		{
+			Resource: types.Resource{ID: "cattle-global-data:foo"},
+			Targets:  submitted.Targets,
+			Answers:  submitted.Answers[:1],
+		},
+	}
+	gets := 0
+	getApp := func() (*managementClient.MultiClusterApp, error) {
+		gets++
+		if gets == 1 {
+			return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+		}
+		if gets-2 < len(lagging) {
+			return lagging[gets-2], nil
+		}
+		return &managementClient.MultiClusterApp{
+			Resource: types.Resource{ID: "cattle-global-data:foo"},
+			Targets:  submitted.Targets,
+			Answers:  submitted.Answers,
+		}, nil
+	}
+
+	err := multiClusterAppWaitForConsistency(getApp, submitted, 30*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, gets, "Multi cluster app should be got until it reflects the submitted targets and answers")
+
+	err = multiClusterAppWaitForConsistency(func() (*managementClient.MultiClusterApp, error) {
+		return lagging[0], nil
+	}, submitted, 2*time.Second)
+	assert.Error(t, err, "Consistency wait should be bounded by timeout")
+}
+
+func TestResourceRancher2MultiClusterAppAnswersDiffSummary(t *testing.T) {
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+		"answers": []interface{}{
+			map[string]interface{}{"values": map[string]interface{}{"replicaCount": "1", "image.tag": "v1"}},
+			map[string]interface{}{"project_id": "c-abcde:p-one", "values": map[string]interface{}{"ingress.host": "one.example.com"}},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	d.SetId("cattle-global-data:foo")
+
+	config["answers"] = []interface{}{
+		map[string]interface{}{"values": map[string]interface{}{"replicaCount": "1", "image.tag": "v2", "debug": "true"}},
+		map[string]interface{}{"cluster_id": "c-abcde", "values": map[string]interface{}{"region": "eu"}},
+	}
+	diff, err := resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, diff) {
+		return
+	}
+	summary := []string{}
+	for i := 0; ; i++ {
+		attr, ok := diff.Attributes[fmt.Sprintf("answers_diff_summary.%d", i)]
+		if !ok {
+			break
+		}
+		summary = append(summary, attr.New)
+	}
+	assert.Equal(t, []string{
+		"+ cluster/c-abcde region",
+		"+ global debug",
+		"~ global image.tag",
+		"- project/c-abcde:p-one ingress.host",
+	}, summary)
+
+	diff, err = resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"template_name":    "test-demo",
+		"template_version": "1.24.0",
+		"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+		"answers":          d.Get("answers"),
+	}), nil)
+	assert.NoError(t, err)
+	if diff != nil {
+		_, ok := diff.Attributes["answers_diff_summary.#"]
+		assert.False(t, ok, "Answers diff summary shouldn't be set if answers don't change")
+	}
+}
@@ -159,6 +159,13 @@ func resourceRancher2NodePoolDelete(d *schema.ResourceData, meta interface{}) er
 		return err
 	}
 
+	if v, ok := d.Get("drain_input").([]interface{}); ok && len(v) > 0 {
+		err = nodePoolDrainNodes(client, id, expandClusterRKEConfigNodeDrainInput(v), d.Timeout(schema.TimeoutDelete))
+		if err != nil {
+			return err
+		}
+	}
+
 	err = client.NodePool.Delete(nodePool)
 	if err != nil {
 		return fmt.Errorf("Error removing Node Pool: %s", err)
@@ -199,3 +206,67 @@ func nodePoolStateRefreshFunc(client *managementClient.Client, nodePoolID string
 		return obj, obj.State, nil
 	}
 }
+
+// nodePoolDrainNodes drains every active or cordoned node of the node pool, waiting up to timeout for all of them to be
+// drained. Nodes on other states, e.g. unavailable or error, can't be drained and are skipped
+func nodePoolDrainNodes(client *managementClient.Client, nodePoolID string, input *managementClient.NodeDrainInput, timeout time.Duration) error {
+	filters := map[string]interface{}{"nodePoolId": nodePoolID}
+	collection, err := client.Node.List(NewListOpts(filters))
+	if err != nil {
+		return fmt.Errorf("[ERROR] listing node pool (%s) nodes: %s", nodePoolID, err)
+	}
+
+	draining := []string{}
+	for i := range collection.Data {
+		node := &collection.Data[i]
+		if node.State != "active" && node.State != "cordoned" {
+			if node.State != "drained" {
+				log.Printf("[WARN] Skipping drain of node %s of node pool %s on %s state", node.ID, nodePoolID, node.State)
+			}
+			continue
+		}
+		log.Printf("[INFO] Draining node %s of node pool %s", node.ID, nodePoolID)
+		err = client.Node.ActionDrain(node, input)
+		if err != nil {
+			return fmt.Errorf("[ERROR] draining node %s of node pool (%s): %s", node.ID, nodePoolID, err)
+		}
+		draining = append(draining, node.ID)
+	}
+
+	start := time.Now()
+	for _, nodeID := range draining {
+		stateConf := &resource.StateChangeConf{
+			Pending:    []string{"active", "cordoned", "draining"},
+			Target:     []string{"drained", "removed"},
+			Refresh:    nodeDrainStateRefreshFunc(client, nodeID),
+			Timeout:    timeout - time.Since(start),
+			Delay:      1 * time.Second,
+			MinTimeout: 3 * time.Second,
+		}
+		_, waitErr := stateConf.WaitForState()
+		if waitErr != nil {
+			return fmt.Errorf("[ERROR] waiting for node %s of node pool (%s) to be drained: %s", nodeID, nodePoolID, waitErr)
+		}
+	}
+
+	return nil
+}
+
+// nodeDrainStateRefreshFunc returns a resource.StateRefreshFunc, used to watch a Rancher Node drain.
+func nodeDrainStateRefreshFunc(client *managementClient.Client, nodeID string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		obj, err := client.Node.ByID(nodeID)
+		if err != nil {
+			if IsNotFound(err) || IsForbidden(err) {
+				return obj, "removed", nil
+			}
+			return nil, "", err
+		}
+
+		if obj.Transitioning == "error" {
+			return nil, "", fmt.Errorf("%s", obj.TransitioningMessage)
+		}
+
+		return obj, obj.State, nil
+	}
+}
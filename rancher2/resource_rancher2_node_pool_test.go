@@ -2,12 +2,17 @@ package rancher2
 
 import (
 	"fmt"
+	"net/http"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/rancher/norman/clientbase"
+	"github.com/rancher/norman/types"
 	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"github.com/stretchr/testify/assert"
 )
 
 const (
@@ -218,3 +223,60 @@ func testAccCheckRancher2NodePoolDestroy(s *terraform.State) error {
 	}
 	return nil
 }
+
+type testNodeDrainOperations struct {
+	managementClient.NodeOperations
+	nodes   map[string]*managementClient.Node
+	drained []string
+}
+
+func (o *testNodeDrainOperations) List(opts *types.ListOpts) (*managementClient.NodeCollection, error) {
+	collection := &managementClient.NodeCollection{}
+	for _, node := range o.nodes {
+		if node.NodePoolID == opts.Filters["nodePoolId"] {
+			collection.Data = append(collection.Data, *node)
+		}
+	}
+	return collection, nil
+}
+
+func (o *testNodeDrainOperations) ActionDrain(node *managementClient.Node, input *managementClient.NodeDrainInput) error {
+	o.drained = append(o.drained, node.ID)
+	o.nodes[node.ID].State = "drained"
+	return nil
+}
+
+func (o *testNodeDrainOperations) ByID(id string) (*managementClient.Node, error) {
+	node, ok := o.nodes[id]
+	if !ok {
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+	return node, nil
+}
+
+func TestNodePoolDrainNodes(t *testing.T) {
+	nodes := &testNodeDrainOperations{
+		nodes: map[string]*managementClient.Node{
+			"m-1": {Resource: types.Resource{ID: "m-1"}, NodePoolID: "c-test:np-test", State: "active"},
+			"m-2": {Resource: types.Resource{ID: "m-2"}, NodePoolID: "c-test:np-test", State: "drained"},
+			"m-3": {Resource: types.Resource{ID: "m-3"}, NodePoolID: "c-test:np-other", State: "active"},
+			"m-4": {Resource: types.Resource{ID: "m-4"}, NodePoolID: "c-test:np-test", State: "cordoned"},
+			"m-5": {Resource: types.Resource{ID: "m-5"}, NodePoolID: "c-test:np-test", State: "unavailable"},
+			"m-6": {Resource: types.Resource{ID: "m-6"}, NodePoolID: "c-test:np-test", State: "error"},
+		},
+	}
+	client := &managementClient.Client{Node: nodes}
+
+	err := nodePoolDrainNodes(client, "c-test:np-test", expandClusterRKEConfigNodeDrainInput(nil), 10*time.Second)
+	assert.NoError(t, err)
+	sort.Strings(nodes.drained)
+	assert.Equal(t, []string{"m-1", "m-4"}, nodes.drained, "Just active and cordoned nodes should be drained")
+	assert.Equal(t, "active", nodes.nodes["m-3"].State)
+
+	nodes.nodes["m-3"].Transitioning = "error"
+	nodes.nodes["m-3"].TransitioningMessage = "cannot evict pod"
+	_, _, err = nodeDrainStateRefreshFunc(client, "m-3")()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cannot evict pod")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"github.com/stretchr/testify/assert"
 )
 
 const (
@@ -201,3 +202,15 @@ func testAccCheckRancher2ProjectAlertRuleDestroy(s *terraform.State) error {
 	}
 	return nil
 }
+
+func TestProjectAlertRuleImportID(t *testing.T) {
+	cases := map[string]string{
+		"p-yyyyy:zzzzz":                 "p-yyyyy:zzzzz",
+		"c-xxxxx:p-yyyyy:zzzzz":         "p-yyyyy:zzzzz",
+		"c-xxxxx:p-yyyyy:p-yyyyy:zzzzz": "p-yyyyy:zzzzz",
+		"c-xxxxx:p-yyyyy:p-other:zzzzz": "c-xxxxx:p-yyyyy:p-other:zzzzz",
+	}
+	for id, expected := range cases {
+		assert.Equal(t, expected, projectAlertRuleImportID(id), "Import ID %s", id)
+	}
+}
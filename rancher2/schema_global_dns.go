@@ -38,6 +38,12 @@ func GlobalDNSFields() map[string]*schema.Schema {
 			Optional: true,
 			Default:  300,
 		},
+		"endpoints": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Endpoints the fqdn resolves to, updated by Rancher as the multi cluster app targets or projects change",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
 	}
 	for k, v := range commonAnnotationLabelFields() {
 		s[k] = v
@@ -39,3 +39,61 @@ func memberFields() map[string]*schema.Schema {
 
 	return s
 }
+
+func memberEffectivePermissionFieldsV0() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"access_type": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Member access type",
+		},
+		"principal_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Member user or group principal id",
+		},
+		"roles": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Roles the member is able to exercise through the app",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"verbs": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Verbs allowed to the member on the app",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+	}
+
+	return s
+}
+
+func memberEffectivePermissionFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"access_type": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Member access type, as set on Rancher. Role template rules aren't resolved",
+		},
+		"principal_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Member user or group principal id",
+		},
+		"roles": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Roles the member is able to exercise through the app",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+	}
+
+	return s
+}
@@ -1,24 +1,172 @@
 package rancher2
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+const (
+	multiClusterAppAnswersMergeStrategyMerge     = "merge"
+	multiClusterAppAnswersMergeStrategyOverwrite = "overwrite"
+	helmReleaseSecretType                        = "helm.sh/release.v1"
 )
 
 //Schemas
 
-func multiClusterAppFields() map[string]*schema.Schema {
+func multiClusterAppGroupAnswerFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"group": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"values": {
+			Type:     schema.TypeMap,
+			Optional: true,
+		},
+	}
+
+	return s
+}
+
+func multiClusterAppClusterTemplateTargetFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"cluster_template_id": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"project_name": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "Default",
+		},
+	}
+
+	return s
+}
+
+func multiClusterAppFleetWorkspaceTargetFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"project_name": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "Default",
+		},
+	}
+
+	return s
+}
+
+func multiClusterAppClientTimeoutsFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"connect": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validatePositiveDuration,
+			Description:  "Timeout establishing the connection to Rancher, TLS handshake included. Golang duration format, ex: \"10s\"",
+		},
+		"read": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validatePositiveDuration,
+			Description:  "Timeout of a single Rancher API call, reading the response included. Golang duration format, ex: \"30s\"",
+		},
+	}
+
+	return s
+}
+
+func multiClusterAppWaitConditionFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"type": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"status": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "True",
+			ValidateFunc: validation.StringInSlice([]string{"True", "False", "Unknown"}, true),
+		},
+	}
+
+	return s
+}
+
+func multiClusterAppTargetAnswersDriftFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"project_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"app_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"drifted_answers": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+	}
+
+	return s
+}
+
+func multiClusterAppTargetAppFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"project_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"app_id": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"state": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+
+	return s
+}
+
+func multiClusterAppAnswerFields() map[string]*schema.Schema {
+	s := answerFields()
+	s["values"].DiffSuppressFunc = suppressMultiClusterAppAnswerValue
+
+	return s
+}
+
+func multiClusterAppFieldsV0() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"catalog_name": {
 			Type:        schema.TypeString,
 			Required:    true,
 			Description: "Multi cluster app catalog name",
 		},
+		// Multi cluster app name is used as object name by Rancher and can't be updated
 		"name": {
 			Type:        schema.TypeString,
 			Required:    true,
 			ForceNew:    true,
 			Description: "Multi cluster app name",
 		},
+		"rollback_timeout": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validatePositiveDuration,
+			Description:  "Timeout waiting for the multi cluster app to be active after a rollback. Golang duration format, ex: \"10m\". Default: update timeout",
+		},
 		"roles": {
 			Type:        schema.TypeList,
 			Required:    true,
@@ -28,27 +176,595 @@ func multiClusterAppFields() map[string]*schema.Schema {
 			},
 		},
 		"targets": {
+			Type:         schema.TypeList,
+			Optional:     true,
+			AtLeastOneOf: []string{"targets", "target_from_cluster_template"},
+			Description:  "Multi cluster app targets",
+			Elem: &schema.Resource{
+				Schema: targetFieldsV0(),
+			},
+		},
+		"target_app_names": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Multi cluster app target app names by project ID",
+		},
+		"target_answers_drift": {
 			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Multi cluster app answers that differ on live target apps, if read_target_answers is true",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppTargetAnswersDriftFields(),
+			},
+		},
+		"target_from_cluster_template": {
+			Type:         schema.TypeList,
+			MaxItems:     1,
+			Optional:     true,
+			AtLeastOneOf: []string{"targets", "target_from_cluster_template"},
+			Description:  "Target the project_name project of every cluster provisioned from the cluster template. Resolved on plan and apply",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppClusterTemplateTargetFields(),
+			},
+		},
+		"target_health_states": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Multi cluster app target health states by project ID",
+		},
+		"target_namespaces": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Multi cluster app target app namespaces by project ID",
+		},
+		"template_name": {
+			Type:        schema.TypeString,
 			Required:    true,
-			Description: "Multi cluster app targets",
+			Description: "Multi cluster app template name",
+		},
+		"add_targets_timeout": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validatePositiveDuration,
+			Description:  "Timeout waiting for the multi cluster app to be active after adding targets. Golang duration format, ex: \"10m\". Default: update timeout",
+		},
+		"answers": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			Description: "Multi cluster app answers",
+			Elem: &schema.Resource{
+				Schema: answerFields(),
+			},
+		},
+		"answers_object": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Description:      "Multi cluster app global answers as nested YAML or JSON object, merged with answers as dotted keys",
+			ValidateFunc:     validateAnswersObject,
+			DiffSuppressFunc: suppressAppDiff,
+		},
+		"cluster_template_targets": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Project IDs targeted from target_from_cluster_template",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"catalog_wait_timeout": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validatePositiveDuration,
+			Description:  "Timeout waiting for the catalog template if wait_for_catalog is true. Golang duration format, ex: \"2m\". Default: a quarter of the create timeout",
+		},
+		"effective_answers": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Multi cluster app answers applied on every target, deep merging global, cluster and project answers",
+			Elem: &schema.Resource{
+				Schema: answerFields(),
+			},
+		},
+		"creator_username": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Username of the multi cluster app creator. Creator ID if the user can't be resolved",
+		},
+		"debug_answers": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Log at plan the resolved value and source scope of every answer key on every target",
+		},
+		"delete_snapshot_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Local file path to write the multi cluster app spec to before deleting it",
+		},
+		"exclude_unavailable": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Exclude targets whose cluster is unavailable or provisioning when waiting for the multi cluster app to be active",
+		},
+		"force_new_on_catalog_change": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Replace the multi cluster app if catalog_name changes, instead of updating it in place to a chart from another catalog",
+		},
+		"group_answers": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Multi cluster app answers for targets by group",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppGroupAnswerFields(),
+			},
+		},
+		"keep_target_apps": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Keep target apps running on multi cluster app deletion. Kept apps are no longer managed",
+		},
+		"members": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Multi cluster app members",
+			Elem: &schema.Resource{
+				Schema: memberFields(),
+			},
+		},
+		"member_effective_permissions": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Multi cluster app members effective permissions",
+			Elem: &schema.Resource{
+				Schema: memberEffectivePermissionFieldsV0(),
+			},
+		},
+		"progress_webhook_url": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			Description:  "URL to POST the multi cluster app state transitions to as JSON while waiting for it to be active",
+		},
+		"remove_targets_timeout": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validatePositiveDuration,
+			Description:  "Timeout waiting for the multi cluster app to be active after removing targets. Golang duration format, ex: \"10m\". Default: update timeout",
+		},
+		"read_target_answers": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Read live answers from every target app to report answers drift. It requires an API call per target on every refresh",
+		},
+		"read_template_metadata": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Read the template metadata, like categories, on refresh",
+		},
+		"resolve_role_dependencies": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Auto include on roles the role templates the roles depend on",
+		},
+		"role_dependencies": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Roles auto included as dependencies of the multi cluster app roles",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"revision_history_limit": {
+			Type:        schema.TypeInt,
+			Optional:    true,
+			Default:     10,
+			Description: "Multi cluster app revision history limit",
+		},
+		"revision_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "Multi cluster app revision name",
+		},
+		"sensitive_answers": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Computed:    true,
+			Sensitive:   true,
+			Description: "Multi cluster app global sensitive answers. Encrypted on state if provider answers_encryption_passphrase is set",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"template_categories": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Multi cluster app template categories, if read_template_metadata is true",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"template_version": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			StateFunc:   normalizeMultiClusterAppTemplateVersion,
+			Description: "Multi cluster app template version",
+		},
+		"template_version_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Multi cluster app template version ID",
+		},
+		"trim_answers": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Trim leading and trailing whitespaces from answer values, ignoring whitespace only differences",
+		},
+		"upgrade_strategy": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			MaxItems:    1,
+			Description: "Multi cluster app upgrade strategy",
+			Elem: &schema.Resource{
+				Schema: upgradeStrategyFields(),
+			},
+		},
+		"validate_target_quota": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "",
+			ValidateFunc: validation.StringInSlice([]string{"", "warn", "error"}, false),
+			Description:  "Check the project resource quota of added targets has headroom for the chart resource requests. warn logs a warning, error fails. Disabled if empty",
+		},
+		"wait": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Wait until multi cluster app is active",
+		},
+		"wait_states": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Multi cluster app states considered ready when waiting on create and update. Default: [\"active\"]",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"wait_pending_states": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Multi cluster app states allowed while waiting on create and update, any other not ready state fails. Any state is allowed if empty",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"wait_no_progress_polls": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			Description:  "Fail waiting for the multi cluster app to be active after this number of consecutive polls reporting the same non active state and message. Disabled if 0",
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		"wait_for_targets_settled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Wait until no target app is transitioning after the multi cluster app is active, if wait is true",
+		},
+		"wait_for_condition": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "Wait until the multi cluster app status condition reaches the status, if wait is true",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppWaitConditionFields(),
+			},
+		},
+		"wait_for_namespaces_removal": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Wait until target app namespaces are removed after deleting the multi cluster app",
+		},
+		"wait_for_roles_removal": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Wait until removed roles are revoked on at least one target after updating roles",
+		},
+		"wait_for_catalog": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Wait until the catalog template is available to resolve the template version",
+		},
+	}
+
+	for k, v := range commonAnnotationLabelFields() {
+		s[k] = v
+	}
+
+	return s
+}
+
+func multiClusterAppFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"catalog_name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Multi cluster app catalog name",
+		},
+		"catalog_scope": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      catalogScopeGlobal,
+			ValidateFunc: validation.StringInSlice(catalogScopes, false),
+			Description:  "Multi cluster app catalog scope. cluster and project scopes require catalog_cluster_id or catalog_project_id",
+		},
+		"catalog_cluster_id": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"catalog_project_id"},
+			Description:   "Multi cluster app catalog cluster ID, for cluster scoped catalog",
+		},
+		"catalog_project_id": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"catalog_cluster_id"},
+			Description:   "Multi cluster app catalog project ID, for project scoped catalog",
+		},
+		// Multi cluster app name is used as object name by Rancher and can't be updated
+		"name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+			Description: "Multi cluster app name",
+		},
+		"rollback_timeout": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validatePositiveDuration,
+			Description:  "Timeout waiting for the multi cluster app to be active after a rollback. Golang duration format, ex: \"10m\". Default: update timeout",
+		},
+		"roles": {
+			Type:        schema.TypeList,
+			Required:    true,
+			Description: "Multi cluster app roles",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"targets": {
+			Type:         schema.TypeList,
+			Optional:     true,
+			AtLeastOneOf: []string{"targets", "target_from_cluster_template", "target_fleet_workspace"},
+			Description:  "Multi cluster app targets",
 			Elem: &schema.Resource{
 				Schema: targetFields(),
 			},
 		},
+		"targets_in_sync": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "Whether every multi cluster app target state equals the multi cluster app state",
+		},
+		"target_app_names": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Multi cluster app target app names by project ID",
+		},
+		"target_apps": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Multi cluster app target apps, as project ID, app ID and state of every target",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppTargetAppFields(),
+			},
+		},
+		"target_answers_drift": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Multi cluster app answers that differ on live target apps, if read_target_answers is true",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppTargetAnswersDriftFields(),
+			},
+		},
+		"target_from_cluster_template": {
+			Type:         schema.TypeList,
+			MaxItems:     1,
+			Optional:     true,
+			AtLeastOneOf: []string{"targets", "target_from_cluster_template", "target_fleet_workspace"},
+			Description:  "Target the project_name project of every cluster provisioned from the cluster template. Resolved on plan and apply",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppClusterTemplateTargetFields(),
+			},
+		},
+		"target_fleet_workspace": {
+			Type:         schema.TypeList,
+			MaxItems:     1,
+			Optional:     true,
+			AtLeastOneOf: []string{"targets", "target_from_cluster_template", "target_fleet_workspace"},
+			Description:  "Target the project_name project of every cluster on the fleet workspace. Resolved on plan and apply",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppFleetWorkspaceTargetFields(),
+			},
+		},
+		"target_health_states": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Multi cluster app target health states by project ID",
+		},
+		"target_namespaces": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Multi cluster app target app namespaces by project ID",
+		},
+		"target_helm_revisions": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Multi cluster app target app deployed Helm release revisions by project ID, if read_target_helm_revisions is true",
+		},
+		"target_revisions": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Multi cluster app target app applied revision IDs by project ID, if read_target_revisions is true",
+		},
 		"template_name": {
 			Type:        schema.TypeString,
 			Required:    true,
 			Description: "Multi cluster app template name",
 		},
+		"add_targets_timeout": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validatePositiveDuration,
+			Description:  "Timeout waiting for the multi cluster app to be active after adding targets. Golang duration format, ex: \"10m\". Default: update timeout",
+		},
+		"allow_target_removal": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Allow removing targets, uninstalling their apps. If false, plan fails if targets not listed on allow_target_removal_projects are removed",
+		},
+		"allow_target_removal_projects": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Project IDs of the targets allowed to be removed if allow_target_removal is false",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
 		"answers": {
 			Type:        schema.TypeList,
 			Optional:    true,
 			Computed:    true,
 			Description: "Multi cluster app answers",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppAnswerFields(),
+			},
+		},
+		"answers_diff_summary": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Answer keys added (+), removed (-) or changed (~) by scope on the last answers change",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"answers_merge_strategy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      multiClusterAppAnswersMergeStrategyOverwrite,
+			ValidateFunc: validation.StringInSlice([]string{multiClusterAppAnswersMergeStrategyOverwrite, multiClusterAppAnswersMergeStrategyMerge}, false),
+			Description:  "Multi cluster app answers update strategy. overwrite replaces the answers, merge deep merges them over the Rancher answers",
+		},
+		"answers_object": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Description:      "Multi cluster app global answers as nested YAML or JSON object, merged with answers as dotted keys",
+			ValidateFunc:     validateAnswersObject,
+			DiffSuppressFunc: suppressAppDiff,
+		},
+		"cluster_template_targets": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Project IDs targeted from target_from_cluster_template and target_fleet_workspace",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"catalog_wait_timeout": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validatePositiveDuration,
+			Description:  "Timeout waiting for the catalog template if wait_for_catalog is true. Golang duration format, ex: \"2m\". Default: a quarter of the create timeout",
+		},
+		"client_timeouts": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "HTTP timeouts of the multi cluster app Rancher API calls, apart from the operation timeouts",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppClientTimeoutsFields(),
+			},
+		},
+		"effective_answers": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Multi cluster app answers applied on every target, deep merging global, cluster and project answers",
 			Elem: &schema.Resource{
 				Schema: answerFields(),
 			},
 		},
+		"creator_username": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Username of the multi cluster app creator. Creator ID if the user can't be resolved",
+		},
+		"debug_answers": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Log at plan the resolved value and source scope of every answer key on every target",
+		},
+		"delete_snapshot_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Local file path to write the multi cluster app spec to before deleting it",
+		},
+		"exclude_unavailable": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Exclude targets whose cluster is unavailable or provisioning when waiting for the multi cluster app to be active",
+		},
+		"force_new_on_catalog_change": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Replace the multi cluster app if catalog_name changes, instead of updating it in place to a chart from another catalog",
+		},
+		"gitops_owner": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "GitOps controller owning the multi cluster app, e.g. fleet, detected from its labels and annotations",
+		},
+		"group_answers": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Multi cluster app answers for targets by group",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppGroupAnswerFields(),
+			},
+		},
+		"lock_version": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Require template_version to be set explicitly, instead of resolving the latest template version",
+		},
+		"keep_target_apps": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Keep target apps running on multi cluster app deletion. Kept apps are no longer managed",
+		},
+		"last_transition_time": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Timestamp of the multi cluster app most recent status condition transition, RFC3339 format",
+		},
 		"members": {
 			Type:        schema.TypeList,
 			Optional:    true,
@@ -57,6 +773,70 @@ func multiClusterAppFields() map[string]*schema.Schema {
 				Schema: memberFields(),
 			},
 		},
+		"member_effective_permissions": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Multi cluster app members effective permissions",
+			Elem: &schema.Resource{
+				Schema: memberEffectivePermissionFields(),
+			},
+		},
+		"progress_webhook_url": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			Description:  "URL to POST the multi cluster app state transitions to as JSON while waiting for it to be active",
+		},
+		"remove_targets_timeout": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validatePositiveDuration,
+			Description:  "Timeout waiting for the multi cluster app to be active after removing targets. Golang duration format, ex: \"10m\". Default: update timeout",
+		},
+		"read_only": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Just read the multi cluster app, never writing it. Required if gitops_owner is set",
+		},
+		"read_target_answers": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Read live answers from every target app to report answers drift. It requires an API call per target on every refresh",
+		},
+		"read_target_helm_revisions": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Read the deployed Helm release revision of every target app deployed by Helm 3. It requires API calls per target on every refresh",
+		},
+		"read_target_revisions": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Read the applied revision from every target app. It requires an API call per target on every refresh",
+		},
+		"read_template_metadata": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Read the template metadata, like categories, on refresh",
+		},
+		"resolve_role_dependencies": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Auto include on roles the role templates the roles depend on",
+		},
+		"role_dependencies": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Roles auto included as dependencies of the multi cluster app roles",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
 		"revision_history_limit": {
 			Type:        schema.TypeInt,
 			Optional:    true,
@@ -69,17 +849,47 @@ func multiClusterAppFields() map[string]*schema.Schema {
 			Computed:    true,
 			Description: "Multi cluster app revision name",
 		},
+		"sensitive_answers": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Computed:    true,
+			Sensitive:   true,
+			Description: "Multi cluster app global sensitive answers. Encrypted on state if provider answers_encryption_passphrase is set",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"template_categories": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Multi cluster app template categories, if read_template_metadata is true",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
 		"template_version": {
 			Type:        schema.TypeString,
 			Optional:    true,
 			Computed:    true,
+			StateFunc:   normalizeMultiClusterAppTemplateVersion,
 			Description: "Multi cluster app template version",
 		},
+		"template_deprecation": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Deprecation warning of the multi cluster app catalog template or template version, empty if not deprecated",
+		},
 		"template_version_id": {
 			Type:        schema.TypeString,
 			Computed:    true,
 			Description: "Multi cluster app template version ID",
 		},
+		"trim_answers": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Trim leading and trailing whitespaces from answer values, ignoring whitespace only differences",
+		},
 		"upgrade_strategy": {
 			Type:        schema.TypeList,
 			Optional:    true,
@@ -90,12 +900,93 @@ func multiClusterAppFields() map[string]*schema.Schema {
 				Schema: upgradeStrategyFields(),
 			},
 		},
+		"validate_target_quota": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "",
+			ValidateFunc: validation.StringInSlice([]string{"", "warn", "error"}, false),
+			Description:  "Check the project resource quota of added targets has headroom for the chart resource requests. warn logs a warning, error fails. Disabled if empty",
+		},
 		"wait": {
 			Type:        schema.TypeBool,
 			Optional:    true,
 			Default:     true,
 			Description: "Wait until multi cluster app is active",
 		},
+		"wait_states": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Multi cluster app states considered ready when waiting on create and update. Default: [\"active\"]",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"wait_pending_states": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Multi cluster app states allowed while waiting on create and update, any other not ready state fails. Any state is allowed if empty",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"wait_no_progress_polls": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			Description:  "Fail waiting for the multi cluster app to be active after this number of consecutive polls reporting the same non active state and message. Disabled if 0",
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		"wait_for_targets_settled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Wait until no target app is transitioning after the multi cluster app is active, if wait is true",
+		},
+		"wait_for_condition": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "Wait until the multi cluster app status condition reaches the status, if wait is true",
+			Elem: &schema.Resource{
+				Schema: multiClusterAppWaitConditionFields(),
+			},
+		},
+		"supersede_in_flight": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Update multi cluster app immediately if a previous rollout is still transitioning, instead of waiting for it",
+		},
+		"wait_for_delete": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Wait until multi cluster app and target apps are removed on delete",
+		},
+		"wait_for_target_namespaces": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Wait until target app namespaces are active once the multi cluster app is active, if wait is true",
+		},
+		"wait_for_namespaces_removal": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Wait until target app namespaces are removed after deleting the multi cluster app",
+		},
+		"wait_for_roles_removal": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Wait until removed roles are revoked on every target after updating roles",
+		},
+		"wait_for_catalog": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Wait until the catalog template is available to resolve the template version",
+		},
 	}
 
 	for k, v := range commonAnnotationLabelFields() {
@@ -104,3 +995,39 @@ func multiClusterAppFields() map[string]*schema.Schema {
 
 	return s
 }
+
+func validateAnswersObject(val interface{}, key string) (warns []string, errs []error) {
+	v, ok := val.(string)
+	if !ok || len(v) == 0 {
+		return
+	}
+	_, err := answersObjectValues(v)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%q is not valid: %v", key, err))
+	}
+	return
+}
+
+// suppressMultiClusterAppAnswerValue suppresses answer value diffs between values normalized as equal by
+// normalizeMultiClusterAppAnswerValue. Leading and trailing whitespaces are just ignored if trim_answers is true
+func suppressMultiClusterAppAnswerValue(k, old, new string, d *schema.ResourceData) bool {
+	if trim, ok := d.Get("trim_answers").(bool); ok && trim {
+		old = strings.TrimSpace(old)
+		new = strings.TrimSpace(new)
+	}
+
+	return normalizeMultiClusterAppAnswerValue(old) == normalizeMultiClusterAppAnswerValue(new)
+}
+
+// normalizeMultiClusterAppAnswerValue returns boolean like values, "true", "false", "1" and "0" in any case, as
+// "true" or "false". Any other value is returned as is
+func normalizeMultiClusterAppAnswerValue(v string) string {
+	switch strings.ToLower(v) {
+	case "true", "1":
+		return "true"
+	case "false", "0":
+		return "false"
+	}
+
+	return v
+}
@@ -44,6 +44,15 @@ func nodePoolFields() map[string]*schema.Schema {
 			Type:     schema.TypeBool,
 			Optional: true,
 		},
+		"drain_input": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Drain options used to drain the node pool nodes before deleting the node pool",
+			Elem: &schema.Resource{
+				Schema: clusterRKEConfigNodeDrainInputFields(),
+			},
+		},
 		"control_plane": {
 			Type:     schema.TypeBool,
 			Optional: true,
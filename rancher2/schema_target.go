@@ -1,11 +1,58 @@
 package rancher2
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 )
 
 //Schemas
 
+func targetFieldsV0() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"project_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Project ID for target",
+		},
+		"group": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Group for target, used to apply group answers",
+		},
+		"scale": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ValidateFunc: validation.IntAtLeast(0),
+			Description:  "Intended scale hint for target, written as multi cluster app annotation. Not set if 0",
+		},
+		"answers_yaml": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateTargetAnswersYAML,
+			Description:  "Path to a YAML values file for target, merged over global and group answers",
+		},
+		"app_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "App ID for target",
+		},
+		"health_state": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "App health state for target",
+		},
+		"state": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "App state for target",
+		},
+	}
+
+	return s
+}
+
 func targetFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"project_id": {
@@ -13,6 +60,47 @@ func targetFields() map[string]*schema.Schema {
 			Required:    true,
 			Description: "Project ID for target",
 		},
+		"group": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Group for target, used to apply group answers",
+		},
+		"scale": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ValidateFunc: validation.IntAtLeast(0),
+			Description:  "Intended scale hint for target, written as multi cluster app annotation. Not set if 0",
+		},
+		"answers_yaml": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateTargetAnswersYAML,
+			Description:  "Path to a YAML values file for target, merged over global and group answers",
+		},
+		"answers": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Answer values for target, merged on its project answer over group and answers_yaml values. Keys can't be also set on the target project answers",
+		},
+		"priority": {
+			Type:        schema.TypeInt,
+			Optional:    true,
+			Default:     0,
+			Description: "Priority for target, added targets are deployed in stages by priority, the lowest first",
+		},
+		"enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Deploy the multi cluster app on target. Disabled targets are removed, keeping their configuration",
+		},
+		"skip_update": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Keep target on its current answers while other targets are updated. Template version changes and rollbacks aren't allowed while set, nor removing or disabling the target",
+		},
 		"app_id": {
 			Type:        schema.TypeString,
 			Computed:    true,
@@ -32,3 +120,15 @@ func targetFields() map[string]*schema.Schema {
 
 	return s
 }
+
+func validateTargetAnswersYAML(val interface{}, key string) (warns []string, errs []error) {
+	v, ok := val.(string)
+	if !ok || len(v) == 0 {
+		return
+	}
+	_, err := targetAnswersYAMLValues(v)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%q is not valid: %v", key, err))
+	}
+	return
+}
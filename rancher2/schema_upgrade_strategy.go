@@ -11,14 +11,14 @@ func rollingUpdateFields() map[string]*schema.Schema {
 		"batch_size": {
 			Type:        schema.TypeInt,
 			Optional:    true,
-			Default:     1,
-			Description: "Rolling update batch size",
+			Computed:    true,
+			Description: "Rolling update batch size, number of targets upgraded at once. Rancher default if not set",
 		},
 		"interval": {
 			Type:        schema.TypeInt,
 			Optional:    true,
-			Default:     1,
-			Description: "Rolling update interval",
+			Computed:    true,
+			Description: "Rolling update interval, seconds between batches. Rancher default if not set",
 		},
 	}
 
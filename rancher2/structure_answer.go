@@ -1,7 +1,26 @@
 package rancher2
 
 import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
 	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	answerReferenceSuffix          = "}"
+	answerConfigMapReferencePrefix = "${configmap://"
+	answerClusterProviderToken     = "${cluster.provider}"
+	answersEncryptedPrefix         = "enc:v2:"
+	answersEncryptedPrefixV1       = "enc:v1:"
+	answersEncryptionSaltSize      = 16
+	answersEncryptionScryptN       = 1 << 15
+	answersEncryptionScryptR       = 8
+	answersEncryptionScryptP       = 1
 )
 
 // Flatteners
@@ -105,3 +124,634 @@ func expandAnswer(p []interface{}) *managementClient.Answer {
 
 	return obj
 }
+
+// Effective answers
+
+// mergeAnswerValues deep merges answer values by their dotted key path, later values taking precedence.
+// Setting a key replaces its whole subtree, and setting a nested key replaces any value set on its parents. Keys are
+// applied sorted, so within the same values a nested key always replaces its parent
+func mergeAnswerValues(values ...map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, v := range values {
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := v[key]
+			for k := range out {
+				if strings.HasPrefix(k, key+".") || strings.HasPrefix(key, k+".") {
+					delete(out, k)
+				}
+			}
+			out[key] = value
+		}
+	}
+
+	return out
+}
+
+// effectiveAnswerValues returns the answer values applied on project ID, merging global, cluster and project scoped answers
+func effectiveAnswerValues(answers []managementClient.Answer, projectID string) map[string]string {
+	clusterID, _ := clusterIDFromProjectID(projectID)
+	var global, cluster, project map[string]string
+	for _, a := range answers {
+		switch {
+		case len(a.ProjectID) > 0:
+			if a.ProjectID == projectID {
+				project = a.Values
+			}
+		case len(a.ClusterID) > 0:
+			if a.ClusterID == clusterID {
+				cluster = a.Values
+			}
+		default:
+			global = a.Values
+		}
+	}
+
+	return mergeAnswerValues(global, cluster, project)
+}
+
+// effectiveAnswers returns the answer values applied on every target project
+func effectiveAnswers(answers []managementClient.Answer, targets []managementClient.Target) []interface{} {
+	out := make([]interface{}, 0, len(targets))
+	for _, t := range targets {
+		out = append(out, map[string]interface{}{
+			"project_id": t.ProjectID,
+			"values":     toMapInterface(effectiveAnswerValues(answers, t.ProjectID)),
+		})
+	}
+
+	return out
+}
+
+// trimAnswerValues returns a copy of answers with leading and trailing whitespaces removed from values
+func trimAnswerValues(answers []managementClient.Answer) []managementClient.Answer {
+	out := make([]managementClient.Answer, len(answers))
+	for i := range answers {
+		out[i] = answers[i]
+		if len(answers[i].Values) == 0 {
+			continue
+		}
+		out[i].Values = make(map[string]string, len(answers[i].Values))
+		for k, v := range answers[i].Values {
+			out[i].Values[k] = strings.TrimSpace(v)
+		}
+	}
+
+	return out
+}
+
+// Answer references
+
+// splitAnswerConfigMapReference parses an answer value in the format ${configmap://<project_id>:<namespace>:<name>/<key>}
+func splitAnswerConfigMapReference(value string) (projectID, configMapID, key string, ok bool) {
+	if !strings.HasPrefix(value, answerConfigMapReferencePrefix) || !strings.HasSuffix(value, answerReferenceSuffix) {
+		return "", "", "", false
+	}
+
+	ref := strings.TrimSuffix(strings.TrimPrefix(value, answerConfigMapReferencePrefix), answerReferenceSuffix)
+	i := strings.LastIndex(ref, "/")
+	if i < 0 {
+		return "", "", "", false
+	}
+	key = ref[i+1:]
+	fields := strings.Split(ref[:i], ":")
+	if len(fields) != 4 || len(key) == 0 {
+		return "", "", "", false
+	}
+	for _, f := range fields {
+		if len(f) == 0 {
+			return "", "", "", false
+		}
+	}
+
+	return fields[0] + ":" + fields[1], fields[2] + ":" + fields[3], key, true
+}
+
+func isAnswerReference(value string) bool {
+	_, _, _, ok := splitAnswerConfigMapReference(value)
+	return ok
+}
+
+// resolveAnswerReferences returns a copy of answers with every reference replaced by the value returned from getConfigMapKey
+func resolveAnswerReferences(answers []managementClient.Answer, getConfigMapKey func(projectID, configMapID, key string) (string, error)) ([]managementClient.Answer, error) {
+	if len(answers) == 0 {
+		return answers, nil
+	}
+
+	out := make([]managementClient.Answer, len(answers))
+	for i := range answers {
+		out[i] = answers[i]
+		if len(answers[i].Values) == 0 {
+			continue
+		}
+		out[i].Values = make(map[string]string, len(answers[i].Values))
+		for k, v := range answers[i].Values {
+			projectID, configMapID, key, ok := splitAnswerConfigMapReference(v)
+			if !ok {
+				out[i].Values[k] = v
+				continue
+			}
+			value, err := getConfigMapKey(projectID, configMapID, key)
+			if err != nil {
+				return nil, fmt.Errorf("[ERROR] resolving answer %s reference %s: %v", k, v, err)
+			}
+			out[i].Values[k] = value
+		}
+	}
+
+	return out, nil
+}
+
+func sameAnswerScope(a, b map[string]interface{}) bool {
+	aCluster, _ := a["cluster_id"].(string)
+	bCluster, _ := b["cluster_id"].(string)
+	aProject, _ := a["project_id"].(string)
+	bProject, _ := b["project_id"].(string)
+
+	return aCluster == bCluster && aProject == bProject
+}
+
+// keepAnswerReferences restores on flattened answers the references set on old answers, matching them by cluster and project
+func keepAnswerReferences(old, flattened []interface{}) []interface{} {
+	for _, o := range old {
+		oldAnswer, ok := o.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		oldValues, ok := oldAnswer["values"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, n := range flattened {
+			newAnswer := n.(map[string]interface{})
+			if !sameAnswerScope(oldAnswer, newAnswer) {
+				continue
+			}
+			newValues, ok := newAnswer["values"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for k, v := range oldValues {
+				if ref, ok := v.(string); ok && isAnswerReference(ref) {
+					if _, ok := newValues[k]; ok {
+						newValues[k] = ref
+					}
+				}
+			}
+		}
+	}
+
+	return flattened
+}
+
+// Answer cluster provider
+
+func hasAnswerClusterProviderToken(answers []managementClient.Answer) bool {
+	for _, a := range answers {
+		for _, v := range a.Values {
+			if strings.Contains(v, answerClusterProviderToken) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// renderAnswersClusterProvider returns a copy of answers with the cluster provider token replaced by the provider of the
+// answer cluster. Global answers using the token are rendered on a cluster scoped answer for every target cluster,
+// unless the cluster answer already sets the key
+func renderAnswersClusterProvider(answers []managementClient.Answer, targets []managementClient.Target, getClusterProvider func(clusterID string) (string, error)) ([]managementClient.Answer, error) {
+	if !hasAnswerClusterProviderToken(answers) {
+		return answers, nil
+	}
+
+	providers := map[string]string{}
+	render := func(clusterID, value string) (string, error) {
+		provider, ok := providers[clusterID]
+		if !ok {
+			var err error
+			provider, err = getClusterProvider(clusterID)
+			if err != nil {
+				return "", fmt.Errorf("[ERROR] getting cluster %s provider to render answers: %v", clusterID, err)
+			}
+			providers[clusterID] = provider
+		}
+		return strings.Replace(value, answerClusterProviderToken, provider, -1), nil
+	}
+
+	out := make([]managementClient.Answer, 0, len(answers))
+	global := map[string]string{}
+	for _, a := range answers {
+		rendered := a
+		if len(a.Values) > 0 {
+			rendered.Values = make(map[string]string, len(a.Values))
+		}
+		clusterID := a.ClusterID
+		if len(a.ProjectID) > 0 {
+			clusterID, _ = clusterIDFromProjectID(a.ProjectID)
+		}
+		for k, v := range a.Values {
+			rendered.Values[k] = v
+			if !strings.Contains(v, answerClusterProviderToken) {
+				continue
+			}
+			if len(clusterID) == 0 {
+				global[k] = v
+				continue
+			}
+			value, err := render(clusterID, v)
+			if err != nil {
+				return nil, err
+			}
+			rendered.Values[k] = value
+		}
+		out = append(out, rendered)
+	}
+
+	if len(global) == 0 {
+		return out, nil
+	}
+
+	for _, t := range targets {
+		clusterID, err := clusterIDFromProjectID(t.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		i := -1
+		for j := range out {
+			if out[j].ClusterID == clusterID && len(out[j].ProjectID) == 0 {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			out = append(out, managementClient.Answer{ClusterID: clusterID})
+			i = len(out) - 1
+		}
+		if out[i].Values == nil {
+			out[i].Values = map[string]string{}
+		}
+		for k, v := range global {
+			if _, ok := out[i].Values[k]; ok {
+				continue
+			}
+			value, err := render(clusterID, v)
+			if err != nil {
+				return nil, err
+			}
+			out[i].Values[k] = value
+		}
+	}
+
+	return out, nil
+}
+
+// keepAnswerClusterProvider restores on flattened answers the cluster provider tokens set on old answers, removing the
+// cluster scoped values rendered from global answers
+func keepAnswerClusterProvider(old, flattened []interface{}) []interface{} {
+	global := map[string]bool{}
+	for _, o := range old {
+		oldAnswer, ok := o.(map[string]interface{})
+		if !ok || !sameAnswerScope(oldAnswer, map[string]interface{}{}) {
+			continue
+		}
+		oldValues, _ := oldAnswer["values"].(map[string]interface{})
+		for k, v := range oldValues {
+			if value, ok := v.(string); ok && strings.Contains(value, answerClusterProviderToken) {
+				global[k] = true
+			}
+		}
+	}
+
+	out := make([]interface{}, 0, len(flattened))
+	for _, n := range flattened {
+		newAnswer := n.(map[string]interface{})
+		newValues, _ := newAnswer["values"].(map[string]interface{})
+
+		var oldAnswer map[string]interface{}
+		for _, o := range old {
+			if a, ok := o.(map[string]interface{}); ok && sameAnswerScope(a, newAnswer) {
+				oldAnswer = a
+				break
+			}
+		}
+		oldValues, _ := oldAnswer["values"].(map[string]interface{})
+
+		if clusterID, _ := newAnswer["cluster_id"].(string); len(clusterID) > 0 && len(global) > 0 {
+			if projectID, _ := newAnswer["project_id"].(string); len(projectID) == 0 {
+				for k := range global {
+					if _, ok := oldValues[k]; !ok {
+						delete(newValues, k)
+					}
+				}
+				if len(newValues) == 0 {
+					if oldAnswer == nil {
+						continue
+					}
+					delete(newAnswer, "values")
+				}
+			}
+		}
+
+		for k, v := range oldValues {
+			if value, ok := v.(string); ok && strings.Contains(value, answerClusterProviderToken) {
+				if _, ok := newValues[k]; ok {
+					newValues[k] = value
+				}
+			}
+		}
+		out = append(out, newAnswer)
+	}
+
+	return out
+}
+
+// Answers object
+
+// answersObjectValues flattens a nested YAML or JSON answers object into dotted answer keys. Array items are keyed as key[i]
+func answersObjectValues(in string) (map[string]string, error) {
+	out := map[string]string{}
+	if len(strings.TrimSpace(in)) == 0 {
+		return out, nil
+	}
+
+	obj, err := ghodssyamlToMapInterface(in)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] answers object must be in YAML or JSON format: %v", err)
+	}
+
+	err = flattenAnswersObjectValue("", obj, out)
+	if err != nil {
+		return nil, err
+	}
+
+	return keepAnswersObjectFloatText(in, out), nil
+}
+
+// answersObjectScalars is a nested YAML or JSON answers object keeping the scalars as written
+type answersObjectScalars struct {
+	scalar string
+	object map[string]*answersObjectScalars
+	list   []*answersObjectScalars
+}
+
+func (a *answersObjectScalars) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&a.object); err == nil {
+		return nil
+	}
+	if err := unmarshal(&a.list); err == nil {
+		return nil
+	}
+
+	return unmarshal(&a.scalar)
+}
+
+func (a *answersObjectScalars) flatten(key string, out map[string]string) {
+	if a == nil {
+		return
+	}
+	switch {
+	case a.object != nil:
+		for k, item := range a.object {
+			child := k
+			if len(key) > 0 {
+				child = key + "." + k
+			}
+			item.flatten(child, out)
+		}
+	case a.list != nil:
+		for i, item := range a.list {
+			item.flatten(fmt.Sprintf("%s[%d]", key, i), out)
+		}
+	default:
+		out[key] = a.scalar
+	}
+}
+
+// keepAnswersObjectFloatText keeps the decimal answer values parsed as floats as written on the in answers object,
+// e.g. version 1.20 parsed as 1.2, logging a warning
+func keepAnswersObjectFloatText(in string, values map[string]string) map[string]string {
+	raw := &answersObjectScalars{}
+	if err := yaml.Unmarshal([]byte(in), raw); err != nil {
+		return values
+	}
+	scalars := map[string]string{}
+	raw.flatten("", scalars)
+
+	for k, v := range values {
+		text, ok := scalars[k]
+		if !ok || text == v || !strings.Contains(text, ".") || strings.ContainsAny(text, "eE") {
+			continue
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil || strconv.FormatFloat(f, 'f', -1, 64) != v {
+			continue
+		}
+		log.Printf("[WARN] answers object key %q value %s is parsed as number %s, keeping it as string %q. Quote the value to avoid this warning", k, text, v, text)
+		values[k] = text
+	}
+
+	return values
+}
+
+func flattenAnswersObjectValue(key string, in interface{}, out map[string]string) error {
+	switch v := in.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && len(key) > 0 {
+			return fmt.Errorf("[ERROR] answers object key %q is empty", key)
+		}
+		for k, item := range v {
+			child := k
+			if len(key) > 0 {
+				child = key + "." + k
+			}
+			if len(k) == 0 || strings.ContainsAny(k, ".[]") {
+				return fmt.Errorf("[ERROR] answers object key %q is not valid, nested keys have to be set as nested objects", child)
+			}
+			err := flattenAnswersObjectValue(child, item, out)
+			if err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return fmt.Errorf("[ERROR] answers object key %q is empty", key)
+		}
+		for i, item := range v {
+			err := flattenAnswersObjectValue(fmt.Sprintf("%s[%d]", key, i), item, out)
+			if err != nil {
+				return err
+			}
+		}
+	case nil:
+		return fmt.Errorf("[ERROR] answers object key %q is null", key)
+	case string:
+		out[key] = v
+	case bool:
+		out[key] = strconv.FormatBool(v)
+	case float64:
+		out[key] = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		out[key] = fmt.Sprintf("%v", v)
+	}
+
+	return nil
+}
+
+// mergeAnswersObject returns a copy of answers with the answers object values added to the global answer. Values
+// already set on the global answer take precedence
+func mergeAnswersObject(answers []managementClient.Answer, values map[string]string) []managementClient.Answer {
+	if len(values) == 0 {
+		return answers
+	}
+
+	out := make([]managementClient.Answer, 0, len(answers)+1)
+	global := -1
+	for i, a := range answers {
+		if len(a.ClusterID) == 0 && len(a.ProjectID) == 0 && global < 0 {
+			global = i
+		}
+		out = append(out, a)
+	}
+	if global < 0 {
+		out = append([]managementClient.Answer{{}}, out...)
+		global = 0
+	}
+
+	merged := make(map[string]string, len(out[global].Values)+len(values))
+	for k, v := range values {
+		merged[k] = v
+	}
+	for k, v := range out[global].Values {
+		merged[k] = v
+	}
+	out[global].Values = merged
+
+	return out
+}
+
+// keepAnswersObject removes from the flattened global answer the values set by the answers object, unless they are
+// also set on the old global answer
+func keepAnswersObject(old, flattened []interface{}, values map[string]string) []interface{} {
+	if len(values) == 0 {
+		return flattened
+	}
+
+	var oldGlobal map[string]interface{}
+	for _, o := range old {
+		if a, ok := o.(map[string]interface{}); ok && sameAnswerScope(a, map[string]interface{}{}) {
+			oldGlobal = a
+			break
+		}
+	}
+	oldValues, _ := oldGlobal["values"].(map[string]interface{})
+
+	out := make([]interface{}, 0, len(flattened))
+	for _, n := range flattened {
+		newAnswer := n.(map[string]interface{})
+		if !sameAnswerScope(newAnswer, map[string]interface{}{}) {
+			out = append(out, newAnswer)
+			continue
+		}
+		newValues, _ := newAnswer["values"].(map[string]interface{})
+		for k := range values {
+			if _, ok := oldValues[k]; !ok {
+				delete(newValues, k)
+			}
+		}
+		if len(newValues) == 0 {
+			if oldGlobal == nil {
+				continue
+			}
+			delete(newAnswer, "values")
+		}
+		out = append(out, newAnswer)
+	}
+
+	return out
+}
+
+// mergeSensitiveAnswers returns a copy of answers with the sensitive values set on the global answer. Sensitive values
+// take precedence. Returns error if any value is still encrypted
+func mergeSensitiveAnswers(answers []managementClient.Answer, sensitive map[string]interface{}) ([]managementClient.Answer, error) {
+	if len(sensitive) == 0 {
+		return answers, nil
+	}
+
+	values := make(map[string]string, len(sensitive))
+	for k, v := range sensitive {
+		value, _ := v.(string)
+		if isEncryptedAnswer(value) {
+			return nil, fmt.Errorf("[ERROR] sensitive answer %s can't be decrypted, check provider answers_encryption_passphrase", k)
+		}
+		values[k] = value
+	}
+
+	out := make([]managementClient.Answer, 0, len(answers)+1)
+	global := -1
+	for i, a := range answers {
+		if len(a.ClusterID) == 0 && len(a.ProjectID) == 0 && global < 0 {
+			global = i
+		}
+		out = append(out, a)
+	}
+	if global < 0 {
+		out = append([]managementClient.Answer{{}}, out...)
+		global = 0
+	}
+
+	merged := make(map[string]string, len(out[global].Values)+len(values))
+	for k, v := range out[global].Values {
+		merged[k] = v
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+	out[global].Values = merged
+
+	return out, nil
+}
+
+// decryptSensitiveAnswers returns a copy of the sensitive answers with the encrypted values decrypted. Values that
+// can't be decrypted are returned as is
+func decryptSensitiveAnswers(encrypter answersEncrypter, sensitive map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(sensitive))
+	for k, v := range sensitive {
+		out[k] = v
+		value, _ := v.(string)
+		if encrypter == nil || !isEncryptedAnswer(value) {
+			continue
+		}
+		if plaintext, err := encrypter.Decrypt(value); err == nil {
+			out[k] = plaintext
+		}
+	}
+
+	return out
+}
+
+// isEncryptedAnswer returns true if value is encrypted by an answersEncrypter, on the current or a legacy envelope
+func isEncryptedAnswer(value string) bool {
+	return strings.HasPrefix(value, answersEncryptedPrefix) || strings.HasPrefix(value, answersEncryptedPrefixV1)
+}
+
+// sensitiveAnswerStateValue returns the value to store on state for the live sensitive answer value. The old state value
+// is kept if it's the same value, so it isn't encrypted again on every refresh. Values on a legacy envelope are encrypted
+// again. Plaintext is returned if encrypter is nil
+func sensitiveAnswerStateValue(encrypter answersEncrypter, old, live string) (string, error) {
+	if encrypter == nil {
+		return live, nil
+	}
+	if strings.HasPrefix(old, answersEncryptedPrefix) {
+		if plaintext, err := encrypter.Decrypt(old); err == nil && plaintext == live {
+			return old, nil
+		}
+	}
+
+	return encrypter.Encrypt(live)
+}
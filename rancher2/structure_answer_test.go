@@ -1,6 +1,12 @@
 package rancher2
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
 	"testing"
 
 	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
@@ -70,3 +76,291 @@ func TestExpandAnswers(t *testing.T) {
 		assert.Equal(t, tc.ExpectedOutput, output, "Unexpected output from expander.")
 	}
 }
+
+func TestResolveAnswerReferences(t *testing.T) {
+	answers := []managementClient.Answer{
+		{
+			ProjectID: "c-abcde:p-fghij",
+			Values: map[string]string{
+				"plain":      "value",
+				"referenced": "${configmap://c-abcde:p-fghij:default:settings/hostname}",
+			},
+		},
+	}
+	getConfigMapKey := func(projectID, configMapID, key string) (string, error) {
+		if projectID == "c-abcde:p-fghij" && configMapID == "default:settings" && key == "hostname" {
+			return "test.example.com", nil
+		}
+		return "", fmt.Errorf("config map %s key %s not found", configMapID, key)
+	}
+
+	output, err := resolveAnswerReferences(answers, getConfigMapKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", output[0].Values["plain"])
+	assert.Equal(t, "test.example.com", output[0].Values["referenced"])
+	assert.Equal(t, "${configmap://c-abcde:p-fghij:default:settings/hostname}", answers[0].Values["referenced"], "Input answers should not be modified")
+
+	answers[0].Values["referenced"] = "${configmap://c-abcde:p-fghij:default:settings/missing}"
+	_, err = resolveAnswerReferences(answers, getConfigMapKey)
+	assert.Error(t, err)
+}
+
+func TestEffectiveAnswers(t *testing.T) {
+	answers := []managementClient.Answer{
+		{
+			Values: map[string]string{
+				"ingress.host":        "global.example.com",
+				"ingress.tls.enabled": "true",
+				"persistence.size":    "10Gi",
+			},
+		},
+		{
+			ClusterID: "c-abcde",
+			Values: map[string]string{
+				"ingress.host": "cluster.example.com",
+			},
+		},
+		{
+			ProjectID: "c-abcde:p-two",
+			Values: map[string]string{
+				"ingress.tls.enabled": "false",
+				"persistence":         "disabled",
+			},
+		},
+	}
+	targets := []managementClient.Target{
+		{ProjectID: "c-abcde:p-one"},
+		{ProjectID: "c-abcde:p-two"},
+		{ProjectID: "c-fghij:p-three"},
+	}
+	expected := []interface{}{
+		map[string]interface{}{
+			"project_id": "c-abcde:p-one",
+			"values": map[string]interface{}{
+				"ingress.host":        "cluster.example.com",
+				"ingress.tls.enabled": "true",
+				"persistence.size":    "10Gi",
+			},
+		},
+		map[string]interface{}{
+			"project_id": "c-abcde:p-two",
+			"values": map[string]interface{}{
+				"ingress.host":        "cluster.example.com",
+				"ingress.tls.enabled": "false",
+				"persistence":         "disabled",
+			},
+		},
+		map[string]interface{}{
+			"project_id": "c-fghij:p-three",
+			"values": map[string]interface{}{
+				"ingress.host":        "global.example.com",
+				"ingress.tls.enabled": "true",
+				"persistence.size":    "10Gi",
+			},
+		},
+	}
+
+	output := effectiveAnswers(answers, targets)
+	assert.Equal(t, expected, output, "Unexpected effective answers.")
+}
+
+func TestMergeAnswerValuesParentAndChild(t *testing.T) {
+	global := map[string]string{
+		"ingress":             "disabled",
+		"ingress.host":        "global.example.com",
+		"ingress.tls.enabled": "true",
+		"persistence.size":    "10Gi",
+	}
+	project := map[string]string{
+		"persistence":      "disabled",
+		"persistence.size": "20Gi",
+	}
+	expected := map[string]string{
+		"ingress.host":        "global.example.com",
+		"ingress.tls.enabled": "true",
+		"persistence.size":    "20Gi",
+	}
+
+	for i := 0; i < 100; i++ {
+		output := mergeAnswerValues(global, project)
+		assert.Equal(t, expected, output, "Merge should not depend on map iteration order.")
+	}
+}
+
+func TestRenderAnswersClusterProvider(t *testing.T) {
+	answers := []managementClient.Answer{
+		{
+			Values: map[string]string{
+				"ingress.host":             "global.example.com",
+				"persistence.storageClass": "sc-${cluster.provider}",
+			},
+		},
+		{
+			ClusterID: "c-gke",
+			Values: map[string]string{
+				"persistence.storageClass": "standard",
+			},
+		},
+		{
+			ProjectID: "c-aks:p-one",
+			Values: map[string]string{
+				"cloud": "${cluster.provider}",
+			},
+		},
+	}
+	targets := []managementClient.Target{
+		{ProjectID: "c-eks:p-one"},
+		{ProjectID: "c-eks:p-two"},
+		{ProjectID: "c-gke:p-one"},
+		{ProjectID: "c-aks:p-one"},
+	}
+	providers := map[string]string{
+		"c-eks": "eks",
+		"c-gke": "gke",
+		"c-aks": "aks",
+	}
+	getClusterProvider := func(clusterID string) (string, error) {
+		provider, ok := providers[clusterID]
+		if !ok {
+			return "", fmt.Errorf("cluster %s not found", clusterID)
+		}
+		return provider, nil
+	}
+
+	output, err := renderAnswersClusterProvider(answers, targets, getClusterProvider)
+	assert.NoError(t, err)
+	assert.Equal(t, "sc-${cluster.provider}", answers[0].Values["persistence.storageClass"], "Answers should not be modified")
+	assert.Equal(t, "sc-eks", effectiveAnswerValues(output, "c-eks:p-two")["persistence.storageClass"])
+	assert.Equal(t, "standard", effectiveAnswerValues(output, "c-gke:p-one")["persistence.storageClass"])
+	assert.Equal(t, "sc-aks", effectiveAnswerValues(output, "c-aks:p-one")["persistence.storageClass"])
+	assert.Equal(t, "aks", effectiveAnswerValues(output, "c-aks:p-one")["cloud"])
+	assert.Equal(t, "global.example.com", effectiveAnswerValues(output, "c-eks:p-one")["ingress.host"])
+
+	// Rendered answers are read back as configured
+	flattened := keepAnswerClusterProvider(flattenAnswers(answers), flattenAnswers(output))
+	assert.Equal(t, flattenAnswers(answers), flattened)
+
+	_, err = renderAnswersClusterProvider(answers, []managementClient.Target{{ProjectID: "c-unknown:p-one"}}, getClusterProvider)
+	assert.Error(t, err)
+}
+
+func TestAnswersObjectValues(t *testing.T) {
+	in := `{
+  "ingress": {
+    "enabled": true,
+    "hosts": ["a.example.com", "b.example.com"],
+    "tls": [{"secretName": "tls-a", "hosts": ["a.example.com"]}]
+  },
+  "replicaCount": 2,
+  "resources": {"limits": {"cpu": "500m", "memory": 0.5}}
+}`
+	expected := map[string]string{
+		"ingress.enabled":           "true",
+		"ingress.hosts[0]":          "a.example.com",
+		"ingress.hosts[1]":          "b.example.com",
+		"ingress.tls[0].secretName": "tls-a",
+		"ingress.tls[0].hosts[0]":   "a.example.com",
+		"replicaCount":              "2",
+		"resources.limits.cpu":      "500m",
+		"resources.limits.memory":   "0.5",
+	}
+
+	output, err := answersObjectValues(in)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, output, "Unexpected output from answers object.")
+
+	versions := "image:\n  tag: 1.20\n  previous: [1.10, \"1.9\"]\nkubeVersion: 1.0\nreplicaCount: 2\nratio: 1e3\n"
+	output, err = answersObjectValues(versions)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"image.tag":         "1.20",
+		"image.previous[0]": "1.10",
+		"image.previous[1]": "1.9",
+		"kubeVersion":       "1.0",
+		"replicaCount":      "2",
+		"ratio":             "1000",
+	}, output, "Unexpected output from answers object with float coercible versions.")
+
+	for _, invalid := range []string{
+		"ingress:\n  host.name: a.example.com\n",
+		"ingress:\n  hosts: []\n",
+		"ingress:\n  host: null\n",
+		"- a\n- b\n",
+	} {
+		_, err = answersObjectValues(invalid)
+		assert.Error(t, err, "Expected error for answers object %q", invalid)
+	}
+
+	answers := []managementClient.Answer{
+		{
+			ProjectID: "c-abcde:p-one",
+			Values:    map[string]string{"replicaCount": "3"},
+		},
+		{
+			Values: map[string]string{"replicaCount": "1"},
+		},
+	}
+	merged := mergeAnswersObject(answers, expected)
+	assert.Len(t, merged, 2)
+	assert.Equal(t, "1", merged[1].Values["replicaCount"], "Answers should take precedence over answers object")
+	assert.Equal(t, "a.example.com", merged[1].Values["ingress.hosts[0]"])
+	assert.Equal(t, map[string]string{"replicaCount": "1"}, answers[1].Values, "Answers should not be modified")
+
+	// Answers object values are not read back on answers
+	flattened := keepAnswersObject(flattenAnswers(answers), flattenAnswers(merged), expected)
+	assert.Equal(t, flattenAnswers(answers), flattened)
+
+	merged = mergeAnswersObject(answers[:1], expected)
+	assert.Len(t, merged, 2)
+	assert.Equal(t, expected, merged[0].Values)
+	flattened = keepAnswersObject(flattenAnswers(answers[:1]), flattenAnswers(merged), expected)
+	assert.Equal(t, flattenAnswers(answers[:1]), flattened)
+}
+
+func TestPassphraseAnswersEncrypter(t *testing.T) {
+	encrypter := newPassphraseAnswersEncrypter("passphrase")
+
+	encrypted, err := encrypter.Encrypt("secret")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, answersEncryptedPrefix))
+	assert.NotContains(t, encrypted, "secret")
+
+	decrypted, err := encrypter.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", decrypted)
+
+	_, err = newPassphraseAnswersEncrypter("other").Decrypt(encrypted)
+	assert.Error(t, err, "Decrypting with another passphrase should fail")
+
+	again, err := encrypter.Encrypt("secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "Each value should be encrypted with its own salt")
+
+	legacyKey := sha256.Sum256([]byte("passphrase"))
+	block, _ := aes.NewCipher(legacyKey[:])
+	gcm, _ := cipher.NewGCM(block)
+	nonce := make([]byte, gcm.NonceSize())
+	legacy := answersEncryptedPrefixV1 + base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte("secret"), nil))
+	decrypted, err = encrypter.Decrypt(legacy)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", decrypted, "Legacy values should still be decrypted")
+	stateValue, err := sensitiveAnswerStateValue(encrypter, legacy, "secret")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stateValue, answersEncryptedPrefix), "Legacy values should be encrypted again")
+
+	stateValue, err = sensitiveAnswerStateValue(encrypter, encrypted, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, encrypted, stateValue, "Unchanged value should not be encrypted again")
+	stateValue, err = sensitiveAnswerStateValue(encrypter, encrypted, "changed")
+	assert.NoError(t, err)
+	decrypted, _ = encrypter.Decrypt(stateValue)
+	assert.Equal(t, "changed", decrypted)
+
+	sensitive := decryptSensitiveAnswers(encrypter, map[string]interface{}{"password": encrypted})
+	answers, err := mergeSensitiveAnswers([]managementClient.Answer{{Values: map[string]string{"host": "example.com"}}}, sensitive)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "example.com", "password": "secret"}, answers[0].Values)
+
+	_, err = mergeSensitiveAnswers(nil, map[string]interface{}{"password": encrypted})
+	assert.Error(t, err, "Encrypted values should not be submitted")
+}
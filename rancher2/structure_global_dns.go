@@ -29,7 +29,16 @@ func flattenGlobalDNS(d *schema.ResourceData, in *managementClient.GlobalDns) er
 		d.Set("ttl", int(in.TTL))
 	}
 
-	err := d.Set("annotations", toMapInterface(in.Annotations))
+	endpoints := []interface{}{}
+	if in.Status != nil {
+		endpoints = toArrayInterface(in.Status.Endpoints)
+	}
+	err := d.Set("endpoints", endpoints)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("annotations", toMapInterface(in.Annotations))
 	if err != nil {
 		return err
 	}
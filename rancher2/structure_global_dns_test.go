@@ -83,3 +83,26 @@ func TestExpandGlobalDNS(t *testing.T) {
 		assert.Equal(t, tc.ExpectedOutput, output, "Unexpected output from expander.")
 	}
 }
+
+func TestFlattenGlobalDNSEndpoints(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, GlobalDNSFields(), map[string]interface{}{})
+	in := &managementClient.GlobalDns{
+		FQDN:              "test.non.example.com",
+		ProviderID:        "cattle-global:foo-test2",
+		MultiClusterAppID: "mca",
+		Status: &managementClient.GlobalDNSStatus{
+			Endpoints: []string{"10.0.0.1", "10.0.0.2"},
+		},
+	}
+	assert.NoError(t, flattenGlobalDNS(d, in))
+	assert.Equal(t, []interface{}{"10.0.0.1", "10.0.0.2"}, d.Get("endpoints"))
+
+	// Endpoints follow the multi cluster app targets
+	in.Status.Endpoints = []string{"10.0.0.1"}
+	assert.NoError(t, flattenGlobalDNS(d, in))
+	assert.Equal(t, []interface{}{"10.0.0.1"}, d.Get("endpoints"))
+
+	in.Status = nil
+	assert.NoError(t, flattenGlobalDNS(d, in))
+	assert.Empty(t, d.Get("endpoints"))
+}
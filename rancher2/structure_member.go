@@ -1,6 +1,10 @@
 package rancher2
 
 import (
+	"fmt"
+	"log"
+	"strings"
+
 	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
 )
 
@@ -33,6 +37,41 @@ func flattenMembers(p []managementClient.Member) []interface{} {
 	return out
 }
 
+// flattenMemberEffectivePermissions cross-references members access type with the app roles.
+// Owners and members are able to exercise app roles, read-only members aren't. Role template rules aren't resolved
+func flattenMemberEffectivePermissions(p []managementClient.Member, roles []string) []interface{} {
+	if len(p) == 0 {
+		return []interface{}{}
+	}
+
+	out := make([]interface{}, len(p))
+	for i, in := range p {
+		obj := make(map[string]interface{})
+
+		accessType := strings.ToLower(in.AccessType)
+		if len(accessType) == 0 {
+			accessType = memberAccessTypeMember
+		}
+		obj["access_type"] = accessType
+
+		if len(in.UserPrincipalID) > 0 {
+			obj["principal_id"] = in.UserPrincipalID
+		} else {
+			obj["principal_id"] = in.GroupPrincipalID
+		}
+
+		if accessType == memberAccessTypeRO {
+			obj["roles"] = []interface{}{}
+		} else {
+			obj["roles"] = toArrayInterface(roles)
+		}
+
+		out[i] = obj
+	}
+
+	return out
+}
+
 // Expanders
 
 func expandMembers(p []interface{}) []managementClient.Member {
@@ -60,3 +99,66 @@ func expandMembers(p []interface{}) []managementClient.Member {
 
 	return obj
 }
+
+// memberPrincipalKey returns the member principal prefixed by its kind, so user and group principals with the same
+// ID don't match
+func memberPrincipalKey(in managementClient.Member) string {
+	if len(in.UserPrincipalID) > 0 {
+		return "user:" + in.UserPrincipalID
+	}
+
+	return "group:" + in.GroupPrincipalID
+}
+
+// membersChange returns the principal keys of the members added and removed from old to new, matched by principal.
+// Access type changes aren't reported
+func membersChange(old, new []managementClient.Member) ([]string, []string) {
+	oldKeys := map[string]bool{}
+	for _, m := range old {
+		oldKeys[memberPrincipalKey(m)] = true
+	}
+	newKeys := map[string]bool{}
+	added := []string{}
+	for _, m := range new {
+		key := memberPrincipalKey(m)
+		if !oldKeys[key] && !newKeys[key] {
+			added = append(added, key)
+		}
+		newKeys[key] = true
+	}
+	removed := []string{}
+	for _, m := range old {
+		key := memberPrincipalKey(m)
+		if !newKeys[key] {
+			removed = append(removed, key)
+			newKeys[key] = true
+		}
+	}
+
+	return added, removed
+}
+
+// validateMemberPrincipals returns error listing the member user and group principals not found. Principals failing
+// to be got for other reasons are skipped, they're validated by Rancher on apply
+func validateMemberPrincipals(members []managementClient.Member, getPrincipal func(string) (*managementClient.Principal, error)) error {
+	notFound := []string{}
+	for _, m := range members {
+		for _, id := range []string{m.UserPrincipalID, m.GroupPrincipalID} {
+			if len(id) == 0 {
+				continue
+			}
+			if _, err := getPrincipal(id); err != nil {
+				if IsNotFound(err) {
+					notFound = append(notFound, id)
+					continue
+				}
+				log.Printf("[WARN] Skipping member principal %s validation: %v", id, err)
+			}
+		}
+	}
+	if len(notFound) > 0 {
+		return fmt.Errorf("[ERROR] Member principals not found on Rancher: %s", strings.Join(notFound, ", "))
+	}
+
+	return nil
+}
@@ -1,8 +1,11 @@
 package rancher2
 
 import (
+	"errors"
+	"net/http"
 	"testing"
 
+	"github.com/rancher/norman/clientbase"
 	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
 	"github.com/stretchr/testify/assert"
 )
@@ -64,3 +67,91 @@ func TestExpandMembers(t *testing.T) {
 		assert.Equal(t, tc.ExpectedOutput, output, "Unexpected output from expander.")
 	}
 }
+
+func TestFlattenMemberEffectivePermissions(t *testing.T) {
+	members := []managementClient.Member{
+		{
+			AccessType:      memberAccessTypeOwner,
+			UserPrincipalID: "local://u-owner",
+		},
+		{
+			AccessType:       memberAccessTypeMember,
+			GroupPrincipalID: "github_team://1234",
+		},
+		{
+			AccessType:      memberAccessTypeRO,
+			UserPrincipalID: "local://u-viewer",
+		},
+	}
+	roles := []string{"project-member"}
+	expected := []interface{}{
+		map[string]interface{}{
+			"access_type":  memberAccessTypeOwner,
+			"principal_id": "local://u-owner",
+			"roles":        []interface{}{"project-member"},
+		},
+		map[string]interface{}{
+			"access_type":  memberAccessTypeMember,
+			"principal_id": "github_team://1234",
+			"roles":        []interface{}{"project-member"},
+		},
+		map[string]interface{}{
+			"access_type":  memberAccessTypeRO,
+			"principal_id": "local://u-viewer",
+			"roles":        []interface{}{},
+		},
+	}
+
+	output := flattenMemberEffectivePermissions(members, roles)
+	assert.Equal(t, expected, output, "Unexpected output from flattener.")
+}
+
+func TestMembersChange(t *testing.T) {
+	old := []managementClient.Member{
+		{AccessType: memberAccessTypeOwner, UserPrincipalID: "local://u-owner"},
+		{AccessType: memberAccessTypeMember, GroupPrincipalID: "github_team://1234"},
+		{AccessType: memberAccessTypeRO, UserPrincipalID: "local://u-viewer"},
+	}
+	updated := []managementClient.Member{
+		{AccessType: memberAccessTypeOwner, UserPrincipalID: "local://u-owner"},
+		// Same ID as a group principal isn't the same member
+		{AccessType: memberAccessTypeRO, GroupPrincipalID: "local://u-viewer"},
+		{AccessType: memberAccessTypeRO, GroupPrincipalID: "local://u-viewer"},
+		// Access type change isn't an addition
+		{AccessType: memberAccessTypeOwner, GroupPrincipalID: "github_team://1234"},
+	}
+
+	added, removed := membersChange(old, updated)
+	assert.Equal(t, []string{"group:local://u-viewer"}, added)
+	assert.Equal(t, []string{"user:local://u-viewer"}, removed)
+
+	added, removed = membersChange(old, nil)
+	assert.Empty(t, added)
+	assert.Equal(t, []string{"user:local://u-owner", "group:github_team://1234", "user:local://u-viewer"}, removed)
+}
+
+func TestValidateMemberPrincipals(t *testing.T) {
+	getPrincipal := func(id string) (*managementClient.Principal, error) {
+		switch id {
+		case "local://u-owner", "github_team://1234":
+			return &managementClient.Principal{}, nil
+		case "github_user://unreachable":
+			return nil, errors.New("connection refused")
+		}
+		return nil, &clientbase.APIError{StatusCode: http.StatusNotFound}
+	}
+
+	members := []managementClient.Member{
+		{AccessType: memberAccessTypeOwner, UserPrincipalID: "local://u-owner"},
+		{AccessType: memberAccessTypeMember, GroupPrincipalID: "github_team://1234"},
+		{AccessType: memberAccessTypeRO, UserPrincipalID: "github_user://unreachable"},
+	}
+	assert.NoError(t, validateMemberPrincipals(members, getPrincipal))
+
+	members = append(members, managementClient.Member{AccessType: memberAccessTypeRO, UserPrincipalID: "local://u-typo"})
+	err := validateMemberPrincipals(members, getPrincipal)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "local://u-typo")
+		assert.NotContains(t, err.Error(), "local://u-owner")
+	}
+}
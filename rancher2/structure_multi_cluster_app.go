@@ -2,19 +2,66 @@ package rancher2
 
 import (
 	"fmt"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
-	MultiClusterAppTemplatePrefix = "cattle-global-data:"
+	MultiClusterAppTemplatePrefix        = "cattle-global-data:"
+	multiClusterAppCatalogWaitFraction   = 4
+	multiClusterAppTargetAppNamePrefix   = "mcapp-"
+	multiClusterAppTargetScaleAnnotation = "rancher2.terraform.io/target-scale"
+	multiClusterAppDeprecatedAnnotation  = "catalog.cattle.io/deprecated"
+	multiClusterAppReplicaCountAnswer    = "replicaCount"
+	catalogResolutionScopeGlobal         = "global"
+	catalogResolutionScopeCluster        = "cluster"
+	catalogResolutionScopeProject        = "project"
 )
 
+// catalogResolutionScopes are the catalog scopes allowed on the provider catalog_resolution_order
+var catalogResolutionScopes = []string{
+	catalogResolutionScopeGlobal,
+	catalogResolutionScopeCluster,
+	catalogResolutionScopeProject,
+}
+
+// multiClusterAppRequestAnswers are the chart answers declaring the resource requests checked against project quotas
+var multiClusterAppRequestAnswers = map[string]string{
+	"cpu":    "resources.requests.cpu",
+	"memory": "resources.requests.memory",
+}
+
 // Flatteners
 
 func flattenMultiClusterAppTemplateVersionID(d *schema.ResourceData, externalID string) string {
+	out := splitMultiClusterAppExternalID(externalID)
+
+	prefix := MultiClusterAppTemplatePrefix
+	catalogName := out["catalog"]
+	//Cluster or project catalog: catalog://?catalog=p-xxxxx/test&type=projectCatalog&template=test&version=1.23.0
+	if i := strings.Index(catalogName, "/"); i > 0 {
+		prefix = catalogName[:i] + ":"
+		catalogName = catalogName[i+1:]
+	}
+
+	d.Set("catalog_name", catalogName)
+	d.Set("template_name", out["template"])
+	d.Set("template_version", out["version"])
+
+	//Template version ID: cattle-global-data:test-test-1.23.0
+	return prefix + catalogName + "-" + out["template"] + "-" + out["version"]
+}
+
+// splitMultiClusterAppExternalID parses the template external ID query values
+func splitMultiClusterAppExternalID(externalID string) map[string]string {
 	//Global catalog url: catalog://?catalog=demo&template=test&version=1.23.0
 
 	str := strings.TrimPrefix(externalID, AppTemplateExternalIDPrefix)
@@ -28,12 +75,146 @@ func flattenMultiClusterAppTemplateVersionID(d *schema.ResourceData, externalID
 		out[pair[0]] = pair[1]
 	}
 
-	d.Set("catalog_name", out["catalog"])
-	d.Set("template_name", out["template"])
-	d.Set("template_version", out["version"])
+	return out
+}
 
-	//Template version ID: cattle-global-data:test-test-1.23.0
-	return MultiClusterAppTemplatePrefix + out["catalog"] + "-" + out["template"] + "-" + out["version"]
+// normalizeMultiClusterAppTemplateVersion returns the version of a template_version set as a full template external ID.
+// Any other value is returned as is
+func normalizeMultiClusterAppTemplateVersion(v interface{}) string {
+	version, _ := v.(string)
+	if !strings.HasPrefix(version, AppTemplateExternalIDPrefix) && !strings.Contains(version, "version=") {
+		return version
+	}
+	out := splitMultiClusterAppExternalID(version)
+	if len(out["version"]) == 0 {
+		return version
+	}
+	log.Printf("[INFO] Normalizing multi cluster app template_version %s to %s", version, out["version"])
+
+	return out["version"]
+}
+
+// flattenMultiClusterAppTemplateCategories returns the template categories, including its main category, without duplicates
+func flattenMultiClusterAppTemplateCategories(in *managementClient.Template) []interface{} {
+	out := []interface{}{}
+	if in == nil {
+		return out
+	}
+
+	seen := map[string]bool{}
+	for _, c := range append([]string{in.Category}, in.Categories...) {
+		if len(c) == 0 || seen[c] {
+			continue
+		}
+		seen[c] = true
+		out = append(out, c)
+	}
+
+	return out
+}
+
+// flattenMultiClusterAppLastTransitionTime returns the latest status condition transition time, in RFC3339 UTC format.
+// Empty if no condition transition time is parsable
+func flattenMultiClusterAppLastTransitionTime(in *managementClient.MultiClusterApp) string {
+	if in.Status == nil {
+		return ""
+	}
+	var last time.Time
+	for _, c := range in.Status.Conditions {
+		transition, err := time.Parse(time.RFC3339, c.LastTransitionTime)
+		if err != nil {
+			if len(c.LastTransitionTime) > 0 {
+				log.Printf("[DEBUG] Ignoring multi cluster app ID %s condition %s transition time %q: %v", in.ID, c.Type, c.LastTransitionTime, err)
+			}
+			continue
+		}
+		if transition.After(last) {
+			last = transition
+		}
+	}
+	if last.IsZero() {
+		return ""
+	}
+
+	return last.UTC().Format(time.RFC3339)
+}
+
+// flattenMultiClusterAppTargetHealthStates returns target health states by project ID. Targets without health state yet are omitted
+func flattenMultiClusterAppTargetHealthStates(targets []managementClient.Target) map[string]interface{} {
+	out := make(map[string]interface{}, len(targets))
+	for _, t := range targets {
+		if len(t.ProjectID) == 0 || len(t.Healthstate) == 0 {
+			continue
+		}
+		out[t.ProjectID] = t.Healthstate
+	}
+
+	return out
+}
+
+// flattenMultiClusterAppTargetAppNames returns target app names by project ID. Rancher names target apps
+// mcapp-<multi_cluster_app_name>, target app ID is used instead if already set
+func flattenMultiClusterAppTargetAppNames(name string, targets []managementClient.Target) map[string]interface{} {
+	out := make(map[string]interface{}, len(targets))
+	for _, t := range targets {
+		if len(t.ProjectID) == 0 {
+			continue
+		}
+		if len(t.AppID) > 0 {
+			out[t.ProjectID] = t.AppID
+			continue
+		}
+		out[t.ProjectID] = multiClusterAppTargetAppNamePrefix + name
+	}
+
+	return out
+}
+
+// flattenMultiClusterAppTargetApps returns the project ID, app ID and state of every target. App ID is empty until
+// Rancher deploys the target app
+func flattenMultiClusterAppTargetApps(targets []managementClient.Target) []interface{} {
+	out := make([]interface{}, 0, len(targets))
+	for _, t := range targets {
+		if len(t.ProjectID) == 0 {
+			continue
+		}
+		out = append(out, map[string]interface{}{
+			"project_id": t.ProjectID,
+			"app_id":     t.AppID,
+			"state":      t.State,
+		})
+	}
+
+	return out
+}
+
+// flattenMultiClusterAppTargetsInSync returns true if every target state equals the multi cluster app state
+func flattenMultiClusterAppTargetsInSync(in *managementClient.MultiClusterApp) bool {
+	for _, t := range in.Targets {
+		if t.State != in.State {
+			return false
+		}
+	}
+
+	return true
+}
+
+// flattenMultiClusterAppKeptTargets returns the flattened targets with their scale hints, removing the ones resolved from
+// target_from_cluster_template or target_fleet_workspace and keeping the disabled targets and target arguments set on d
+func flattenMultiClusterAppKeptTargets(d *schema.ResourceData, in []managementClient.Target, scales map[string]int) []interface{} {
+	targets := flattenTargets(in)
+	for _, t := range targets {
+		target := t.(map[string]interface{})
+		if scale, ok := scales[target["project_id"].(string)]; ok {
+			target["scale"] = scale
+		}
+	}
+	oldTargets, _ := d.Get("targets").([]interface{})
+	templateTargets, _ := d.Get("cluster_template_targets").([]interface{})
+	targets = removeClusterTemplateTargets(oldTargets, targets, toArrayString(templateTargets))
+	targets = keepDisabledTargets(oldTargets, targets)
+
+	return keepTargetArguments(oldTargets, targets)
 }
 
 func flattenMultiClusterApp(d *schema.ResourceData, in *managementClient.MultiClusterApp, externalID string) error {
@@ -44,19 +225,85 @@ func flattenMultiClusterApp(d *schema.ResourceData, in *managementClient.MultiCl
 	d.SetId(in.ID)
 	d.Set("name", in.Name)
 
-	err := d.Set("roles", toArrayInterface(in.Roles))
+	roles, _ := d.Get("roles").([]interface{})
+	roleDependencies, _ := d.Get("role_dependencies").([]interface{})
+	err := d.Set("roles", toArrayInterface(keepMultiClusterAppRoles(toArrayString(roles), in.Roles, toArrayString(roleDependencies))))
 	if err != nil {
 		return err
 	}
 
-	err = d.Set("targets", flattenTargets(in.Targets))
+	annotations, scales, err := flattenMultiClusterAppTargetScales(in.Annotations)
+	if err != nil {
+		return err
+	}
+	err = d.Set("targets", flattenMultiClusterAppKeptTargets(d, in.Targets, scales))
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("target_app_names", flattenMultiClusterAppTargetAppNames(in.Name, in.Targets))
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("target_apps", flattenMultiClusterAppTargetApps(in.Targets))
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("targets_in_sync", flattenMultiClusterAppTargetsInSync(in))
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("target_health_states", flattenMultiClusterAppTargetHealthStates(in.Targets))
 	if err != nil {
 		return err
 	}
 
 	d.Set("template_version_id", flattenMultiClusterAppTemplateVersionID(d, externalID))
 
-	err = d.Set("answers", flattenAnswers(in.Answers))
+	answers := flattenAnswers(in.Answers)
+	if v, ok := d.Get("answers").([]interface{}); ok && len(v) > 0 {
+		answers = keepAnswerReferences(v, answers)
+		answers = keepAnswerClusterProvider(v, answers)
+	}
+	if v, ok := d.Get("answers_object").(string); ok && len(v) > 0 {
+		values, err := answersObjectValues(v)
+		if err != nil {
+			return err
+		}
+		answers = keepAnswersObject(d.Get("answers").([]interface{}), answers, values)
+	}
+	if v, ok := d.Get("group_answers").([]interface{}); ok && len(v) > 0 {
+		overrides, err := expandMultiClusterAppGroupAnswers(oldTargets, v)
+		if err != nil {
+			return err
+		}
+		answers = keepGroupAnswers(d.Get("answers").([]interface{}), answers, overrides)
+	}
+	files, err := expandMultiClusterAppTargetAnswersYAML(oldTargets)
+	if err != nil {
+		return err
+	}
+	files = mergeTargetAnswerOverrides(files, expandMultiClusterAppTargetAnswers(oldTargets))
+	if len(files) > 0 {
+		answers = keepTargetAnswersYAML(d.Get("answers").([]interface{}), answers, files)
+	}
+	if v, ok := d.Get("sensitive_answers").(map[string]interface{}); ok && len(v) > 0 {
+		answers = keepAnswersObject(d.Get("answers").([]interface{}), answers, toMapString(v))
+	}
+	answers = keepDisabledTargetAnswers(d.Get("answers").([]interface{}), answers, disabledTargetProjectIDs(oldTargets))
+	answers = keepSkipUpdateTargetAnswers(d.Get("answers").([]interface{}), answers, skipUpdateTargetProjectIDs(oldTargets))
+	if d.Get("answers_merge_strategy").(string) == multiClusterAppAnswersMergeStrategyMerge {
+		answers = keepMergedAnswers(d.Get("answers").([]interface{}), answers)
+	}
+	err = d.Set("answers", canonicalMultiClusterAppAnswers(d.Get("answers").([]interface{}), answers))
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("effective_answers", effectiveAnswers(in.Answers, in.Targets))
 	if err != nil {
 		return err
 	}
@@ -66,18 +313,27 @@ func flattenMultiClusterApp(d *schema.ResourceData, in *managementClient.MultiCl
 		return err
 	}
 
-	d.Set("revision_history_limit", int(in.RevisionHistoryLimit))
+	err = d.Set("member_effective_permissions", flattenMemberEffectivePermissions(in.Members, in.Roles))
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("revision_history_limit", int(in.RevisionHistoryLimit))
+	if err != nil {
+		return err
+	}
 
 	if in.Status != nil {
 		d.Set("revision_id", in.Status.RevisionID)
 	}
+	d.Set("last_transition_time", flattenMultiClusterAppLastTransitionTime(in))
 
 	err = d.Set("upgrade_strategy", flattenUpgradeStrategy(in.UpgradeStrategy))
 	if err != nil {
 		return err
 	}
 
-	err = d.Set("annotations", toMapInterface(in.Annotations))
+	err = d.Set("annotations", toMapInterface(annotations))
 	if err != nil {
 		return err
 	}
@@ -90,8 +346,69 @@ func flattenMultiClusterApp(d *schema.ResourceData, in *managementClient.MultiCl
 	return nil
 }
 
+// keepMultiClusterAppRoles removes from the multi cluster app roles the auto included role dependencies,
+// unless they are also set on config
+func keepMultiClusterAppRoles(configured, in, dependencies []string) []string {
+	if len(dependencies) == 0 {
+		return in
+	}
+	isDependency := make(map[string]bool, len(dependencies))
+	for _, role := range dependencies {
+		isDependency[role] = true
+	}
+	for _, role := range configured {
+		isDependency[role] = false
+	}
+	out := make([]string, 0, len(in))
+	for _, role := range in {
+		if isDependency[role] {
+			continue
+		}
+		out = append(out, role)
+	}
+
+	return out
+}
+
+// flattenMultiClusterAppTargetScales returns the annotations without the target scale hints annotation and the hints by target project ID
+func flattenMultiClusterAppTargetScales(in map[string]string) (map[string]string, map[string]int, error) {
+	scales := map[string]int{}
+	v, ok := in[multiClusterAppTargetScaleAnnotation]
+	if !ok {
+		return in, scales, nil
+	}
+	err := jsonToInterface(v, &scales)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[ERROR] Unmarshalling multi cluster app annotation %s: %v", multiClusterAppTargetScaleAnnotation, err)
+	}
+	out := make(map[string]string, len(in)-1)
+	for k, v := range in {
+		if k != multiClusterAppTargetScaleAnnotation {
+			out[k] = v
+		}
+	}
+
+	return out, scales, nil
+}
+
 // Expanders
 
+// expandMultiClusterAppClientTimeouts returns the client_timeouts connect and read timeouts, 0 if not set
+func expandMultiClusterAppClientTimeouts(p []interface{}) (time.Duration, time.Duration) {
+	if len(p) == 0 || p[0] == nil {
+		return 0, 0
+	}
+	in := p[0].(map[string]interface{})
+	timeouts := make([]time.Duration, 2)
+	for i, key := range []string{"connect", "read"} {
+		if v, ok := in[key].(string); ok && len(v) > 0 {
+			timeouts[i], _ = time.ParseDuration(v)
+		}
+	}
+
+	return timeouts[0], timeouts[1]
+}
+
 func expandMultiClusterAppTemplateVersionID(in *schema.ResourceData) string {
 	//Template version ID: cattle-global-data:test-test-1.23.0
 
@@ -99,7 +416,896 @@ func expandMultiClusterAppTemplateVersionID(in *schema.ResourceData) string {
 	appName := in.Get("template_name").(string)
 	appVersion := in.Get("template_version").(string)
 
-	return MultiClusterAppTemplatePrefix + catalogName + "-" + appName + "-" + appVersion
+	// Template version resolved on a cluster or project catalog, following the provider catalog_resolution_order
+	if v, ok := in.Get("template_version_id").(string); ok && strings.HasSuffix(v, ":"+catalogName+"-"+appName+"-"+appVersion) {
+		return v
+	}
+
+	return multiClusterAppTemplatePrefix(in.Get) + catalogName + "-" + appName + "-" + appVersion
+}
+
+// multiClusterAppTemplatePrefix returns the template ID prefix of the catalog_scope catalog: the global catalog
+// namespace, the catalog_cluster_id cluster or the catalog_project_id project name. Empty if catalog_name is already
+// namespaced, like c-xxxxx:mycatalog.
+// get is the Get function of the resource data or diff
+func multiClusterAppTemplatePrefix(get func(string) interface{}) string {
+	if catalogName, _ := get("catalog_name").(string); strings.Contains(catalogName, ":") {
+		return ""
+	}
+	scope, _ := get("catalog_scope").(string)
+	switch scope {
+	case catalogScopeCluster:
+		clusterID, _ := get("catalog_cluster_id").(string)
+		return clusterID + ":"
+	case catalogScopeProject:
+		projectID, _ := get("catalog_project_id").(string)
+		return splitProjectIDPart(projectID) + ":"
+	}
+
+	return MultiClusterAppTemplatePrefix
+}
+
+// expandMultiClusterAppAnswers expands answers, merging the answers object and sensitive answers values on the global
+// answer, and the group answers, answers_yaml file and target answers values on the project answer of every target.
+// Target answers keys can't be also set on the target project answer. Project answers of
+// disabled targets are removed. Values are trimmed if trim_answers is true.
+// get is the Get function of the resource data or diff
+func expandMultiClusterAppAnswers(get func(string) interface{}) ([]managementClient.Answer, error) {
+	answersObject, _ := get("answers_object").(string)
+	values, err := answersObjectValues(answersObject)
+	if err != nil {
+		return nil, err
+	}
+
+	answers, _ := get("answers").([]interface{})
+	out := mergeAnswersObject(expandAnswers(answers), values)
+
+	targets, _ := get("targets").([]interface{})
+	groupAnswers, _ := get("group_answers").([]interface{})
+	overrides, err := expandMultiClusterAppGroupAnswers(targets, groupAnswers)
+	if err != nil {
+		return nil, err
+	}
+	files, err := expandMultiClusterAppTargetAnswersYAML(targets)
+	if err != nil {
+		return nil, err
+	}
+	targetAnswers := expandMultiClusterAppTargetAnswers(targets)
+	err = validateMultiClusterAppTargetAnswers(expandAnswers(answers), targetAnswers)
+	if err != nil {
+		return nil, err
+	}
+
+	out = mergeGroupAnswers(out, mergeTargetAnswerOverrides(overrides, files, targetAnswers))
+	sensitive, _ := get("sensitive_answers").(map[string]interface{})
+	out, err = mergeSensitiveAnswers(out, sensitive)
+	if err != nil {
+		return nil, err
+	}
+	out = removeDisabledTargetAnswers(out, disabledTargetProjectIDs(targets))
+	if trim, _ := get("trim_answers").(bool); trim {
+		out = trimAnswerValues(out)
+	}
+
+	return out, nil
+}
+
+// expandMultiClusterAppGroupAnswers returns the group answer values by project ID of every grouped target
+func expandMultiClusterAppGroupAnswers(targets, groupAnswers []interface{}) (map[string]map[string]string, error) {
+	groups := make(map[string]map[string]string, len(groupAnswers))
+	for _, g := range groupAnswers {
+		in, ok := g.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group := in["group"].(string)
+		if _, ok := groups[group]; ok {
+			return nil, fmt.Errorf("[ERROR] group answers for group %q are duplicated", group)
+		}
+		values := map[string]string{}
+		if v, ok := in["values"].(map[string]interface{}); ok && len(v) > 0 {
+			values = toMapString(v)
+		}
+		groups[group] = values
+	}
+
+	out := map[string]map[string]string{}
+	for _, t := range targets {
+		in, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _ := in["group"].(string)
+		if len(group) == 0 {
+			continue
+		}
+		values, ok := groups[group]
+		if !ok {
+			return nil, fmt.Errorf("[ERROR] target %v group %q has no group answers", in["project_id"], group)
+		}
+		out[in["project_id"].(string)] = values
+	}
+
+	return out, nil
+}
+
+// multiClusterAppAnswerSource is the resolved value of an answer key on a target and the scope it comes from
+type multiClusterAppAnswerSource struct {
+	Value string
+	Scope string
+}
+
+// multiClusterAppAnswerSources resolves every answer key on every target, reporting the scope setting the final value.
+// Scopes are applied by precedence: answers_object, global, cluster, group, answers_yaml, target and project answers.
+// get is the Get function of the resource data or diff
+func multiClusterAppAnswerSources(get func(string) interface{}) (map[string]map[string]multiClusterAppAnswerSource, error) {
+	answersObject, _ := get("answers_object").(string)
+	objectValues, err := answersObjectValues(answersObject)
+	if err != nil {
+		return nil, err
+	}
+	rawAnswers, _ := get("answers").([]interface{})
+	answers := expandAnswers(rawAnswers)
+	rawTargets, _ := get("targets").([]interface{})
+	groupAnswers, _ := get("group_answers").([]interface{})
+	overrides, err := expandMultiClusterAppGroupAnswers(rawTargets, groupAnswers)
+	if err != nil {
+		return nil, err
+	}
+	files, err := expandMultiClusterAppTargetAnswersYAML(rawTargets)
+	if err != nil {
+		return nil, err
+	}
+	targetAnswers := expandMultiClusterAppTargetAnswers(rawTargets)
+
+	out := map[string]map[string]multiClusterAppAnswerSource{}
+	for _, t := range expandTargets(rawTargets) {
+		clusterID, _ := clusterIDFromProjectID(t.ProjectID)
+		var global, cluster, project map[string]string
+		for _, a := range answers {
+			switch {
+			case len(a.ProjectID) > 0:
+				if a.ProjectID == t.ProjectID {
+					project = a.Values
+				}
+			case len(a.ClusterID) > 0:
+				if a.ClusterID == clusterID {
+					cluster = a.Values
+				}
+			default:
+				global = a.Values
+			}
+		}
+		sources := map[string]multiClusterAppAnswerSource{}
+		scopes := []struct {
+			name   string
+			values map[string]string
+		}{
+			{"answers_object", objectValues},
+			{"global", global},
+			{"cluster", cluster},
+			{"group", overrides[t.ProjectID]},
+			{"answers_yaml", files[t.ProjectID]},
+			{"target", targetAnswers[t.ProjectID]},
+			{"project", project},
+		}
+		for _, scope := range scopes {
+			for k, v := range scope.values {
+				sources[k] = multiClusterAppAnswerSource{Value: v, Scope: scope.name}
+			}
+		}
+		out[t.ProjectID] = sources
+	}
+
+	return out, nil
+}
+
+// mergeGroupAnswers returns a copy of answers with the group answer values added to the project answer of every
+// grouped target. Values already set on the project answer take precedence
+func mergeGroupAnswers(answers []managementClient.Answer, overrides map[string]map[string]string) []managementClient.Answer {
+	if len(overrides) == 0 {
+		return answers
+	}
+
+	out := make([]managementClient.Answer, len(answers))
+	copy(out, answers)
+	projectIDs := make([]string, 0, len(overrides))
+	for projectID := range overrides {
+		projectIDs = append(projectIDs, projectID)
+	}
+	sort.Strings(projectIDs)
+
+	for _, projectID := range projectIDs {
+		if len(overrides[projectID]) == 0 {
+			continue
+		}
+		i := -1
+		for j := range out {
+			if out[j].ProjectID == projectID {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			clusterID, _ := clusterIDFromProjectID(projectID)
+			out = append(out, managementClient.Answer{ClusterID: clusterID, ProjectID: projectID})
+			i = len(out) - 1
+		}
+		merged := make(map[string]string, len(overrides[projectID])+len(out[i].Values))
+		for k, v := range overrides[projectID] {
+			merged[k] = v
+		}
+		for k, v := range out[i].Values {
+			merged[k] = v
+		}
+		out[i].Values = merged
+	}
+
+	return out
+}
+
+// keepGroupAnswers removes from the flattened project answers the values set by group answers, unless they are also
+// set on the old project answer
+func keepGroupAnswers(old, flattened []interface{}, overrides map[string]map[string]string) []interface{} {
+	if len(overrides) == 0 {
+		return flattened
+	}
+
+	out := make([]interface{}, 0, len(flattened))
+	for _, n := range flattened {
+		newAnswer := n.(map[string]interface{})
+		projectID, _ := newAnswer["project_id"].(string)
+		values, ok := overrides[projectID]
+		if !ok {
+			out = append(out, newAnswer)
+			continue
+		}
+
+		var oldAnswer map[string]interface{}
+		for _, o := range old {
+			if a, ok := o.(map[string]interface{}); ok && a["project_id"] == projectID {
+				oldAnswer = a
+				break
+			}
+		}
+		oldValues, _ := oldAnswer["values"].(map[string]interface{})
+		newValues, _ := newAnswer["values"].(map[string]interface{})
+		for k := range values {
+			if _, ok := oldValues[k]; !ok {
+				delete(newValues, k)
+			}
+		}
+		if len(newValues) == 0 {
+			if oldAnswer == nil {
+				continue
+			}
+			delete(newAnswer, "values")
+		}
+		out = append(out, newAnswer)
+	}
+
+	return out
+}
+
+// flattenedAnswerScope returns the scope of a flattened answer, like multiClusterAppAnswerScope
+func flattenedAnswerScope(in map[string]interface{}) string {
+	if projectID, _ := in["project_id"].(string); len(projectID) > 0 {
+		return "project/" + projectID
+	}
+	if clusterID, _ := in["cluster_id"].(string); len(clusterID) > 0 {
+		return "cluster/" + clusterID
+	}
+	return "global"
+}
+
+// mergeMultiClusterAppAnswers deep merges answers over the live answers. Answers are matched by scope, project_id then
+// cluster_id, and for the same scope and key the answers value wins. Keys and scopes just answered on live are kept
+func mergeMultiClusterAppAnswers(live, answers []managementClient.Answer) []managementClient.Answer {
+	liveValues := map[string]map[string]string{}
+	for _, a := range live {
+		scope := multiClusterAppAnswerScope(a)
+		if liveValues[scope] == nil {
+			liveValues[scope] = map[string]string{}
+		}
+		for k, v := range a.Values {
+			liveValues[scope][k] = v
+		}
+	}
+
+	out := make([]managementClient.Answer, 0, len(answers)+len(live))
+	merged := map[string]bool{}
+	for _, a := range answers {
+		scope := multiClusterAppAnswerScope(a)
+		values := map[string]string{}
+		for k, v := range liveValues[scope] {
+			values[k] = v
+		}
+		for k, v := range a.Values {
+			values[k] = v
+		}
+		merged[scope] = true
+		out = append(out, managementClient.Answer{ClusterID: a.ClusterID, ProjectID: a.ProjectID, Values: values})
+	}
+	for _, a := range live {
+		scope := multiClusterAppAnswerScope(a)
+		if merged[scope] {
+			continue
+		}
+		merged[scope] = true
+		out = append(out, managementClient.Answer{ClusterID: a.ClusterID, ProjectID: a.ProjectID, Values: liveValues[scope]})
+	}
+
+	return out
+}
+
+// keepMergedAnswers removes from the flattened answers the scopes and keys not set on old answers, which are just
+// merged on update and not managed. Flattened answers are kept as is if there are no old answers, e.g. on import
+func keepMergedAnswers(old, flattened []interface{}) []interface{} {
+	if len(old) == 0 {
+		return flattened
+	}
+	oldValues := map[string]map[string]interface{}{}
+	for _, o := range old {
+		oldAnswer, ok := o.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		values, _ := oldAnswer["values"].(map[string]interface{})
+		oldValues[flattenedAnswerScope(oldAnswer)] = values
+	}
+
+	out := make([]interface{}, 0, len(flattened))
+	for _, f := range flattened {
+		answer, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		keys, ok := oldValues[flattenedAnswerScope(answer)]
+		if !ok {
+			continue
+		}
+		values := map[string]interface{}{}
+		if flattenedValues, ok := answer["values"].(map[string]interface{}); ok {
+			for k, v := range flattenedValues {
+				if _, ok := keys[k]; ok {
+					values[k] = v
+				}
+			}
+		}
+		obj := map[string]interface{}{}
+		for k, v := range answer {
+			obj[k] = v
+		}
+		obj["values"] = values
+		out = append(out, obj)
+	}
+
+	return out
+}
+
+// canonicalMultiClusterAppAnswers returns answers in their canonical representation: every answer sets cluster_id and
+// project_id, values are strings and empty values are unset. Answers keep the order of the old answers, matching them by
+// project_id or cluster_id, and new ones are appended as global, cluster and project answers sorted by ID
+func canonicalMultiClusterAppAnswers(old, answers []interface{}) []interface{} {
+	scopeKey := flattenedAnswerScope
+	order := map[string]int{}
+	for i, o := range old {
+		if in, ok := o.(map[string]interface{}); ok {
+			if _, ok := order[scopeKey(in)]; !ok {
+				order[scopeKey(in)] = i
+			}
+		}
+	}
+
+	out := make([]interface{}, 0, len(answers))
+	for _, a := range answers {
+		in, ok := a.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		obj := map[string]interface{}{
+			"cluster_id": "",
+			"project_id": "",
+		}
+		if v, ok := in["cluster_id"].(string); ok {
+			obj["cluster_id"] = v
+		}
+		if v, ok := in["project_id"].(string); ok {
+			obj["project_id"] = v
+		}
+		values := map[string]interface{}{}
+		switch v := in["values"].(type) {
+		case map[string]interface{}:
+			for key, value := range v {
+				if value != nil {
+					values[key] = fmt.Sprint(value)
+				}
+			}
+		case map[string]string:
+			values = toMapInterface(v)
+		}
+		if len(values) > 0 {
+			obj["values"] = values
+		}
+		out = append(out, obj)
+	}
+
+	rank := func(in map[string]interface{}) (int, int, string) {
+		if i, ok := order[scopeKey(in)]; ok {
+			return 0, i, ""
+		}
+		switch {
+		case len(in["project_id"].(string)) > 0:
+			return 3, 0, scopeKey(in)
+		case len(in["cluster_id"].(string)) > 0:
+			return 2, 0, scopeKey(in)
+		}
+		return 1, 0, ""
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		gi, oi, ki := rank(out[i].(map[string]interface{}))
+		gj, oj, kj := rank(out[j].(map[string]interface{}))
+		if gi != gj {
+			return gi < gj
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		return ki < kj
+	})
+
+	return out
+}
+
+// importMultiClusterAppAnswers returns the imported answers without the answers of projects not targeted anymore, and
+// with the project answers sorted as their targets
+func importMultiClusterAppAnswers(answers, targets []interface{}) []interface{} {
+	targetIndex := map[string]int{}
+	for i, t := range targets {
+		if target, ok := t.(map[string]interface{}); ok {
+			if projectID, ok := target["project_id"].(string); ok {
+				targetIndex[projectID] = i
+			}
+		}
+	}
+
+	out := []interface{}{}
+	projectAnswers := []interface{}{}
+	for _, a := range answers {
+		answer, ok := a.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		projectID, _ := answer["project_id"].(string)
+		if len(projectID) == 0 {
+			out = append(out, answer)
+			continue
+		}
+		if _, ok := targetIndex[projectID]; !ok {
+			log.Printf("[INFO] Dropping imported multi cluster app answers of not targeted project %s", projectID)
+			continue
+		}
+		projectAnswers = append(projectAnswers, answer)
+	}
+	sort.SliceStable(projectAnswers, func(i, j int) bool {
+		return targetIndex[projectAnswers[i].(map[string]interface{})["project_id"].(string)] < targetIndex[projectAnswers[j].(map[string]interface{})["project_id"].(string)]
+	})
+
+	return append(out, projectAnswers...)
+}
+
+// multiClusterAppGitOpsOwner returns the GitOps controller owning a multi cluster app, detected from its labels and
+// annotations, or empty if none
+func multiClusterAppGitOpsOwner(labels, annotations map[string]string) string {
+	for _, in := range []map[string]string{labels, annotations} {
+		keys := make([]string, 0, len(in))
+		for k := range in {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			switch {
+			case strings.HasPrefix(k, "fleet.cattle.io/"), k == "objectset.rio.cattle.io/id" && strings.HasPrefix(in[k], "fleet-"):
+				return "fleet"
+			case strings.HasPrefix(k, "argocd.argoproj.io/"):
+				return "argocd"
+			case strings.HasPrefix(k, "kustomize.toolkit.fluxcd.io/"), strings.HasPrefix(k, "helm.toolkit.fluxcd.io/"):
+				return "flux"
+			}
+		}
+	}
+	switch managedBy := strings.ToLower(labels["app.kubernetes.io/managed-by"]); managedBy {
+	case "fleet", "fleetagent":
+		return "fleet"
+	case "argocd", "flux":
+		return managedBy
+	}
+
+	return ""
+}
+
+// keepDisabledTargets inserts on flattened targets the old targets with enabled false, at their old index, unless
+// they are still deployed
+func keepDisabledTargets(old, flattened []interface{}) []interface{} {
+	deployed := map[interface{}]bool{}
+	for _, n := range flattened {
+		deployed[n.(map[string]interface{})["project_id"]] = true
+	}
+
+	out := make([]interface{}, len(flattened))
+	copy(out, flattened)
+	for i, o := range old {
+		oldTarget, ok := o.(map[string]interface{})
+		if !ok || deployed[oldTarget["project_id"]] {
+			continue
+		}
+		if v, ok := oldTarget["enabled"].(bool); !ok || v {
+			continue
+		}
+		if i > len(out) {
+			i = len(out)
+		}
+		out = append(out[:i], append([]interface{}{oldTarget}, out[i:]...)...)
+	}
+
+	return out
+}
+
+// keepDisabledTargetAnswers appends to the flattened answers the old project answers of disabled targets, which
+// aren't deployed
+func keepDisabledTargetAnswers(old, flattened []interface{}, disabled map[string]bool) []interface{} {
+	if len(disabled) == 0 {
+		return flattened
+	}
+	found := map[string]bool{}
+	for _, n := range flattened {
+		projectID, _ := n.(map[string]interface{})["project_id"].(string)
+		found[projectID] = true
+	}
+	for _, o := range old {
+		oldAnswer, ok := o.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		projectID, _ := oldAnswer["project_id"].(string)
+		if disabled[projectID] && !found[projectID] {
+			flattened = append(flattened, oldAnswer)
+		}
+	}
+
+	return flattened
+}
+
+// removeDisabledTargetAnswers removes the project answers of disabled targets
+func removeDisabledTargetAnswers(answers []managementClient.Answer, disabled map[string]bool) []managementClient.Answer {
+	if len(disabled) == 0 {
+		return answers
+	}
+	out := make([]managementClient.Answer, 0, len(answers))
+	for _, a := range answers {
+		if len(a.ProjectID) > 0 && disabled[a.ProjectID] {
+			continue
+		}
+		out = append(out, a)
+	}
+
+	return out
+}
+
+// pinSkipUpdateTargetAnswers sets the project answer of every mca target skipping updates to its current effective
+// answer values, so answer changes on other scopes don't reach it. Keys first answered on other scopes still do
+func pinSkipUpdateTargetAnswers(answers []managementClient.Answer, mca *managementClient.MultiClusterApp, skipped map[string]bool) []managementClient.Answer {
+	if len(skipped) == 0 {
+		return answers
+	}
+	pinned := map[string]map[string]string{}
+	for _, t := range mca.Targets {
+		if skipped[t.ProjectID] {
+			pinned[t.ProjectID] = effectiveAnswerValues(mca.Answers, t.ProjectID)
+		}
+	}
+	if len(pinned) == 0 {
+		return answers
+	}
+
+	out := make([]managementClient.Answer, 0, len(answers)+len(pinned))
+	for _, a := range answers {
+		if len(a.ProjectID) > 0 && pinned[a.ProjectID] != nil {
+			continue
+		}
+		out = append(out, a)
+	}
+	for _, t := range mca.Targets {
+		values, ok := pinned[t.ProjectID]
+		if !ok || len(values) == 0 {
+			continue
+		}
+		out = append(out, managementClient.Answer{ProjectID: t.ProjectID, Values: values})
+		delete(pinned, t.ProjectID)
+	}
+
+	return out
+}
+
+// keepSkipUpdateTargetAnswers replaces the flattened project answers of targets skipping updates, pinned on update,
+// by their old project answers, if any
+func keepSkipUpdateTargetAnswers(old, flattened []interface{}, skipped map[string]bool) []interface{} {
+	if len(skipped) == 0 {
+		return flattened
+	}
+	out := make([]interface{}, 0, len(flattened))
+	for _, n := range flattened {
+		projectID, _ := n.(map[string]interface{})["project_id"].(string)
+		if skipped[projectID] {
+			continue
+		}
+		out = append(out, n)
+	}
+	for _, o := range old {
+		oldAnswer, ok := o.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if projectID, _ := oldAnswer["project_id"].(string); skipped[projectID] {
+			out = append(out, oldAnswer)
+		}
+	}
+
+	return out
+}
+
+// keepTargetArguments restores on flattened targets the group, answers_yaml, answers, priority and skip_update set on
+// old targets, matching them by project ID
+func keepTargetArguments(old, flattened []interface{}) []interface{} {
+	for _, o := range old {
+		oldTarget, ok := o.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range []string{"group", "answers_yaml"} {
+			value, _ := oldTarget[key].(string)
+			if len(value) == 0 {
+				continue
+			}
+			for _, n := range flattened {
+				newTarget := n.(map[string]interface{})
+				if newTarget["project_id"] == oldTarget["project_id"] {
+					newTarget[key] = value
+				}
+			}
+		}
+		if priority, _ := oldTarget["priority"].(int); priority != 0 {
+			for _, n := range flattened {
+				newTarget := n.(map[string]interface{})
+				if newTarget["project_id"] == oldTarget["project_id"] {
+					newTarget["priority"] = priority
+				}
+			}
+		}
+		if values, _ := oldTarget["answers"].(map[string]interface{}); len(values) > 0 {
+			for _, n := range flattened {
+				newTarget := n.(map[string]interface{})
+				if newTarget["project_id"] == oldTarget["project_id"] {
+					newTarget["answers"] = values
+				}
+			}
+		}
+		if skip, _ := oldTarget["skip_update"].(bool); skip {
+			for _, n := range flattened {
+				newTarget := n.(map[string]interface{})
+				if newTarget["project_id"] == oldTarget["project_id"] {
+					newTarget["skip_update"] = true
+				}
+			}
+		}
+	}
+
+	return flattened
+}
+
+// keepTargetAnswersYAML removes from the flattened project answers the values equal to the answers_yaml file or target
+// answers values, unless they are also set on the old project answer. Values changed on them are kept, so they are planned
+func keepTargetAnswersYAML(old, flattened []interface{}, files map[string]map[string]string) []interface{} {
+	out := make([]interface{}, 0, len(flattened))
+	for _, n := range flattened {
+		newAnswer := n.(map[string]interface{})
+		projectID, _ := newAnswer["project_id"].(string)
+		values, ok := files[projectID]
+		if !ok {
+			out = append(out, newAnswer)
+			continue
+		}
+
+		var oldAnswer map[string]interface{}
+		for _, o := range old {
+			if a, ok := o.(map[string]interface{}); ok && a["project_id"] == projectID {
+				oldAnswer = a
+				break
+			}
+		}
+		oldValues, _ := oldAnswer["values"].(map[string]interface{})
+		newValues, _ := newAnswer["values"].(map[string]interface{})
+		for k, v := range values {
+			if _, ok := oldValues[k]; !ok && newValues[k] == v {
+				delete(newValues, k)
+			}
+		}
+		if len(newValues) == 0 {
+			if oldAnswer == nil {
+				continue
+			}
+			delete(newAnswer, "values")
+		}
+		out = append(out, newAnswer)
+	}
+
+	return out
+}
+
+// expandMultiClusterAppTargetAnswers returns the answers values by project ID of every target setting them
+func expandMultiClusterAppTargetAnswers(targets []interface{}) map[string]map[string]string {
+	out := map[string]map[string]string{}
+	for _, t := range targets {
+		in, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if v, ok := in["answers"].(map[string]interface{}); ok && len(v) > 0 {
+			out[in["project_id"].(string)] = toMapString(v)
+		}
+	}
+
+	return out
+}
+
+// validateMultiClusterAppTargetAnswers returns error if a target answers key is also set on the project answer of
+// the same target
+func validateMultiClusterAppTargetAnswers(answers []managementClient.Answer, targetAnswers map[string]map[string]string) error {
+	for _, a := range answers {
+		values, ok := targetAnswers[a.ProjectID]
+		if len(a.ProjectID) == 0 || !ok {
+			continue
+		}
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			if _, ok := a.Values[k]; ok {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) > 0 {
+			sort.Strings(keys)
+			return fmt.Errorf("[ERROR] target %s answers %v are also set on its project answers, set them on just one of them", a.ProjectID, keys)
+		}
+	}
+
+	return nil
+}
+
+// expandMultiClusterAppTargetAnswersYAML returns the answers_yaml file values by project ID of every target setting it
+func expandMultiClusterAppTargetAnswersYAML(targets []interface{}) (map[string]map[string]string, error) {
+	out := map[string]map[string]string{}
+	for _, t := range targets {
+		in, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path, _ := in["answers_yaml"].(string)
+		if len(path) == 0 {
+			continue
+		}
+		values, err := targetAnswersYAMLValues(path)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] target %v answers_yaml: %v", in["project_id"], err)
+		}
+		out[in["project_id"].(string)] = values
+	}
+
+	return out, nil
+}
+
+// targetAnswersYAMLValues reads the YAML values file at path as dotted answer keys
+func targetAnswersYAMLValues(path string) (map[string]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", path, err)
+	}
+
+	return answersObjectValues(string(content))
+}
+
+// mergeTargetAnswerOverrides merges the answer values by project ID of overrides, later ones taking precedence
+func mergeTargetAnswerOverrides(overrides ...map[string]map[string]string) map[string]map[string]string {
+	out := map[string]map[string]string{}
+	for _, o := range overrides {
+		for projectID, values := range o {
+			if _, ok := out[projectID]; !ok {
+				out[projectID] = map[string]string{}
+			}
+			for k, v := range values {
+				out[projectID][k] = v
+			}
+		}
+	}
+
+	return out
+}
+
+// removeClusterTemplateTargets removes from flattened targets the ones resolved from target_from_cluster_template or
+// target_fleet_workspace, unless they are also set on old targets
+func removeClusterTemplateTargets(old, flattened []interface{}, templateTargets []string) []interface{} {
+	if len(templateTargets) == 0 {
+		return flattened
+	}
+	configured := map[string]bool{}
+	for _, o := range old {
+		if oldTarget, ok := o.(map[string]interface{}); ok {
+			configured[oldTarget["project_id"].(string)] = true
+		}
+	}
+	fromTemplate := map[string]bool{}
+	for _, projectID := range templateTargets {
+		fromTemplate[projectID] = !configured[projectID]
+	}
+
+	out := make([]interface{}, 0, len(flattened))
+	for _, n := range flattened {
+		if fromTemplate[n.(map[string]interface{})["project_id"].(string)] {
+			continue
+		}
+		out = append(out, n)
+	}
+
+	return out
+}
+
+// appendClusterTemplateTargets appends to targets the project IDs resolved from target_from_cluster_template or
+// target_fleet_workspace not already targeted
+func appendClusterTemplateTargets(targets []managementClient.Target, templateTargets []string) []managementClient.Target {
+	for _, projectID := range templateTargets {
+		found := false
+		for _, t := range targets {
+			if t.ProjectID == projectID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			targets = append(targets, managementClient.Target{ProjectID: projectID})
+		}
+	}
+
+	return targets
+}
+
+// expandMultiClusterAppAnnotations returns the annotations adding the target scale hints as JSON annotation, if any
+func expandMultiClusterAppAnnotations(annotations map[string]interface{}, targets []interface{}) (map[string]string, error) {
+	out := toMapString(annotations)
+	scales := map[string]int{}
+	for _, t := range targets {
+		in, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if enabled, ok := in["enabled"].(bool); ok && !enabled {
+			continue
+		}
+		if scale, ok := in["scale"].(int); ok && scale > 0 {
+			scales[in["project_id"].(string)] = scale
+		}
+	}
+	if len(scales) == 0 {
+		return out, nil
+	}
+	if _, ok := out[multiClusterAppTargetScaleAnnotation]; ok {
+		return nil, fmt.Errorf("[ERROR] annotation %s is managed by targets scale", multiClusterAppTargetScaleAnnotation)
+	}
+	v, err := interfaceToJSON(scales)
+	if err != nil {
+		return nil, err
+	}
+	out[multiClusterAppTargetScaleAnnotation] = v
+
+	return out, nil
 }
 
 func expandMultiClusterApp(in *schema.ResourceData) (*managementClient.MultiClusterApp, error) {
@@ -124,8 +1330,12 @@ func expandMultiClusterApp(in *schema.ResourceData) (*managementClient.MultiClus
 
 	obj.TemplateVersionID = expandMultiClusterAppTemplateVersionID(in)
 
-	if v, ok := in.Get("answers").([]interface{}); ok && len(v) > 0 {
-		obj.Answers = expandAnswers(v)
+	answers, err := expandMultiClusterAppAnswers(in.Get)
+	if err != nil {
+		return nil, err
+	}
+	if len(answers) > 0 {
+		obj.Answers = answers
 	}
 
 	if v, ok := in.Get("members").([]interface{}); ok && len(v) > 0 {
@@ -147,8 +1357,12 @@ func expandMultiClusterApp(in *schema.ResourceData) (*managementClient.MultiClus
 		obj.UpgradeStrategy = expandUpgradeStrategy(v)
 	}
 
-	if v, ok := in.Get("annotations").(map[string]interface{}); ok && len(v) > 0 {
-		obj.Annotations = toMapString(v)
+	annotations, err := expandMultiClusterAppAnnotations(in.Get("annotations").(map[string]interface{}), in.Get("targets").([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	if len(annotations) > 0 {
+		obj.Annotations = annotations
 	}
 
 	if v, ok := in.Get("labels").(map[string]interface{}); ok && len(v) > 0 {
@@ -157,3 +1371,84 @@ func expandMultiClusterApp(in *schema.ResourceData) (*managementClient.MultiClus
 
 	return obj, nil
 }
+
+// multiClusterAppTargetRequests returns the chart resource requests of a target, from the target answer values or the
+// question defaults, multiplied by replicaCount if set
+func multiClusterAppTargetRequests(questions []managementClient.Question, values map[string]string) (map[string]resource.Quantity, error) {
+	merged := map[string]string{}
+	for _, q := range questions {
+		if len(q.Default) > 0 {
+			merged[q.Variable] = q.Default
+		}
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+
+	replicas := 1
+	if v, err := strconv.Atoi(merged[multiClusterAppReplicaCountAnswer]); err == nil && v > 0 {
+		replicas = v
+	}
+	out := map[string]resource.Quantity{}
+	for name, key := range multiClusterAppRequestAnswers {
+		if len(merged[key]) == 0 {
+			continue
+		}
+		request, err := resource.ParseQuantity(merged[key])
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] parsing answer %s=%q: %v", key, merged[key], err)
+		}
+		total := request.DeepCopy()
+		for i := 1; i < replicas; i++ {
+			total.Add(request)
+		}
+		out[name] = total
+	}
+
+	return out, nil
+}
+
+// multiClusterAppQuotaShortages returns the requests exceeding the project resource quota headroom, limit minus used
+func multiClusterAppQuotaShortages(quota *managementClient.ProjectResourceQuota, requests map[string]resource.Quantity) ([]string, error) {
+	if quota == nil || quota.Limit == nil {
+		return nil, nil
+	}
+	limits := map[string]string{
+		"cpu":    quota.Limit.RequestsCPU,
+		"memory": quota.Limit.RequestsMemory,
+	}
+	used := map[string]string{}
+	if quota.UsedLimit != nil {
+		used["cpu"] = quota.UsedLimit.RequestsCPU
+		used["memory"] = quota.UsedLimit.RequestsMemory
+	}
+
+	names := make([]string, 0, len(requests))
+	for name := range requests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := []string{}
+	for _, name := range names {
+		if len(limits[name]) == 0 {
+			continue
+		}
+		headroom, err := resource.ParseQuantity(limits[name])
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] parsing project quota requests %s limit %q: %v", name, limits[name], err)
+		}
+		if len(used[name]) > 0 {
+			usedQuantity, err := resource.ParseQuantity(used[name])
+			if err != nil {
+				return nil, fmt.Errorf("[ERROR] parsing project quota requests %s used %q: %v", name, used[name], err)
+			}
+			headroom.Sub(usedQuantity)
+		}
+		request := requests[name]
+		if request.Cmp(headroom) > 0 {
+			out = append(out, fmt.Sprintf("%s requests %s exceed quota headroom %s", name, request.String(), headroom.String()))
+		}
+	}
+
+	return out, nil
+}
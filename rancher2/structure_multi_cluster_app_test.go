@@ -1,10 +1,14 @@
 package rancher2
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	managementClient "github.com/rancher/rancher/pkg/client/generated/management/v3"
 	"github.com/stretchr/testify/assert"
 )
@@ -38,6 +42,11 @@ func init() {
 	testMultiClusterAppTargetsInterface = []interface{}{
 		map[string]interface{}{
 			"project_id":   "project_id",
+			"group":        "",
+			"scale":        0,
+			"priority":     0,
+			"answers_yaml": "",
+			"enabled":      true,
 			"app_id":       "app_id",
 			"health_state": "health_state",
 			"state":        "state",
@@ -198,3 +207,764 @@ func TestExpandMultiClusterApp(t *testing.T) {
 		}
 	}
 }
+
+func TestFlattenMultiClusterAppTargetAppNames(t *testing.T) {
+	targets := []managementClient.Target{
+		{
+			ProjectID: "c-abcde:p-one",
+			AppID:     "mcapp-foo-custom",
+		},
+		{
+			ProjectID: "c-abcde:p-two",
+		},
+	}
+	expected := map[string]interface{}{
+		"c-abcde:p-one": "mcapp-foo-custom",
+		"c-abcde:p-two": "mcapp-foo",
+	}
+
+	output := flattenMultiClusterAppTargetAppNames("foo", targets)
+	assert.Equal(t, expected, output, "Unexpected output from flattener.")
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	err := flattenMultiClusterApp(d, testMultiClusterAppConf, testMultiClusterAppExternalID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"project_id": "app_id"}, d.Get("target_app_names"))
+}
+
+func TestFlattenMultiClusterAppTargetApps(t *testing.T) {
+	targets := []managementClient.Target{
+		{
+			ProjectID: "c-abcde:p-one",
+			AppID:     "p-one:mcapp-foo",
+			State:     "active",
+		},
+		{
+			ProjectID: "c-abcde:p-two",
+		},
+		{
+			AppID: "p-three:mcapp-foo",
+		},
+	}
+	expected := []interface{}{
+		map[string]interface{}{"project_id": "c-abcde:p-one", "app_id": "p-one:mcapp-foo", "state": "active"},
+		map[string]interface{}{"project_id": "c-abcde:p-two", "app_id": "", "state": ""},
+	}
+
+	output := flattenMultiClusterAppTargetApps(targets)
+	assert.Equal(t, expected, output, "Unexpected output from flattener.")
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	err := flattenMultiClusterApp(d, testMultiClusterAppConf, testMultiClusterAppExternalID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, d.Get("target_apps.#"))
+	assert.Equal(t, "app_id", d.Get("target_apps.0.app_id"))
+}
+
+func TestFlattenMultiClusterAppTargetsInSync(t *testing.T) {
+	cases := []struct {
+		state    string
+		targets  []managementClient.Target
+		expected bool
+	}{
+		{
+			state: "active",
+			targets: []managementClient.Target{
+				{ProjectID: "c-abcde:p-one", State: "active"},
+				{ProjectID: "c-abcde:p-two", State: "active"},
+			},
+			expected: true,
+		},
+		{
+			state: "active",
+			targets: []managementClient.Target{
+				{ProjectID: "c-abcde:p-one", State: "active"},
+				{ProjectID: "c-abcde:p-two", State: "installing"},
+			},
+			expected: false,
+		},
+		{
+			state: "updating",
+			targets: []managementClient.Target{
+				{ProjectID: "c-abcde:p-one", State: "active"},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		in := &managementClient.MultiClusterApp{State: tc.state, Targets: tc.targets}
+		assert.Equal(t, tc.expected, flattenMultiClusterAppTargetsInSync(in), "Unexpected targets in sync for %#v", tc.targets)
+
+		d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+		conf := *testMultiClusterAppConf
+		conf.State = tc.state
+		conf.Targets = tc.targets
+		err := flattenMultiClusterApp(d, &conf, testMultiClusterAppExternalID)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, d.Get("targets_in_sync"))
+	}
+}
+
+func TestFlattenMultiClusterAppLastTransitionTime(t *testing.T) {
+	in := *testMultiClusterAppConf
+	in.Status = &managementClient.MultiClusterAppStatus{
+		Conditions: []managementClient.AppCondition{
+			{Type: "Deployed", Status: "True", LastTransitionTime: "2021-03-01T10:00:00Z"},
+			{Type: "Installed", Status: "True", LastTransitionTime: "2021-03-02T12:30:00+02:00"},
+			{Type: "Migrated", Status: "True", LastTransitionTime: "not a timestamp"},
+			{Type: "Pending", Status: "Unknown"},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	err := flattenMultiClusterApp(d, &in, testMultiClusterAppExternalID)
+	assert.NoError(t, err)
+	assert.Equal(t, "2021-03-02T10:30:00Z", d.Get("last_transition_time"))
+
+	in.Status = &managementClient.MultiClusterAppStatus{}
+	err = flattenMultiClusterApp(d, &in, testMultiClusterAppExternalID)
+	assert.NoError(t, err)
+	assert.Equal(t, "", d.Get("last_transition_time"))
+}
+
+func TestFlattenMultiClusterAppTargetHealthStates(t *testing.T) {
+	in := *testMultiClusterAppConf
+	in.Targets = []managementClient.Target{
+		{
+			ProjectID:   "c-abcde:p-one",
+			AppID:       "mcapp-foo",
+			Healthstate: "healthy",
+			State:       "active",
+		},
+		{
+			ProjectID:   "c-abcde:p-two",
+			AppID:       "mcapp-foo",
+			Healthstate: "unhealthy",
+			State:       "active",
+		},
+		{
+			ProjectID: "c-abcde:p-three",
+			State:     "deploying",
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	err := flattenMultiClusterApp(d, &in, testMultiClusterAppExternalID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"c-abcde:p-one": "healthy",
+		"c-abcde:p-two": "unhealthy",
+	}, d.Get("target_health_states"))
+	assert.Equal(t, "healthy", d.Get("targets.0.health_state"))
+	assert.Equal(t, "unhealthy", d.Get("targets.1.health_state"))
+	assert.Equal(t, "", d.Get("targets.2.health_state"))
+}
+
+func TestExpandMultiClusterAppGroupAnswers(t *testing.T) {
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"targets": []interface{}{
+			map[string]interface{}{"project_id": "c-dev:p-one", "group": "dev"},
+			map[string]interface{}{"project_id": "c-prod:p-one", "group": "prod"},
+			map[string]interface{}{"project_id": "c-prod:p-two", "group": "prod"},
+			map[string]interface{}{"project_id": "c-other:p-one"},
+		},
+		"answers": []interface{}{
+			map[string]interface{}{
+				"values": map[string]interface{}{"replicaCount": "1", "ingress.host": "example.com"},
+			},
+			map[string]interface{}{
+				"project_id": "c-prod:p-two",
+				"values":     map[string]interface{}{"replicaCount": "5"},
+			},
+		},
+		"group_answers": []interface{}{
+			map[string]interface{}{
+				"group":  "dev",
+				"values": map[string]interface{}{"debug": "true"},
+			},
+			map[string]interface{}{
+				"group":  "prod",
+				"values": map[string]interface{}{"replicaCount": "3", "resources.limits.cpu": "2"},
+			},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+
+	answers, err := expandMultiClusterAppAnswers(d.Get)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"replicaCount": "1", "ingress.host": "example.com", "debug": "true"}, effectiveAnswerValues(answers, "c-dev:p-one"))
+	assert.Equal(t, map[string]string{"replicaCount": "3", "ingress.host": "example.com", "resources.limits.cpu": "2"}, effectiveAnswerValues(answers, "c-prod:p-one"))
+	assert.Equal(t, map[string]string{"replicaCount": "5", "ingress.host": "example.com", "resources.limits.cpu": "2"}, effectiveAnswerValues(answers, "c-prod:p-two"), "Project answers should take precedence over group answers")
+	assert.Equal(t, map[string]string{"replicaCount": "1", "ingress.host": "example.com"}, effectiveAnswerValues(answers, "c-other:p-one"))
+
+	// Group answers and target groups are not read back
+	mca := &managementClient.MultiClusterApp{
+		Name:                 "foo",
+		Answers:              answers,
+		RevisionHistoryLimit: 10,
+		Targets: []managementClient.Target{
+			{ProjectID: "c-dev:p-one"},
+			{ProjectID: "c-prod:p-one"},
+			{ProjectID: "c-prod:p-two"},
+			{ProjectID: "c-other:p-one"},
+		},
+	}
+	err = flattenMultiClusterApp(d, mca, testMultiClusterAppExternalID)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"cluster_id": "",
+			"project_id": "",
+			"values":     map[string]interface{}{"replicaCount": "1", "ingress.host": "example.com"},
+		},
+		map[string]interface{}{
+			"cluster_id": "",
+			"project_id": "c-prod:p-two",
+			"values":     map[string]interface{}{"replicaCount": "5"},
+		},
+	}, d.Get("answers"))
+	assert.Equal(t, "prod", d.Get("targets.2.group"))
+
+	// Referenced groups must exist
+	config["group_answers"] = config["group_answers"].([]interface{})[:1]
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	_, err = expandMultiClusterAppAnswers(d.Get)
+	assert.Error(t, err)
+}
+
+func TestFlattenMultiClusterAppTemplateCategories(t *testing.T) {
+	cases := []struct {
+		Input          *managementClient.Template
+		ExpectedOutput []interface{}
+	}{
+		{
+			&managementClient.Template{
+				Category:   "Monitoring",
+				Categories: []string{"Monitoring", "Observability", ""},
+			},
+			[]interface{}{"Monitoring", "Observability"},
+		},
+		{
+			&managementClient.Template{
+				Categories: []string{"Storage"},
+			},
+			[]interface{}{"Storage"},
+		},
+		{
+			nil,
+			[]interface{}{},
+		},
+	}
+	for _, tc := range cases {
+		output := flattenMultiClusterAppTemplateCategories(tc.Input)
+		assert.Equal(t, tc.ExpectedOutput, output, "Unexpected output from flattener.")
+	}
+
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	err := d.Set("template_categories", flattenMultiClusterAppTemplateCategories(cases[0].Input))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"Monitoring", "Observability"}, d.Get("template_categories"))
+}
+
+func TestMultiClusterAppTrimAnswers(t *testing.T) {
+	testConfig := func(trim bool, host string) map[string]interface{} {
+		return map[string]interface{}{
+			"catalog_name":     "test",
+			"name":             "foo",
+			"roles":            []interface{}{"role1"},
+			"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+			"template_name":    "test-demo",
+			"template_version": "1.23.0",
+			"trim_answers":     trim,
+			"answers": []interface{}{
+				map[string]interface{}{
+					"values": map[string]interface{}{"ingress.host": host},
+				},
+			},
+		}
+	}
+
+	for _, trim := range []bool{true, false} {
+		// State holds the answer value trimmed by Rancher
+		d := schema.TestResourceDataRaw(t, multiClusterAppFields(), testConfig(trim, "example.com"))
+		d.SetId("cattle-global-data:foo")
+
+		config := testConfig(trim, "example.com\n  ")
+		diff, err := resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+		assert.NoError(t, err)
+		hasDiff := diff != nil && diff.Attributes["answers.0.values.ingress.host"] != nil
+		assert.Equal(t, !trim, hasDiff, "Unexpected whitespace only answers diff with trim_answers %t", trim)
+
+		d = schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+		answers, err := expandMultiClusterAppAnswers(d.Get)
+		assert.NoError(t, err)
+		if trim {
+			assert.Equal(t, "example.com", answers[0].Values["ingress.host"])
+		} else {
+			assert.Equal(t, "example.com\n  ", answers[0].Values["ingress.host"])
+		}
+	}
+}
+
+func TestMultiClusterAppNormalizedAnswersDiff(t *testing.T) {
+	testConfig := func(value string) map[string]interface{} {
+		return map[string]interface{}{
+			"catalog_name":     "test",
+			"name":             "foo",
+			"roles":            []interface{}{"role1"},
+			"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+			"template_name":    "test-demo",
+			"template_version": "1.23.0",
+			"answers": []interface{}{
+				map[string]interface{}{
+					"values": map[string]interface{}{"value": value},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		State    string
+		Config   string
+		Suppress bool
+	}{
+		{"true", "True", true},
+		{"true", "1", true},
+		{"false", "FALSE", true},
+		{"0", "false", true},
+		{"true", "false", false},
+		{"1", "2", false},
+		{"v1.2.3", "v1.2.4", false},
+		{"image", "Image", false},
+	}
+
+	for _, tc := range cases {
+		d := schema.TestResourceDataRaw(t, multiClusterAppFields(), testConfig(tc.State))
+		d.SetId("cattle-global-data:foo")
+
+		diff, err := resourceRancher2MultiClusterApp().Diff(d.State(), terraform.NewResourceConfigRaw(testConfig(tc.Config)), nil)
+		assert.NoError(t, err)
+		hasDiff := diff != nil && diff.Attributes["answers.0.values.value"] != nil
+		assert.Equal(t, !tc.Suppress, hasDiff, "Unexpected answers diff from %q to %q", tc.State, tc.Config)
+	}
+}
+
+func TestExpandMultiClusterAppTemplateVersionIDFromExternalID(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"catalog_name":     "test",
+		"template_name":    "test-demo",
+		"template_version": "catalog://?catalog=test&template=test-demo&version=1.23.0",
+	})
+
+	assert.Equal(t, "1.23.0", d.Get("template_version"))
+	assert.Equal(t, "cattle-global-data:test-test-demo-1.23.0", expandMultiClusterAppTemplateVersionID(d))
+	assert.Equal(t, "1.23.0", normalizeMultiClusterAppTemplateVersion("1.23.0"))
+}
+
+func TestExpandMultiClusterAppTemplateVersionIDCatalogScope(t *testing.T) {
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	assert.Equal(t, "cattle-global-data:test-test-demo-1.23.0", expandMultiClusterAppTemplateVersionID(d), "Global catalog scope should be the default")
+
+	config["catalog_scope"] = "cluster"
+	config["catalog_cluster_id"] = "c-abcde"
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	assert.Equal(t, "c-abcde:test-test-demo-1.23.0", expandMultiClusterAppTemplateVersionID(d))
+
+	delete(config, "catalog_cluster_id")
+	config["catalog_scope"] = "project"
+	config["catalog_project_id"] = "c-abcde:p-fghij"
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	assert.Equal(t, "p-fghij:test-test-demo-1.23.0", expandMultiClusterAppTemplateVersionID(d))
+
+	namespaced := map[string]interface{}{
+		"catalog_name":     "c-abcde:test",
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+	}
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), namespaced)
+	assert.Equal(t, "c-abcde:test-test-demo-1.23.0", expandMultiClusterAppTemplateVersionID(d), "Namespaced catalog should not be prefixed")
+
+	config["name"] = "foo"
+	config["roles"] = []interface{}{"role1"}
+	config["targets"] = []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}}
+	_, err := resourceRancher2MultiClusterApp().Diff(nil, terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err)
+
+	config["catalog_scope"] = "cluster"
+	_, err = resourceRancher2MultiClusterApp().Diff(nil, terraform.NewResourceConfigRaw(config), nil)
+	if assert.Error(t, err, "Cluster catalog scope should require catalog_cluster_id") {
+		assert.Contains(t, err.Error(), "catalog_cluster_id is required")
+	}
+}
+
+func TestMultiClusterAppAnswerSources(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{
+		"answers_object": "object: o\nglobal: o\ncluster: o\ngroup: o\nproject: o\n",
+		"answers": []interface{}{
+			map[string]interface{}{
+				"values": map[string]interface{}{"global": "g", "cluster": "g", "group": "g", "project": "g"},
+			},
+			map[string]interface{}{
+				"cluster_id": "c-abcde",
+				"values":     map[string]interface{}{"cluster": "c", "group": "c", "project": "c"},
+			},
+			map[string]interface{}{
+				"project_id": "c-abcde:p-one",
+				"values":     map[string]interface{}{"project": "p"},
+			},
+		},
+		"group_answers": []interface{}{
+			map[string]interface{}{
+				"group":  "prod",
+				"values": map[string]interface{}{"group": "gr", "project": "gr"},
+			},
+		},
+		"targets": []interface{}{
+			map[string]interface{}{"project_id": "c-abcde:p-one", "group": "prod"},
+			map[string]interface{}{"project_id": "c-fghij:p-two"},
+		},
+	})
+
+	sources, err := multiClusterAppAnswerSources(d.Get)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]multiClusterAppAnswerSource{
+		"object":  {Value: "o", Scope: "answers_object"},
+		"global":  {Value: "g", Scope: "global"},
+		"cluster": {Value: "c", Scope: "cluster"},
+		"group":   {Value: "gr", Scope: "group"},
+		"project": {Value: "p", Scope: "project"},
+	}, sources["c-abcde:p-one"])
+	assert.Equal(t, map[string]multiClusterAppAnswerSource{
+		"object":  {Value: "o", Scope: "answers_object"},
+		"global":  {Value: "g", Scope: "global"},
+		"cluster": {Value: "g", Scope: "global"},
+		"group":   {Value: "g", Scope: "global"},
+		"project": {Value: "g", Scope: "global"},
+	}, sources["c-fghij:p-two"])
+
+	answers, err := expandMultiClusterAppAnswers(d.Get)
+	assert.NoError(t, err)
+	for projectID, keys := range sources {
+		effective := effectiveAnswerValues(answers, projectID)
+		for key, source := range keys {
+			assert.Equal(t, effective[key], source.Value, "Reported %s answer %s should match the applied value", projectID, key)
+		}
+	}
+}
+
+func TestMultiClusterAppTargetScales(t *testing.T) {
+	targets := []interface{}{
+		map[string]interface{}{"project_id": "c-abcde:p-one", "scale": 3},
+		map[string]interface{}{"project_id": "c-fghij:p-two", "scale": 0},
+	}
+	annotations, err := expandMultiClusterAppAnnotations(map[string]interface{}{"owner": "team"}, targets)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"owner":                              "team",
+		multiClusterAppTargetScaleAnnotation: `{"c-abcde:p-one":3}`,
+	}, annotations)
+
+	_, err = expandMultiClusterAppAnnotations(map[string]interface{}{multiClusterAppTargetScaleAnnotation: "{}"}, targets)
+	assert.Error(t, err)
+
+	mca := &managementClient.MultiClusterApp{
+		Name:        "foo",
+		Annotations: annotations,
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-one", AppID: "p-one:mcapp-foo"},
+			{ProjectID: "c-fghij:p-two", AppID: "p-two:mcapp-foo"},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), map[string]interface{}{})
+	err = flattenMultiClusterApp(d, mca, testMultiClusterAppExternalID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, d.Get("targets.0.scale"))
+	assert.Equal(t, 0, d.Get("targets.1.scale"))
+	assert.Equal(t, map[string]interface{}{"owner": "team"}, d.Get("annotations"))
+}
+
+func TestExpandMultiClusterAppTargetAnswersYAML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mca-answers")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	stagingFile := filepath.Join(dir, "staging.yaml")
+	prodFile := filepath.Join(dir, "prod.yaml")
+	assert.NoError(t, ioutil.WriteFile(stagingFile, []byte("replicaCount: 1\ningress:\n  host: staging.example.com\n"), 0600))
+	assert.NoError(t, ioutil.WriteFile(prodFile, []byte("replicaCount: 3\ningress:\n  host: example.com\n"), 0600))
+
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"targets": []interface{}{
+			map[string]interface{}{"project_id": "c-staging:p-one", "answers_yaml": stagingFile},
+			map[string]interface{}{"project_id": "c-prod:p-one", "answers_yaml": prodFile},
+		},
+		"answers": []interface{}{
+			map[string]interface{}{
+				"values": map[string]interface{}{"replicaCount": "2", "image.tag": "v1"},
+			},
+			map[string]interface{}{
+				"project_id": "c-prod:p-one",
+				"values":     map[string]interface{}{"image.tag": "v0"},
+			},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+
+	answers, err := expandMultiClusterAppAnswers(d.Get)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"replicaCount": "1", "ingress.host": "staging.example.com", "image.tag": "v1"}, effectiveAnswerValues(answers, "c-staging:p-one"))
+	assert.Equal(t, map[string]string{"replicaCount": "3", "ingress.host": "example.com", "image.tag": "v0"}, effectiveAnswerValues(answers, "c-prod:p-one"), "Project answers should take precedence over answers_yaml")
+
+	// File values and answers_yaml are not read back, unless they changed
+	answers[1].Values["replicaCount"] = "4"
+	mca := &managementClient.MultiClusterApp{
+		Name:                 "foo",
+		Answers:              answers,
+		RevisionHistoryLimit: 10,
+		Targets: []managementClient.Target{
+			{ProjectID: "c-staging:p-one"},
+			{ProjectID: "c-prod:p-one"},
+		},
+	}
+	err = flattenMultiClusterApp(d, mca, testMultiClusterAppExternalID)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"cluster_id": "",
+			"project_id": "",
+			"values":     map[string]interface{}{"replicaCount": "2", "image.tag": "v1"},
+		},
+		map[string]interface{}{
+			"cluster_id": "",
+			"project_id": "c-prod:p-one",
+			"values":     map[string]interface{}{"image.tag": "v0", "replicaCount": "4"},
+		},
+	}, d.Get("answers"))
+	assert.Equal(t, prodFile, d.Get("targets.1.answers_yaml"))
+
+	// Files must be valid YAML
+	assert.NoError(t, ioutil.WriteFile(prodFile, []byte("replicaCount: [3"), 0600))
+	_, err = expandMultiClusterAppAnswers(d.Get)
+	assert.Error(t, err)
+	_, errs := validateTargetAnswersYAML(prodFile, "answers_yaml")
+	assert.NotEmpty(t, errs)
+}
+
+func TestExpandMultiClusterAppTargetAnswers(t *testing.T) {
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"targets": []interface{}{
+			map[string]interface{}{"project_id": "c-staging:p-one", "answers": map[string]interface{}{"ingress.host": "staging.example.com"}},
+			map[string]interface{}{"project_id": "c-prod:p-one", "answers": map[string]interface{}{"replicaCount": "3"}},
+		},
+		"answers": []interface{}{
+			map[string]interface{}{
+				"values": map[string]interface{}{"replicaCount": "1", "image.tag": "v1"},
+			},
+			map[string]interface{}{
+				"project_id": "c-prod:p-one",
+				"values":     map[string]interface{}{"image.tag": "v0"},
+			},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+
+	answers, err := expandMultiClusterAppAnswers(d.Get)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"replicaCount": "1", "ingress.host": "staging.example.com", "image.tag": "v1"}, effectiveAnswerValues(answers, "c-staging:p-one"))
+	assert.Equal(t, map[string]string{"replicaCount": "3", "image.tag": "v0"}, effectiveAnswerValues(answers, "c-prod:p-one"))
+
+	// Target answers are not read back as project answers
+	mca := &managementClient.MultiClusterApp{
+		Name:                 "foo",
+		Answers:              answers,
+		RevisionHistoryLimit: 10,
+		Targets: []managementClient.Target{
+			{ProjectID: "c-staging:p-one"},
+			{ProjectID: "c-prod:p-one"},
+		},
+	}
+	err = flattenMultiClusterApp(d, mca, testMultiClusterAppExternalID)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"cluster_id": "",
+			"project_id": "",
+			"values":     map[string]interface{}{"replicaCount": "1", "image.tag": "v1"},
+		},
+		map[string]interface{}{
+			"cluster_id": "",
+			"project_id": "c-prod:p-one",
+			"values":     map[string]interface{}{"image.tag": "v0"},
+		},
+	}, d.Get("answers"))
+	assert.Equal(t, map[string]interface{}{"replicaCount": "3"}, d.Get("targets.1.answers"))
+
+	// Keys can't be set on both target answers and project answers
+	config["targets"] = []interface{}{
+		map[string]interface{}{"project_id": "c-prod:p-one", "answers": map[string]interface{}{"image.tag": "v2"}},
+	}
+	d = schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	_, err = expandMultiClusterAppAnswers(d.Get)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "target c-prod:p-one answers [image.tag] are also set on its project answers")
+	}
+}
+
+func TestMultiClusterAppGitOpsOwner(t *testing.T) {
+	cases := []struct {
+		Labels      map[string]string
+		Annotations map[string]string
+		Owner       string
+	}{
+		{nil, nil, ""},
+		{map[string]string{"app": "demo"}, map[string]string{"field.cattle.io/creatorId": "u-abcde"}, ""},
+		{map[string]string{"fleet.cattle.io/bundle-name": "mca"}, nil, "fleet"},
+		{nil, map[string]string{"objectset.rio.cattle.io/id": "fleet-mca"}, "fleet"},
+		{nil, map[string]string{"objectset.rio.cattle.io/id": "cluster-agent"}, ""},
+		{nil, map[string]string{"argocd.argoproj.io/tracking-id": "demo:management.cattle.io/MultiClusterApp:foo"}, "argocd"},
+		{map[string]string{"kustomize.toolkit.fluxcd.io/name": "demo"}, nil, "flux"},
+		{map[string]string{"app.kubernetes.io/managed-by": "Helm"}, nil, ""},
+		{map[string]string{"app.kubernetes.io/managed-by": "ArgoCD"}, nil, "argocd"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Owner, multiClusterAppGitOpsOwner(tc.Labels, tc.Annotations), "Unexpected owner of labels %v, annotations %v", tc.Labels, tc.Annotations)
+	}
+}
+
+func TestExpandMultiClusterAppRollingUpdate(t *testing.T) {
+	config := map[string]interface{}{
+		"catalog_name":     "test",
+		"name":             "foo",
+		"roles":            []interface{}{"role1"},
+		"targets":          []interface{}{map[string]interface{}{"project_id": "c-abcde:p-one"}},
+		"template_name":    "test-demo",
+		"template_version": "1.23.0",
+		"upgrade_strategy": []interface{}{
+			map[string]interface{}{
+				"rolling_update": []interface{}{
+					map[string]interface{}{"batch_size": 5},
+				},
+			},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, multiClusterAppFields(), config)
+	obj, err := expandMultiClusterApp(d)
+	assert.NoError(t, err)
+	if assert.NotNil(t, obj.UpgradeStrategy) && assert.NotNil(t, obj.UpgradeStrategy.RollingUpdate) {
+		assert.Equal(t, int64(5), obj.UpgradeStrategy.RollingUpdate.BatchSize)
+		assert.Equal(t, int64(0), obj.UpgradeStrategy.RollingUpdate.Interval, "Not set interval should use Rancher default")
+	}
+}
+
+func TestMergeMultiClusterAppAnswers(t *testing.T) {
+	live := []managementClient.Answer{
+		{Values: map[string]string{"replicaCount": "1", "image.tag": "v1"}},
+		{ProjectID: "c-abcde:p-one", Values: map[string]string{"replicaCount": "3", "extra": "yes"}},
+		{ClusterID: "c-fghij", Values: map[string]string{"region": "eu"}},
+	}
+	answers := []managementClient.Answer{
+		{Values: map[string]string{"image.tag": "v2"}},
+		{ProjectID: "c-abcde:p-one", Values: map[string]string{"replicaCount": "5"}},
+		{ProjectID: "c-abcde:p-two", Values: map[string]string{"replicaCount": "2"}},
+	}
+
+	assert.Equal(t, []managementClient.Answer{
+		{Values: map[string]string{"replicaCount": "1", "image.tag": "v2"}},
+		{ProjectID: "c-abcde:p-one", Values: map[string]string{"replicaCount": "5", "extra": "yes"}},
+		{ProjectID: "c-abcde:p-two", Values: map[string]string{"replicaCount": "2"}},
+		{ClusterID: "c-fghij", Values: map[string]string{"region": "eu"}},
+	}, mergeMultiClusterAppAnswers(live, answers))
+}
+
+func TestKeepMergedAnswers(t *testing.T) {
+	old := []interface{}{
+		map[string]interface{}{"cluster_id": "", "project_id": "", "values": map[string]interface{}{"image.tag": "v2"}},
+		map[string]interface{}{"cluster_id": "", "project_id": "c-abcde:p-one", "values": map[string]interface{}{"replicaCount": "5"}},
+	}
+	flattened := []interface{}{
+		map[string]interface{}{"values": map[string]interface{}{"replicaCount": "1", "image.tag": "v2"}},
+		map[string]interface{}{"project_id": "c-abcde:p-one", "values": map[string]interface{}{"replicaCount": "5", "extra": "yes"}},
+		map[string]interface{}{"cluster_id": "c-fghij", "values": map[string]interface{}{"region": "eu"}},
+	}
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"values": map[string]interface{}{"image.tag": "v2"}},
+		map[string]interface{}{"project_id": "c-abcde:p-one", "values": map[string]interface{}{"replicaCount": "5"}},
+	}, keepMergedAnswers(old, flattened))
+	assert.Equal(t, flattened, keepMergedAnswers(nil, flattened), "Imported answers should be kept")
+}
+
+func TestPinSkipUpdateTargetAnswers(t *testing.T) {
+	mca := &managementClient.MultiClusterApp{
+		Answers: []managementClient.Answer{
+			{Values: map[string]string{"image.tag": "1.0", "replicas": "1"}},
+			{ClusterID: "c-abcde", Values: map[string]string{"replicas": "2"}},
+			{ProjectID: "c-abcde:p-pinned", Values: map[string]string{"debug": "true"}},
+		},
+		Targets: []managementClient.Target{
+			{ProjectID: "c-abcde:p-one"},
+			{ProjectID: "c-abcde:p-two"},
+			{ProjectID: "c-abcde:p-pinned"},
+		},
+	}
+	// Upgrading all targets image tag but the pinned one
+	answers := []managementClient.Answer{
+		{Values: map[string]string{"image.tag": "2.0", "replicas": "1"}},
+		{ClusterID: "c-abcde", Values: map[string]string{"replicas": "2"}},
+		{ProjectID: "c-abcde:p-pinned", Values: map[string]string{"debug": "false"}},
+	}
+
+	pinned := pinSkipUpdateTargetAnswers(answers, mca, map[string]bool{"c-abcde:p-pinned": true})
+	assert.Equal(t, []managementClient.Answer{
+		{Values: map[string]string{"image.tag": "2.0", "replicas": "1"}},
+		{ClusterID: "c-abcde", Values: map[string]string{"replicas": "2"}},
+		{ProjectID: "c-abcde:p-pinned", Values: map[string]string{"image.tag": "1.0", "replicas": "2", "debug": "true"}},
+	}, pinned)
+	for _, projectID := range []string{"c-abcde:p-one", "c-abcde:p-two"} {
+		assert.Equal(t, "2.0", effectiveAnswerValues(pinned, projectID)["image.tag"])
+	}
+	assert.Equal(t, "1.0", effectiveAnswerValues(pinned, "c-abcde:p-pinned")["image.tag"])
+
+	// Pinning is stable once applied
+	mca.Answers = pinned
+	_, removed := multiClusterAppAnswersToPatch(pinSkipUpdateTargetAnswers(pinned, mca, map[string]bool{"c-abcde:p-pinned": true}), mca)
+	assert.Empty(t, removed)
+
+	// Not deployed targets aren't pinned
+	assert.Equal(t, answers, pinSkipUpdateTargetAnswers(answers, mca, map[string]bool{"c-abcde:p-new": true}))
+}
+
+func TestKeepSkipUpdateTargetAnswers(t *testing.T) {
+	old := []interface{}{
+		map[string]interface{}{"values": map[string]interface{}{"image.tag": "2.0"}},
+		map[string]interface{}{"project_id": "c-abcde:p-pinned", "values": map[string]interface{}{"debug": "false"}},
+	}
+	flattened := []interface{}{
+		map[string]interface{}{"values": map[string]interface{}{"image.tag": "2.0"}},
+		map[string]interface{}{"project_id": "c-abcde:p-pinned", "values": map[string]interface{}{"image.tag": "1.0", "debug": "true"}},
+	}
+
+	assert.Equal(t, old, keepSkipUpdateTargetAnswers(old, flattened, map[string]bool{"c-abcde:p-pinned": true}))
+	assert.Equal(t, flattened, keepSkipUpdateTargetAnswers(old, flattened, nil))
+}
@@ -19,6 +19,8 @@ func flattenTargets(p []managementClient.Target) []interface{} {
 			obj["project_id"] = in.ProjectID
 		}
 
+		obj["enabled"] = true
+
 		if len(in.AppID) > 0 {
 			obj["app_id"] = in.AppID
 		}
@@ -44,27 +46,69 @@ func expandTargets(p []interface{}) []managementClient.Target {
 		return []managementClient.Target{}
 	}
 
-	obj := make([]managementClient.Target, len(p))
+	obj := make([]managementClient.Target, 0, len(p))
 
 	for i := range p {
 		in := p[i].(map[string]interface{})
+		if v, ok := in["enabled"].(bool); ok && !v {
+			continue
+		}
+
+		target := managementClient.Target{}
 
 		if v, ok := in["project_id"].(string); ok && len(v) > 0 {
-			obj[i].ProjectID = v
+			target.ProjectID = v
 		}
 
 		if v, ok := in["app_id"].(string); ok && len(v) > 0 {
-			obj[i].AppID = v
+			target.AppID = v
 		}
 
 		if v, ok := in["health_state"].(string); ok && len(v) > 0 {
-			obj[i].Healthstate = v
+			target.Healthstate = v
 		}
 
 		if v, ok := in["state"].(string); ok && len(v) > 0 {
-			obj[i].State = v
+			target.State = v
 		}
+
+		obj = append(obj, target)
 	}
 
 	return obj
 }
+
+// skipUpdateTargetProjectIDs returns the project IDs of the enabled targets with skip_update true
+func skipUpdateTargetProjectIDs(p []interface{}) map[string]bool {
+	out := map[string]bool{}
+	for _, t := range p {
+		in, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if v, ok := in["enabled"].(bool); ok && !v {
+			continue
+		}
+		if v, ok := in["skip_update"].(bool); ok && v {
+			out[in["project_id"].(string)] = true
+		}
+	}
+
+	return out
+}
+
+// disabledTargetProjectIDs returns the project IDs of the targets with enabled false
+func disabledTargetProjectIDs(p []interface{}) map[string]bool {
+	out := map[string]bool{}
+	for _, t := range p {
+		in, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if v, ok := in["enabled"].(bool); ok && !v {
+			out[in["project_id"].(string)] = true
+		}
+	}
+
+	return out
+}
@@ -24,6 +24,7 @@ func init() {
 	testTargetsInterface = []interface{}{
 		map[string]interface{}{
 			"project_id":   "project_id",
+			"enabled":      true,
 			"app_id":       "app_id",
 			"health_state": "health_state",
 			"state":        "state",
@@ -0,0 +1,204 @@
+package rancher2
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	traceServiceName      = "terraform-provider-rancher2"
+	traceSpanKindInternal = 1
+	traceSpanKindClient   = 3
+	traceStatusOK         = 1
+	traceStatusError      = 2
+	traceExportTimeout    = 2 * time.Second
+)
+
+// traceSpan is a finished tracing span. Kinds and status codes follow the OpenTelemetry protocol
+type traceSpan struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Kind         int
+	Start        time.Time
+	End          time.Time
+	Err          error
+}
+
+type traceSpanContextKey struct{}
+
+// spanExporter exports the finished spans of a trace
+type spanExporter interface {
+	ExportSpans(spans []traceSpan) error
+}
+
+// tracer records spans, exporting the spans of a trace on background once its root span ends
+type tracer struct {
+	exporter spanExporter
+	pending  map[string][]traceSpan
+	sync     sync.Mutex
+	exports  sync.WaitGroup
+}
+
+func newTracer(exporter spanExporter) *tracer {
+	return &tracer{
+		exporter: exporter,
+		pending:  map[string][]traceSpan{},
+	}
+}
+
+// start starts a span named name, child of the ctx span if any. Returns the context carrying the span and the function
+// ending it. The span isn't recorded if its ID can't be generated
+func (t *tracer) start(ctx context.Context, name string, kind int) (context.Context, func(error)) {
+	spanID, err := randomHex(8)
+	if err != nil {
+		log.Printf("[WARN] Skipping tracing span %s: %v", name, err)
+		return ctx, func(error) {}
+	}
+	span := &traceSpan{
+		SpanID: spanID,
+		Name:   name,
+		Kind:   kind,
+		Start:  time.Now(),
+	}
+	if parent, ok := ctx.Value(traceSpanContextKey{}).(*traceSpan); ok {
+		span.TraceID = parent.TraceID
+		span.ParentSpanID = parent.SpanID
+	} else if span.TraceID, err = randomHex(16); err != nil {
+		log.Printf("[WARN] Skipping tracing span %s: %v", name, err)
+		return ctx, func(error) {}
+	}
+
+	return context.WithValue(ctx, traceSpanContextKey{}, span), func(err error) {
+		t.end(span, err)
+	}
+}
+
+func (t *tracer) end(span *traceSpan, err error) {
+	span.End = time.Now()
+	span.Err = err
+
+	t.sync.Lock()
+	t.pending[span.TraceID] = append(t.pending[span.TraceID], *span)
+	var spans []traceSpan
+	if len(span.ParentSpanID) == 0 {
+		spans = t.pending[span.TraceID]
+		delete(t.pending, span.TraceID)
+	}
+	t.sync.Unlock()
+
+	if len(spans) == 0 {
+		return
+	}
+	// Exported on background, so a slow or unreachable endpoint doesn't delay the resource operation
+	t.exports.Add(1)
+	go func() {
+		defer t.exports.Done()
+		if exportErr := t.exporter.ExportSpans(spans); exportErr != nil {
+			log.Printf("[WARN] Exporting %d tracing spans: %v", len(spans), exportErr)
+		}
+	}()
+}
+
+// flush waits until the running exports finish
+func (t *tracer) flush() {
+	t.exports.Wait()
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", fmt.Errorf("generating random ID: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// otlpSpanExporter is a spanExporter sending spans, JSON encoded, to an OpenTelemetry protocol HTTP endpoint
+type otlpSpanExporter struct {
+	url    string
+	client *http.Client
+}
+
+func newOTLPSpanExporter(endpoint string) *otlpSpanExporter {
+	return &otlpSpanExporter{
+		url: strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		client: &http.Client{
+			Timeout: traceExportTimeout,
+		},
+	}
+}
+
+func (e *otlpSpanExporter) ExportSpans(spans []traceSpan) error {
+	body, err := json.Marshal(otlpTracesRequest(spans))
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("OTLP endpoint %s responded %s", e.url, resp.Status)
+	}
+
+	return nil
+}
+
+// otlpTracesRequest returns the OpenTelemetry protocol export request of spans, following its JSON mapping
+func otlpTracesRequest(spans []traceSpan) map[string]interface{} {
+	out := make([]interface{}, 0, len(spans))
+	for _, span := range spans {
+		status := map[string]interface{}{
+			"code": traceStatusOK,
+		}
+		if span.Err != nil {
+			status["code"] = traceStatusError
+			status["message"] = span.Err.Error()
+		}
+		obj := map[string]interface{}{
+			"traceId":           span.TraceID,
+			"spanId":            span.SpanID,
+			"name":              span.Name,
+			"kind":              span.Kind,
+			"startTimeUnixNano": fmt.Sprintf("%d", span.Start.UnixNano()),
+			"endTimeUnixNano":   fmt.Sprintf("%d", span.End.UnixNano()),
+			"status":            status,
+		}
+		if len(span.ParentSpanID) > 0 {
+			obj["parentSpanId"] = span.ParentSpanID
+		}
+		out = append(out, obj)
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []interface{}{
+						map[string]interface{}{
+							"key":   "service.name",
+							"value": map[string]interface{}{"stringValue": traceServiceName},
+						},
+					},
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": traceServiceName},
+						"spans": out,
+					},
+				},
+			},
+		},
+	}
+}
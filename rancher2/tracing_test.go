@@ -0,0 +1,57 @@
+package rancher2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBlockingSpanExporter struct {
+	release  chan struct{}
+	exported chan []traceSpan
+}
+
+func (e *testBlockingSpanExporter) ExportSpans(spans []traceSpan) error {
+	<-e.release
+	e.exported <- spans
+	return nil
+}
+
+func TestTracerExportsOnBackground(t *testing.T) {
+	exporter := &testBlockingSpanExporter{
+		release:  make(chan struct{}),
+		exported: make(chan []traceSpan, 1),
+	}
+	tracer := newTracer(exporter)
+
+	ctx, endRoot := tracer.start(context.Background(), "root", traceSpanKindInternal)
+	_, endChild := tracer.start(ctx, "child", traceSpanKindClient)
+	endChild(nil)
+
+	ended := make(chan struct{})
+	go func() {
+		endRoot(nil)
+		close(ended)
+	}()
+	select {
+	case <-ended:
+	case <-time.After(time.Second):
+		t.Fatal("Ending the root span should not wait for the export")
+	}
+
+	close(exporter.release)
+	tracer.flush()
+	spans := <-exporter.exported
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	assert.Empty(t, tracer.pending)
+}
+
+func TestRandomHex(t *testing.T) {
+	id, err := randomHex(8)
+	assert.NoError(t, err)
+	assert.Len(t, id, 16)
+}
@@ -2,6 +2,10 @@ package rancher2
 
 import (
 	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
@@ -15,6 +19,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	ghodssyaml "github.com/ghodss/yaml"
@@ -22,6 +27,7 @@ import (
 	"github.com/rancher/norman/clientbase"
 	"github.com/rancher/norman/types"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
 	"gopkg.in/yaml.v2"
 	kubeconfig "k8s.io/client-go/tools/clientcmd/api/v1"
 )
@@ -103,6 +109,33 @@ func getTokenFromKubeConfig(config string) (string, error) {
 	return kubeconfig.AuthInfos[0].AuthInfo.Token, nil
 }
 
+// getServerFromKubeConfig returns the server URL and CA certificate of the cluster of the kubeconfig current context,
+// or of its first cluster if current context isn't set
+func getServerFromKubeConfig(config string) (string, string, error) {
+	kubeconfig, err := getObjFromKubeConfig(config)
+	if err != nil {
+		return "", "", err
+	}
+	if kubeconfig == nil || len(kubeconfig.Clusters) == 0 {
+		return "", "", fmt.Errorf("Getting server from kube_config: no cluster found")
+	}
+
+	clusterName := kubeconfig.Clusters[0].Name
+	for _, c := range kubeconfig.Contexts {
+		if c.Name == kubeconfig.CurrentContext {
+			clusterName = c.Context.Cluster
+			break
+		}
+	}
+	for _, c := range kubeconfig.Clusters {
+		if c.Name == clusterName {
+			return c.Cluster.Server, string(c.Cluster.CertificateAuthorityData), nil
+		}
+	}
+
+	return "", "", fmt.Errorf("Getting server from kube_config: cluster %s not found", clusterName)
+}
+
 func TrimSpace(val interface{}) string {
 	return strings.TrimSpace(val.(string))
 }
@@ -296,6 +329,117 @@ func NormalizeURL(input string) (string, error) {
 	return u.String(), nil
 }
 
+// answersEncrypter encrypts the sensitive answer values stored on state. Encrypted values must start with answersEncryptedPrefix
+type answersEncrypter interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
+
+// passphraseAnswersEncrypter is an AES-GCM answersEncrypter keyed by the scrypt derived key of a passphrase and a
+// random salt per value, stored on the value. Values encrypted with answersEncryptedPrefixV1, keyed by the SHA-256 sum
+// of the passphrase, are still decrypted
+type passphraseAnswersEncrypter struct {
+	passphrase []byte
+	keyV1      []byte
+	keysMu     sync.Mutex
+	keys       map[string][]byte
+}
+
+func newPassphraseAnswersEncrypter(passphrase string) *passphraseAnswersEncrypter {
+	keyV1 := sha256.Sum256([]byte(passphrase))
+	return &passphraseAnswersEncrypter{
+		passphrase: []byte(passphrase),
+		keyV1:      keyV1[:],
+		keys:       map[string][]byte{},
+	}
+}
+
+// key returns the scrypt derived key for salt. Keys are cached, deriving them is expensive on purpose
+func (e *passphraseAnswersEncrypter) key(salt []byte) ([]byte, error) {
+	e.keysMu.Lock()
+	defer e.keysMu.Unlock()
+
+	if key, ok := e.keys[string(salt)]; ok {
+		return key, nil
+	}
+	key, err := scrypt.Key(e.passphrase, salt, answersEncryptionScryptN, answersEncryptionScryptR, answersEncryptionScryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	e.keys[string(salt)] = key
+
+	return key, nil
+}
+
+func answersEncryptionGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (e *passphraseAnswersEncrypter) Encrypt(plaintext string) (string, error) {
+	salt := make([]byte, answersEncryptionSaltSize)
+	if _, err := cryptorand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := e.key(salt)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := answersEncryptionGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := cryptorand.Read(nonce); err != nil {
+		return "", err
+	}
+	out := append(salt, gcm.Seal(nonce, nonce, []byte(plaintext), nil)...)
+	return answersEncryptedPrefix + base64.StdEncoding.EncodeToString(out), nil
+}
+
+func (e *passphraseAnswersEncrypter) Decrypt(ciphertext string) (string, error) {
+	var encoded string
+	var key []byte
+	switch {
+	case strings.HasPrefix(ciphertext, answersEncryptedPrefix):
+		encoded = strings.TrimPrefix(ciphertext, answersEncryptedPrefix)
+	case strings.HasPrefix(ciphertext, answersEncryptedPrefixV1):
+		encoded = strings.TrimPrefix(ciphertext, answersEncryptedPrefixV1)
+		key = e.keyV1
+	default:
+		return "", fmt.Errorf("Decrypting answer: value is not encrypted")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("Decrypting answer: %v", err)
+	}
+	if key == nil {
+		if len(data) < answersEncryptionSaltSize {
+			return "", fmt.Errorf("Decrypting answer: value is too short")
+		}
+		key, err = e.key(data[:answersEncryptionSaltSize])
+		if err != nil {
+			return "", err
+		}
+		data = data[answersEncryptionSaltSize:]
+	}
+	gcm, err := answersEncryptionGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("Decrypting answer: value is too short")
+	}
+	out, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("Decrypting answer: %v", err)
+	}
+	return string(out), nil
+}
+
 func IsUnknownSchemaType(err error) bool {
 	return strings.Contains(err.Error(), "Unknown schema type")
 }
@@ -358,6 +502,26 @@ func IsServerError(err error) bool {
 	return apiError.StatusCode == http.StatusInternalServerError
 }
 
+// IsTooManyRequests checks if the given APIError is a Too Many Requests HTTP statuscode
+func IsTooManyRequests(err error) bool {
+	apiError, ok := err.(*clientbase.APIError)
+	if !ok {
+		return false
+	}
+
+	return apiError.StatusCode == http.StatusTooManyRequests
+}
+
+// isRetryableAPIError checks if the given APIError is a throttling or a server side error, worth retrying
+func isRetryableAPIError(err error) bool {
+	apiError, ok := err.(*clientbase.APIError)
+	if !ok {
+		return false
+	}
+
+	return apiError.StatusCode >= http.StatusInternalServerError || apiError.StatusCode == http.StatusTooManyRequests
+}
+
 // IsBadGatewayError checks if the given APIError is a Bad Gateway Server Error HTTP statuscode
 func IsBadGatewayError(err error) bool {
 	apiError, ok := err.(*clientbase.APIError)
@@ -492,6 +656,15 @@ func toArrayString(in []interface{}) []string {
 	return out
 }
 
+func containsString(in []string, s string) bool {
+	for _, v := range in {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func toArrayStringSorted(in []interface{}) []string {
 	if in == nil {
 		return nil
@@ -714,6 +887,37 @@ func getLatestVersion(list map[string]string) (string, error) {
 	return sorted[len(sorted)-1].Original(), nil
 }
 
+// isMajorVersionBump returns true if to major version differs from the from major version. False if any of them isn't
+// a valid version
+func isMajorVersionBump(from, to string) bool {
+	fromVersion, err := gover.NewVersion(from)
+	if err != nil {
+		return false
+	}
+	toVersion, err := gover.NewVersion(to)
+	if err != nil {
+		return false
+	}
+
+	return fromVersion.Segments()[0] != toVersion.Segments()[0]
+}
+
+func validatePositiveDuration(val interface{}, key string) (warns []string, errs []error) {
+	v, ok := val.(string)
+	if !ok || len(v) == 0 {
+		return
+	}
+	duration, err := time.ParseDuration(v)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%q must be in golang duration format, error: %v", key, err))
+		return
+	}
+	if duration <= 0 {
+		errs = append(errs, fmt.Errorf("%q must be a positive duration", key))
+	}
+	return
+}
+
 func structToMap(item interface{}) map[string]interface{} {
 	res := map[string]interface{}{}
 	if item == nil {